@@ -0,0 +1,200 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"math"
+	"math/rand"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// An Effect is an animated text effect.
+type Effect int
+
+const (
+	// EffectTypewriter reveals the text glyph by glyph.
+	EffectTypewriter Effect = iota
+	// EffectScroll scrolls the text from the right edge of the frame to the
+	// left until it has left the frame, like a marquee.
+	EffectScroll
+	// EffectWave moves the glyphs up and down in a sine wave.
+	EffectWave
+	// EffectShake jitters the glyphs randomly in each frame.
+	EffectShake
+	// EffectScatter moves the glyphs from random positions around the text
+	// to their places.
+	EffectScatter
+)
+
+// AnimationOptions configure the animation created by AnimateText.
+// A nil *AnimationOptions is valid and selects the defaults.
+type AnimationOptions struct {
+	// Style is the style of the text. Its background and padding apply to
+	// each frame, and its Visible range and GlyphFunc to the glyphs of each
+	// frame after the effect has moved them.
+	Style *Style
+	// Effect is the animated effect.
+	Effect Effect
+	// Frames is the number of frames. If it is 0, EffectTypewriter creates
+	// one frame per glyph plus an empty first frame, and the other effects
+	// create 30 frames.
+	Frames int
+	// Delay is the delay between frames in 100ths of a second.
+	// If it is 0 a delay of 5 is used.
+	Delay int
+	// Width is the width of the frames in pixels for EffectScroll. If it is
+	// 0 the width of the text is used. The other effects always use the
+	// size of the text.
+	Width int
+	// Amplitude is the amplitude of EffectWave and EffectShake, or the
+	// maximum distance of the start positions of EffectScatter, in pixels.
+	// If it is 0 a quarter of the line height is used.
+	Amplitude int
+	// Seed seeds the pseudo-random offsets of EffectShake and
+	// EffectScatter. Renders with the same seed are identical, and each
+	// frame only depends on the seed and its frame index.
+	Seed int64
+}
+
+// AnimateText renders a sequence of frames of the text with an animated
+// effect and assembles them into an animated GIF with a palette shared by
+// all frames. The result can be encoded with gif.EncodeAll.
+func (f *BitmapFont) AnimateText(text string, opts *AnimationOptions) *gif.GIF {
+	if opts == nil {
+		opts = &AnimationOptions{}
+	}
+	style := opts.Style
+	l := f.layout(text, style, &f.stats)
+	glyphs := l.glyphCount()
+	frames := opts.Frames
+	if frames <= 0 {
+		frames = 30
+		if opts.Effect == EffectTypewriter {
+			frames = glyphs + 1
+		}
+	}
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = 5
+	}
+	amplitude := opts.Amplitude
+	if amplitude <= 0 {
+		amplitude = int(math.Round(float64(l.font.Descriptor.Common.LineHeight) * l.scale / 4))
+	}
+
+	pad := style.padding()
+	var bg color.Color
+	if style != nil {
+		bg = style.Background
+	}
+	bounds := l.bounds()
+	textOrigin := image.Pt(pad.Left, pad.Up).Sub(bounds.Min)
+	size := image.Pt(pad.Left+bounds.Dx()+pad.Right, pad.Up+bounds.Dy()+pad.Down)
+	switch opts.Effect {
+	case EffectScroll:
+		if opts.Width > 0 {
+			size.X = opts.Width
+		}
+	case EffectWave:
+		size.Y += 2 * amplitude
+		textOrigin.Y += amplitude
+	case EffectShake, EffectScatter:
+		size = size.Add(image.Pt(2*amplitude, 2*amplitude))
+		textOrigin = textOrigin.Add(image.Pt(amplitude, amplitude))
+	}
+	scatter := make([]fixed.Point26_6, glyphs)
+	if opts.Effect == EffectScatter {
+		rnd := rand.New(rand.NewSource(opts.Seed))
+		for n := range scatter {
+			scatter[n] = randomOffset(rnd, amplitude)
+		}
+	}
+
+	rgbaFrames := make([]*image.RGBA, frames)
+	for i := range rgbaFrames {
+		t := float64(i) / float64(max(1, frames-1))
+		fl := l.clone()
+		origin := textOrigin
+		switch opts.Effect {
+		case EffectTypewriter:
+			fl.truncate(int(math.Round(t * float64(glyphs))))
+		case EffectScroll:
+			start := size.X - pad.Left
+			end := -bounds.Dx() - pad.Left
+			origin.X += start + int(math.Round(t*float64(end-start)))
+		case EffectWave:
+			phase := 2 * math.Pi * float64(i) / float64(frames)
+			fl.eachGlyph(func(n int, g *glyph) {
+				dy := float64(amplitude) * math.Sin(phase+float64(n)*0.5)
+				g.dot.Y += fixed.Int26_6(math.Round(dy * 64))
+			})
+		case EffectShake:
+			rnd := rand.New(rand.NewSource(opts.Seed + int64(i)))
+			fl.eachGlyph(func(_ int, g *glyph) {
+				g.dot = g.dot.Add(randomOffset(rnd, amplitude))
+			})
+		case EffectScatter:
+			remaining := fixed.Int26_6(math.Round((1 - t) * 64))
+			fl.eachGlyph(func(n int, g *glyph) {
+				g.dot = g.dot.Add(scatter[n].Mul(remaining))
+			})
+		}
+		frame := image.NewRGBA(image.Rectangle{Max: size})
+		if bg != nil {
+			draw.Draw(frame, frame.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+		}
+		fl = fl.drawnGlyphs(style)
+		fl.draw(newImageDrawer(frame, fl, style), origin, &f.stats)
+		rgbaFrames[i] = frame
+	}
+
+	p := sharedPalette(rgbaFrames)
+	anim := &gif.GIF{}
+	for _, frame := range rgbaFrames {
+		paletted := image.NewPaletted(frame.Bounds(), p)
+		draw.Draw(paletted, frame.Bounds(), frame, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return anim
+}
+
+// randomOffset returns a pseudo-random offset whose coordinates are within
+// the given amplitude in pixels.
+func randomOffset(rnd *rand.Rand, amplitude int) fixed.Point26_6 {
+	a := float64(amplitude)
+	return fixed.Point26_6{
+		X: fixed.Int26_6(math.Round((rnd.Float64()*2 - 1) * a * 64)),
+		Y: fixed.Int26_6(math.Round((rnd.Float64()*2 - 1) * a * 64)),
+	}
+}
+
+// sharedPalette returns a palette with the colors of the given images,
+// starting with a transparent color. If the images have more colors than a
+// GIF palette can hold, the web-safe palette is used instead.
+func sharedPalette(images []*image.RGBA) color.Palette {
+	p := color.Palette{color.Transparent}
+	seen := map[color.RGBA]bool{{}: true}
+	for _, img := range images {
+		for i := 0; i < len(img.Pix); i += 4 {
+			c := color.RGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: img.Pix[i+3]}
+			if seen[c] {
+				continue
+			}
+			if len(p) == 256 {
+				return append(color.Palette{color.Transparent}, palette.WebSafe...)
+			}
+			seen[c] = true
+			p = append(p, c)
+		}
+	}
+	return p
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"reflect"
+	"testing"
+)
+
+// opaquePixels returns the number of pixels of the image that are not
+// fully transparent.
+func opaquePixels(img image.Image) int {
+	n := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a > 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestAnimateTypewriter(t *testing.T) {
+	f := opaqueFont("AB")
+	anim := f.AnimateText("ABA", nil)
+	if len(anim.Image) != 4 {
+		t.Fatalf("got %d frames, want one per glyph plus an empty one", len(anim.Image))
+	}
+	for i, frame := range anim.Image {
+		if got, want := opaquePixels(frame), i*4*6; got != want {
+			t.Errorf("frame %d: got %d opaque pixels, want %d", i, got, want)
+		}
+		if anim.Delay[i] != 5 {
+			t.Errorf("frame %d: got delay %d, want the default 5", i, anim.Delay[i])
+		}
+	}
+	if err := gif.EncodeAll(&bytes.Buffer{}, anim); err != nil {
+		t.Errorf("encoding the animation: %v", err)
+	}
+}
+
+func TestAnimateScroll(t *testing.T) {
+	f := opaqueFont("A")
+	anim := f.AnimateText("A", &AnimationOptions{Effect: EffectScroll, Frames: 3, Width: 20})
+	if len(anim.Image) != 3 {
+		t.Fatalf("got %d frames, want 3", len(anim.Image))
+	}
+	if got := anim.Image[0].Bounds().Dx(); got != 20 {
+		t.Errorf("got frame width %d, want 20", got)
+	}
+	// The text enters from the right and has left the frame at the end.
+	if n := opaquePixels(anim.Image[0]); n != 0 {
+		t.Errorf("got %d opaque pixels in the first frame, want 0", n)
+	}
+	if n := opaquePixels(anim.Image[1]); n == 0 {
+		t.Error("got no opaque pixels in the middle frame")
+	}
+	if n := opaquePixels(anim.Image[2]); n != 0 {
+		t.Errorf("got %d opaque pixels in the last frame, want 0", n)
+	}
+}
+
+func TestAnimateSeededEffects(t *testing.T) {
+	f := opaqueFont("AB")
+	for _, effect := range []Effect{EffectShake, EffectScatter} {
+		render := func(seed int64) *gif.GIF {
+			return f.AnimateText("ABAB", &AnimationOptions{Effect: effect, Frames: 4, Amplitude: 3, Seed: seed})
+		}
+		a, b, c := render(1), render(1), render(2)
+		if !reflect.DeepEqual(a.Image, b.Image) {
+			t.Errorf("effect %d: renders with the same seed differ", effect)
+		}
+		if reflect.DeepEqual(a.Image, c.Image) {
+			t.Errorf("effect %d: renders with different seeds are identical", effect)
+		}
+	}
+}
+
+func TestAnimateScatterEndsInPlace(t *testing.T) {
+	f := opaqueFont("AB")
+	last := func(seed int64) *image.Paletted {
+		anim := f.AnimateText("AB", &AnimationOptions{Effect: EffectScatter, Frames: 3, Amplitude: 3, Seed: seed})
+		return anim.Image[len(anim.Image)-1]
+	}
+	// The glyphs have left their random start positions in the last frame,
+	// so it does not depend on the seed.
+	if !reflect.DeepEqual(last(7), last(8)) {
+		t.Error("the last scatter frame depends on the seed")
+	}
+}
+
+func TestAnimateStyle(t *testing.T) {
+	f := opaqueFont("AB")
+	style := &Style{DeviceScale: 2, Padding: Padding{Up: 1, Right: 1, Down: 1, Left: 1}}
+	anim := f.AnimateText("AB", &AnimationOptions{Style: style})
+	// The padding is in logical pixels, like for RenderToImage.
+	if got, want := anim.Image[0].Bounds(), f.RenderToImage("AB", style).Bounds(); got != want {
+		t.Errorf("got frame bounds %v, want %v", got, want)
+	}
+
+	// The visible range and the glyph function apply to each frame.
+	style = &Style{
+		Visible: &GlyphRange{Start: 0, End: 2},
+		GlyphFunc: func(g GlyphInfo, t *GlyphTransform) {
+			t.Skip = g.Index == 1
+		},
+	}
+	anim = f.AnimateText("ABA", &AnimationOptions{Style: style})
+	want := []int{0, 24, 24, 24}
+	for i, frame := range anim.Image {
+		if got := opaquePixels(frame); got != want[i] {
+			t.Errorf("frame %d: got %d opaque pixels, want %d", i, got, want[i])
+		}
+	}
+}
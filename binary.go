@@ -0,0 +1,197 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Block types of the binary format.
+const (
+	blockInfo    = 1
+	blockCommon  = 2
+	blockPages   = 3
+	blockChars   = 4
+	blockKerning = 5
+)
+
+// charsets maps the charset names of the text format to the Windows charset
+// IDs of the binary format.
+var charsets = map[string]uint8{
+	"ANSI": 0, "DEFAULT": 1, "SYMBOL": 2, "MAC": 77, "SHIFTJIS": 128,
+	"HANGUL": 129, "JOHAB": 130, "GB2312": 134, "CHINESEBIG5": 136,
+	"GREEK": 161, "TURKISH": 162, "VIETNAMESE": 163, "HEBREW": 177,
+	"ARABIC": 178, "BALTIC": 186, "RUSSIAN": 204, "THAI": 222,
+	"EASTEUROPE": 238, "OEM": 255,
+}
+
+// WriteBinary writes the descriptor in BMFont's binary format (version 3)
+// to w. The characters and kerning pairs are written sorted by their IDs.
+// The binary format has no room for extensions like class-based kerning and
+// localization metadata, so they are not written. Values that don't fit
+// into the fields of the binary format are reported as errors, as are page
+// file names of different lengths, which the format does not allow.
+func (d *Descriptor) WriteBinary(w io.Writer) error {
+	bw := &binaryWriter{}
+	bw.buf.WriteString("BMF\x03")
+
+	i := d.Info
+	charset, ok := charsets[strings.ToUpper(i.Charset)]
+	if !ok && i.Charset != "" {
+		return fmt.Errorf("bmfont: charset %q has no ID in the binary format", i.Charset)
+	}
+	bw.block(blockInfo, func() {
+		bw.int16("font size", i.Size)
+		bw.uint8("info bits", bits(i.Smooth, i.Unicode, i.Italic, i.Bold))
+		bw.uint8("charset", int(charset))
+		bw.uint16("stretchH", i.StretchH)
+		bw.uint8("aa", i.AA)
+		bw.uint8("padding", i.Padding.Up)
+		bw.uint8("padding", i.Padding.Right)
+		bw.uint8("padding", i.Padding.Down)
+		bw.uint8("padding", i.Padding.Left)
+		bw.uint8("spacing", i.Spacing.Horizontal)
+		bw.uint8("spacing", i.Spacing.Vertical)
+		bw.uint8("outline", i.Outline)
+		bw.string(i.Face)
+	})
+
+	c := d.Common
+	bw.block(blockCommon, func() {
+		bw.uint16("lineHeight", c.LineHeight)
+		bw.uint16("base", c.Base)
+		bw.uint16("scaleW", c.ScaleW)
+		bw.uint16("scaleH", c.ScaleH)
+		bw.uint16("pages", len(d.Pages))
+		bw.uint8("common bits", boolInt(c.Packed))
+		bw.uint8("alphaChnl", int(c.AlphaChannel))
+		bw.uint8("redChnl", int(c.RedChannel))
+		bw.uint8("greenChnl", int(c.GreenChannel))
+		bw.uint8("blueChnl", int(c.BlueChannel))
+	})
+
+	ids := sortedKeys(d.Pages)
+	for n, id := range ids {
+		if id != n {
+			return fmt.Errorf("bmfont: page IDs must be consecutive from 0 for the binary format, found page %d", id)
+		}
+		if first := d.Pages[ids[0]].File; len(d.Pages[id].File) != len(first) {
+			return fmt.Errorf("bmfont: page files %q and %q must have the same length for the binary format", first, d.Pages[id].File)
+		}
+	}
+	bw.block(blockPages, func() {
+		for _, id := range ids {
+			bw.string(d.Pages[id].File)
+		}
+	})
+
+	bw.block(blockChars, func() {
+		for _, r := range sortedKeys(d.Chars) {
+			ch := d.Chars[r]
+			bw.uint32("char id", int64(ch.ID))
+			bw.uint16("char x", ch.X)
+			bw.uint16("char y", ch.Y)
+			bw.uint16("char width", ch.Width)
+			bw.uint16("char height", ch.Height)
+			bw.int16("char xoffset", ch.XOffset)
+			bw.int16("char yoffset", ch.YOffset)
+			bw.int16("char xadvance", ch.XAdvance)
+			bw.uint8("char page", ch.Page)
+			bw.uint8("char chnl", int(ch.Channel))
+		}
+	})
+
+	if len(d.Kerning) > 0 {
+		pairs := d.kerningPairs()
+		bw.block(blockKerning, func() {
+			for _, pair := range pairs {
+				bw.uint32("kerning first", int64(pair.First))
+				bw.uint32("kerning second", int64(pair.Second))
+				bw.int16("kerning amount", d.Kerning[pair].Amount)
+			}
+		})
+	}
+	if bw.err != nil {
+		return bw.err
+	}
+	_, err := w.Write(bw.buf.Bytes())
+	return err
+}
+
+// bits packs the flags into a bit field, the first flag into the most
+// significant bit, as BMFont numbers the bits of the info block from the
+// most significant one. The only flag of the common block, packed, is its
+// bit 7, the least significant bit.
+func bits(flags ...bool) int {
+	var b int
+	for i, f := range flags {
+		if f {
+			b |= 0x80 >> i
+		}
+	}
+	return b
+}
+
+// A binaryWriter encodes the little-endian values of the binary format and
+// records the first value that is out of range.
+type binaryWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+// block writes a block of the given type with the content written by fn,
+// prefixed by its size.
+func (bw *binaryWriter) block(typ byte, fn func()) {
+	bw.buf.WriteByte(typ)
+	start := bw.buf.Len()
+	bw.buf.Write(make([]byte, 4))
+	fn()
+	binary.LittleEndian.PutUint32(bw.buf.Bytes()[start:], uint32(bw.buf.Len()-start-4))
+}
+
+func (bw *binaryWriter) check(name string, v, lo, hi int64) bool {
+	if v < lo || v > hi {
+		if bw.err == nil {
+			bw.err = fmt.Errorf("bmfont: %s %d is out of range for the binary format", name, v)
+		}
+		return false
+	}
+	return true
+}
+
+func (bw *binaryWriter) uint8(name string, v int) {
+	if bw.check(name, int64(v), 0, math.MaxUint8) {
+		bw.buf.WriteByte(byte(v))
+	}
+}
+
+func (bw *binaryWriter) int16(name string, v int) {
+	if bw.check(name, int64(v), math.MinInt16, math.MaxInt16) {
+		bw.buf.Write(binary.LittleEndian.AppendUint16(nil, uint16(v)))
+	}
+}
+
+func (bw *binaryWriter) uint16(name string, v int) {
+	if bw.check(name, int64(v), 0, math.MaxUint16) {
+		bw.buf.Write(binary.LittleEndian.AppendUint16(nil, uint16(v)))
+	}
+}
+
+func (bw *binaryWriter) uint32(name string, v int64) {
+	if bw.check(name, v, 0, math.MaxUint32) {
+		bw.buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(v)))
+	}
+}
+
+// string writes a null-terminated string.
+func (bw *binaryWriter) string(s string) {
+	bw.buf.WriteString(s)
+	bw.buf.WriteByte(0)
+}
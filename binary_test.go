@@ -0,0 +1,94 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteBinary(t *testing.T) {
+	d := &Descriptor{
+		Info: Info{
+			Face:     "A",
+			Size:     12,
+			Bold:     true,
+			Unicode:  true,
+			StretchH: 100,
+			Smooth:   true,
+			AA:       1,
+			Padding:  Padding{Up: 1, Right: 2, Down: 3, Left: 4},
+			Spacing:  Spacing{Horizontal: 1, Vertical: 1},
+		},
+		Common: Common{
+			LineHeight:   16,
+			Base:         13,
+			ScaleW:       256,
+			ScaleH:       128,
+			Packed:       true,
+			AlphaChannel: 1,
+		},
+		Pages: map[int]Page{0: {ID: 0, File: "a.png"}},
+		Chars: map[rune]Char{
+			'A': {ID: 'A', X: 1, Y: 2, Width: 3, Height: 4, XOffset: -1, YOffset: 2, XAdvance: 5, Channel: All},
+		},
+		Kerning: map[CharPair]Kerning{{First: 'A', Second: 'B'}: {Amount: -1}},
+	}
+	want := []byte{
+		'B', 'M', 'F', 3,
+		// info block
+		1, 16, 0, 0, 0,
+		12, 0, // font size
+		0xD0,   // smooth, unicode, bold
+		0,      // charset
+		100, 0, // stretchH
+		1,          // aa
+		1, 2, 3, 4, // padding
+		1, 1, // spacing
+		0,      // outline
+		'A', 0, // face
+		// common block
+		2, 15, 0, 0, 0,
+		16, 0, // lineHeight
+		13, 0, // base
+		0, 1, // scaleW
+		128, 0, // scaleH
+		1, 0, // pages
+		0x01,       // packed
+		1, 0, 0, 0, // channels
+		// pages block
+		3, 6, 0, 0, 0,
+		'a', '.', 'p', 'n', 'g', 0,
+		// chars block
+		4, 20, 0, 0, 0,
+		'A', 0, 0, 0, // id
+		1, 0, 2, 0, 3, 0, 4, 0, // x, y, width, height
+		0xFF, 0xFF, 2, 0, 5, 0, // xoffset, yoffset, xadvance
+		0,  // page
+		15, // chnl
+		// kerning block
+		5, 10, 0, 0, 0,
+		'A', 0, 0, 0, 'B', 0, 0, 0,
+		0xFF, 0xFF,
+	}
+	var buf bytes.Buffer
+	if err := d.WriteBinary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("got\n% x\nwant\n% x", got, want)
+	}
+}
+
+func TestWriteBinaryOutOfRange(t *testing.T) {
+	d := &Descriptor{
+		Pages: map[int]Page{0: {ID: 0, File: "a.png"}},
+		Chars: map[rune]Char{'A': {ID: 'A', X: 70000}},
+	}
+	var buf bytes.Buffer
+	if err := d.WriteBinary(&buf); err == nil {
+		t.Error("WriteBinary accepted a char x outside of the uint16 range")
+	}
+}
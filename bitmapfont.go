@@ -5,11 +5,23 @@
 package bmfont
 
 import (
+	"bytes"
+	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
 )
 
 // A BitmapFont is a bitmap font based on one or more sheet images for the
@@ -19,31 +31,185 @@ type BitmapFont struct {
 	// of the characters in the sheet images.
 	Descriptor *Descriptor
 	// PageSheets contains the loaded sheet images for the pages. The keys
-	// correspond to the keys of the pages map in the descriptor.
+	// correspond to the keys of the pages map in the descriptor. Pages
+	// that no character references are not loaded unless requested via
+	// Loader.IncludeUnreferencedPages. For fonts loaded with
+	// Loader.LazyPages it is empty, and the sheet images are accessed via
+	// the Page method, which is safe for concurrent use.
 	PageSheets map[int]image.Image
+	// Ligatures maps character sequences like "fi" or "->" to the IDs of
+	// ligature glyphs in the font that replace them when text is laid out,
+	// for fonts with ligature glyphs, which are usually stored at private
+	// use code points. The longest matching sequence wins. Sequences whose
+	// glyph is missing in the font are not replaced.
+	Ligatures map[string]rune
+	// Substitutions are contextual substitution rules that replace the
+	// glyphs of characters depending on the surrounding characters when
+	// text is laid out. The first matching rule applies. Characters that
+	// are part of a ligature are not substituted.
+	Substitutions []Substitution
+	// Variants are prescaled versions of the font, e.g. rendered at twice
+	// the size for high resolution displays, keyed by their size relative
+	// to this font. When text is laid out the variant closest to the
+	// effective scale of the style is selected and scaled by the remaining
+	// factor, if any.
+	Variants map[float64]*BitmapFont
+
+	// pages loads the page sheet images on demand, if the font was loaded
+	// with Loader.LazyPages.
+	pages *pageLoader
+	stats fontStats
 }
 
 // Load loads a bitmap font from a BMFont descriptor file (.fnt) in text format
 // including all the referenced page sheet images. The resulting bitmap font
 // is ready to be used to draw text on an image.
-func Load(path string) (f *BitmapFont, err error) {
+func Load(path string) (*BitmapFont, error) {
+	var l Loader
+	return l.Load(path)
+}
+
+// LoadFS loads a bitmap font like Load, but from the file system fsys, e.g.
+// an embed.FS or a zip archive. The page sheet images are loaded from the
+// directory of the descriptor file within fsys.
+func LoadFS(fsys fs.FS, name string) (*BitmapFont, error) {
+	var l Loader
+	return l.LoadFS(fsys, name)
+}
+
+// Read reads a bitmap font from a BMFont descriptor in text format including
+// all the referenced page sheet images.
+// The page sheet images are read from the readers provided by the given
+// SheetReaderFunc. These sheet readers are closed after use. If you want to
+// keep them open wrap them via io.NopCloser.
+// The resulting bitmap font is ready to be used to draw text on an image.
+func Read(r io.Reader, sheets SheetReaderFunc) (*BitmapFont, error) {
+	var l Loader
+	return l.Read(r, sheets)
+}
+
+// ReadAll reads a bitmap font from a BMFont descriptor and page sheet image
+// files held in memory, e.g. embedded with go:embed. The sheets map holds the
+// contents of the page sheet image files by the file names given by the
+// pages of the descriptor.
+func ReadAll(descriptor []byte, sheets map[string][]byte) (*BitmapFont, error) {
+	return Read(bytes.NewReader(descriptor), func(filename string) (io.ReadCloser, error) {
+		data, ok := sheets[filename]
+		if !ok {
+			return nil, fmt.Errorf("bmfont: missing sheet image %q", filename)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+}
+
+// A Loader loads bitmap fonts with additional checks. The zero value is
+// ready to use and loads fonts like the Load and Read functions.
+type Loader struct {
+	// ParseOptions configure how the font descriptor is parsed.
+	ParseOptions
+	// VerifyPageSizes rejects fonts with page sheet images whose size
+	// differs from the size declared by Common.ScaleW and Common.ScaleH,
+	// which is usually a sign of a mis-exported or mismatched atlas.
+	VerifyPageSizes bool
+	// VerifyPixelFormat rejects fonts with page sheet images that lack the
+	// channels declared to hold glyph data, e.g. a grayscale image for a
+	// packed font, or with channels declared as constant zero or one that
+	// have other values. Such mismatches usually result in invisible text.
+	VerifyPixelFormat bool
+	// Placeholders substitutes a magenta checkerboard placeholder image
+	// for each page sheet image that is missing or can't be decoded or
+	// verified, instead of failing. The font is returned together with an
+	// error listing the failed pages, so that tools can still preview
+	// the glyphs of the other pages.
+	Placeholders bool
+	// IncludeUnreferencedPages loads the page sheet images of pages that no
+	// character references, too. By default they are skipped to save
+	// decoding time and memory, and are missing in the PageSheets map.
+	IncludeUnreferencedPages bool
+	// LazyPages defers reading and decoding each page sheet image until a
+	// glyph on the page is first drawn, or until the page is requested via
+	// BitmapFont.Page. This reduces the loading time and memory use of
+	// fonts with many pages, e.g. large CJK sets. The font retains the
+	// SheetReaderFunc for this purpose, so its readers must remain usable.
+	// Its PageSheets map remains empty.
+	// The other options apply to the pages when they are loaded, except
+	// for Progress, which only reports the reading of the descriptor.
+	LazyPages bool
+	// Concurrency is the maximum number of page sheet images that are read
+	// and decoded concurrently, for fonts with multiple pages. If it is 0,
+	// runtime.GOMAXPROCS(0) is used. A value of 1 loads the pages one
+	// after another, e.g. for a SheetReaderFunc that is not safe for
+	// concurrent use.
+	Concurrency int
+	// Progress, if not nil, is called with the progress of loading, for
+	// showing it on loading screens: repeatedly while data is read, after
+	// the descriptor has been parsed, and after each page is decoded. It
+	// may be called from different goroutines, but not concurrently.
+	Progress func(LoadProgress)
+
+	// imageDecoders are the decoders registered via WithImageDecoder by
+	// file name extension in lower case.
+	imageDecoders map[string]ImageDecoder
+}
+
+// WithImageDecoder registers a decoder for the page sheet image files with
+// the given file name extension, e.g. ".png" or ".ktx2", which is matched
+// case-insensitively. The registered decoders take precedence over the
+// formats registered with the image package and over the TGA and DDS
+// decoders of this package, so that programs can choose the decoder for
+// each extension, including decoders for formats the image package doesn't
+// know. Files with other extensions are still decoded with the formats
+// registered with the image package. These always include PNG, JPEG and
+// GIF, because this package imports their encoders for writing images. It
+// returns the loader to allow chaining. Decoders must not be registered
+// while the loader is loading a font.
+func (l *Loader) WithImageDecoder(ext string, decode ImageDecoder) *Loader {
+	if l.imageDecoders == nil {
+		l.imageDecoders = make(map[string]ImageDecoder)
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	l.imageDecoders[strings.ToLower(ext)] = decode
+	return l
+}
+
+// Load loads a bitmap font from a BMFont descriptor file (.fnt) in text
+// format including all the referenced page sheet images, like the Load
+// function.
+func (l *Loader) Load(path string) (f *BitmapFont, err error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer closeChecked(file, &err)
 	dir, _ := filepath.Split(path)
-	return Read(file, fileSheets(dir))
+	return l.read(filepath.Base(path), file, fileSheets(dir))
+}
+
+// LoadFS loads a bitmap font from the file system fsys like the LoadFS
+// function.
+func (l *Loader) LoadFS(fsys fs.FS, name string) (f *BitmapFont, err error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closeChecked(file, &err)
+	dir := path.Dir(name)
+	return l.read(path.Base(name), file, func(filename string) (io.ReadCloser, error) {
+		return fsys.Open(path.Join(dir, filename))
+	})
 }
 
 // Read reads a bitmap font from a BMFont descriptor in text format including
-// all the referenced page sheet images.
-// The page sheet images are read from the readers provided by the given
-// SheetReaderFunc. These sheet readers are closed after use. If you want to
-// keep them open wrap them via io.NopCloser.
-// The resulting bitmap font is ready to be used to draw text on an image.
-func Read(r io.Reader, sheets SheetReaderFunc) (f *BitmapFont, err error) {
-	desc, err := ReadDescriptor(r)
+// all the referenced page sheet images, like the Read function.
+func (l *Loader) Read(r io.Reader, sheets SheetReaderFunc) (*BitmapFont, error) {
+	return l.read("bmfont", r, sheets)
+}
+
+func (l *Loader) read(filename string, r io.Reader, sheets SheetReaderFunc) (*BitmapFont, error) {
+	progress := newProgressTracker(l.Progress)
+	desc, err := parseDescriptor(filename, progress.reader(r), &l.ParseOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -51,30 +217,181 @@ func Read(r io.Reader, sheets SheetReaderFunc) (f *BitmapFont, err error) {
 		Descriptor: desc,
 		PageSheets: make(map[int]image.Image),
 	}
-	for i, page := range desc.Pages {
-		sheet, err := sheets.read(page.File)
+	if l.LazyPages {
+		loader := *l
+		font.pages = &pageLoader{
+			loader: &loader,
+			desc:   desc,
+			sheets: sheets,
+			loaded: make(map[int]image.Image),
+			errs:   make(map[int]error),
+		}
+		progress.start(0)
+		return &font, nil
+	}
+	sheets = progress.sheets(sheets)
+	referenced := make(map[int]bool, len(desc.Pages))
+	for _, ch := range desc.Chars {
+		referenced[ch.Page] = true
+	}
+	var ids []int
+	for _, id := range sortedKeys(desc.Pages) {
+		if referenced[id] || l.IncludeUnreferencedPages {
+			ids = append(ids, id)
+		}
+	}
+	progress.start(len(ids))
+	pages := l.readPages(ids, desc, sheets, progress)
+	var errs errorList
+	for i, id := range ids {
+		sheet, err := pages[i].sheet, pages[i].err
 		if err != nil {
-			return nil, err
+			if !l.Placeholders {
+				return nil, err
+			}
+			errs = append(errs, err)
+			sheet = placeholderPage(desc.Common.Scale())
+		}
+		font.PageSheets[id] = sheet
+	}
+	return &font, errs.Err()
+}
+
+// A pageResult is the result of reading a page sheet image.
+type pageResult struct {
+	sheet image.Image
+	err   error
+	// panicValue is the value of a panic while the page was read, which
+	// is propagated to the goroutine that loads the font.
+	panicValue any
+}
+
+// readPages reads and decodes the page sheet images of the pages with the
+// given IDs with a bounded number of concurrent workers, and returns the
+// results in the order of the IDs. A panic of a worker is propagated.
+func (l *Loader) readPages(ids []int, desc *Descriptor, sheets SheetReaderFunc, progress *progressTracker) []pageResult {
+	results := make([]pageResult, len(ids))
+	workers := l.Concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(ids))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = l.readPageResult(ids[i], desc, sheets)
+				progress.pageDone()
+			}
+		}()
+	}
+	for i := range ids {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	for _, res := range results {
+		if res.panicValue != nil {
+			panic(res.panicValue)
+		}
+	}
+	return results
+}
+
+func (l *Loader) readPageResult(id int, desc *Descriptor, sheets SheetReaderFunc) (res pageResult) {
+	defer func() {
+		res.panicValue = recover()
+	}()
+	res.sheet, res.err = l.readPage(id, desc, sheets)
+	return res
+}
+
+// readPage reads, decodes and verifies the sheet image of the page with the
+// given ID. Failures are reported as *PageError.
+func (l *Loader) readPage(id int, desc *Descriptor, sheets SheetReaderFunc) (image.Image, error) {
+	page := desc.Pages[id]
+	sheet, err := sheets.read(page.File, l.imageDecoders)
+	if err == nil {
+		err = l.verifyPage(sheet, desc)
+	}
+	if err != nil {
+		return nil, &PageError{PageID: id, File: page.File, Err: err}
+	}
+	return sheet, nil
+}
+
+func (l *Loader) verifyPage(sheet image.Image, desc *Descriptor) error {
+	if l.VerifyPageSizes {
+		if size, want := sheet.Bounds().Size(), desc.Common.Scale(); size != want {
+			return fmt.Errorf("sheet has size %dx%d, but the descriptor declares %dx%d",
+				size.X, size.Y, want.X, want.Y)
+		}
+	}
+	if l.VerifyPixelFormat {
+		return checkPixelFormat(sheet, &desc.Common)
+	}
+	return nil
+}
+
+// A PageError reports a failure to read, decode or verify the sheet image
+// of a page while loading a font. Loader.Placeholders returns the font
+// together with the PageErrors of all failed pages, which can be inspected
+// with errors.As, e.g. to accept a font whose optional emoji page is
+// missing.
+type PageError struct {
+	PageID int    // ID of the page
+	File   string // file name of the page sheet image
+	Err    error  // the underlying error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("bmfont: page %d (%s): %s", e.PageID, e.File, strings.TrimPrefix(e.Err.Error(), "bmfont: "))
+}
+
+// Unwrap returns the underlying error.
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// placeholderPage returns a magenta and black checkerboard image of the
+// given size, or of 256×256 pixels if the size is unknown.
+func placeholderPage(size image.Point) image.Image {
+	if size.X <= 0 || size.Y <= 0 {
+		size = image.Pt(256, 256)
+	}
+	const square = 8
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	magenta := color.RGBA{R: 0xff, B: 0xff, A: 0xff}
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			if (x/square+y/square)%2 == 0 {
+				img.SetRGBA(x, y, magenta)
+			} else {
+				img.SetRGBA(x, y, color.RGBA{A: 0xff})
+			}
 		}
-		font.PageSheets[i] = sheet
 	}
-	return &font, nil
+	return img
 }
 
 // A SheetReaderFunc is a function that provides a reader for a page sheet
 // image file name. It is used by the Read function to load a font from a
 // different source than only the file system. The filename parameter is the
 // name provided by the File field of a Page in the font descriptor.
+// A font with multiple pages may read their sheets concurrently, see
+// Loader.Concurrency.
 type SheetReaderFunc func(filename string) (io.ReadCloser, error)
 
-func (f SheetReaderFunc) read(filename string) (img image.Image, err error) {
+func (f SheetReaderFunc) read(filename string, decoders map[string]ImageDecoder) (img image.Image, err error) {
 	r, err := f(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer closeChecked(r, &err)
-	sheet, _, err := image.Decode(r)
-	return sheet, err
+	return decodeSheet(filename, r, decoders)
 }
 
 func fileSheets(directory string) SheetReaderFunc {
@@ -92,9 +409,38 @@ func fileSheets(directory string) SheetReaderFunc {
 // given position. The start position is on the base line of the first line of
 // text, and the characters usually extend above the base line.
 // The text may contain newlines. Text with multiple lines is drawn left
-// aligned.
+// aligned. DrawTextAligned and DrawTextOpts draw text with other alignments.
 func (f *BitmapFont) DrawText(dst draw.Image, pos image.Point, text string) {
-	f.drawText(imageDrawer{dst}, pos, text)
+	f.drawText(dst, pos, text, nil)
+}
+
+// DrawTextColor draws the given text on the destination image like
+// DrawText, with the glyphs tinted with the given color: the colors of the
+// page sheets are multiplied with it, so for the usual white glyphs the text
+// is drawn in this color, and one font can be drawn in any color.
+func (f *BitmapFont) DrawTextColor(dst draw.Image, pos image.Point, text string, c color.Color) {
+	f.drawText(dst, pos, text, &Style{Color: c})
+}
+
+// DrawTextAligned draws the given text on the destination image like
+// DrawText, but aligns each line of text horizontally at the x coordinate of
+// the given position: with AlignLeft the lines start at it, with AlignCenter
+// they are centered on it, and with AlignRight they end at it. The y
+// coordinate is on the base line of the first line.
+func (f *BitmapFont) DrawTextAligned(dst draw.Image, pos image.Point, text string, align Alignment) {
+	style := &Style{Align: align}
+	l := f.layout(text, style, &f.stats)
+	var width fixed.Int26_6
+	for _, ln := range l.lines {
+		width = max(width, ln.width)
+	}
+	switch align {
+	case AlignCenter:
+		pos.X -= (width / 2).Round()
+	case AlignRight:
+		pos.X -= width.Round()
+	}
+	l.draw(imageDrawer{dst: dst}, pos, &f.stats)
 }
 
 // MeasureText calculates the bounding box for the given text as if it was
@@ -103,75 +449,233 @@ func (f *BitmapFont) DrawText(dst draw.Image, pos image.Point, text string) {
 // extend above the base line. The X coordinate can also be negative, depending
 // on the character offsets.
 func (f *BitmapFont) MeasureText(text string) image.Rectangle {
-	var m boundsMeasurer
-	f.drawText(&m, image.Point{}, text)
-	return m.bounds
-}
-
-func (f *BitmapFont) drawText(dst drawer, pos image.Point, text string) {
-	cursor := pos
-	var prev rune
-	for i, r := range text {
-		if r == '\n' {
-			cursor.X = pos.X
-			cursor.Y += f.Descriptor.Common.LineHeight
-			continue
-		}
-		ch, ok := f.char(r)
-		if !ok {
-			continue
-		}
-		sheet := f.PageSheets[ch.Page]
-		min := image.Pt(
-			cursor.X+ch.XOffset,
-			cursor.Y-f.Descriptor.Common.Base+ch.YOffset,
-		)
-		dr := image.Rectangle{
-			Min: min,
-			Max: min.Add(ch.Size()),
-		}
-		dst.Draw(dr, sheet, ch.Pos())
-
-		cursor.X += ch.XAdvance
-		if i > 0 {
-			pair := CharPair{First: prev, Second: r}
-			kerning, ok := f.Descriptor.Kerning[pair]
-			if ok {
-				cursor.X += kerning.Amount
-			}
+	return f.measureText(text, nil)
+}
+
+// DrawTextFixed draws the given text with the given style on the destination
+// image like DrawText, but at a fractional start position, e.g. for text that
+// moves smoothly or that continues after other text at a fractional pen
+// position. The advances of the glyphs are accumulated with fractional
+// precision, and the glyph positions are rounded as specified by the
+// GlyphRounding of the style. The padding and the background color of the
+// style are ignored.
+func (f *BitmapFont) DrawTextFixed(dst draw.Image, pos fixed.Point26_6, text string, style *Style) {
+	l := f.layout(text, style, &f.stats)
+	l.drawHighlight(dst, pos, style)
+	l = l.drawnGlyphs(style)
+	l.drawShadow(dst, pos, style)
+	l.drawFixed(newImageDrawer(dst, l, style), pos, &f.stats)
+}
+
+// MeasureTextFixed calculates the exact bounding box of the glyphs of the
+// given text laid out with the given style as if it was drawn at position
+// (0, 0) with DrawTextFixed, without rounding the glyph positions and
+// without the shear of slanted text.
+func (f *BitmapFont) MeasureTextFixed(text string, style *Style) fixed.Rectangle26_6 {
+	l := f.layout(text, style, nil)
+	var bounds fixed.Rectangle26_6
+	l.eachGlyph(func(_ int, g *glyph) {
+		if r := l.glyphRectFixed(fixed.Point26_6{}, *g); !r.Empty() {
+			bounds = bounds.Union(r)
 		}
-		prev = r
+	})
+	return bounds
+}
+
+// DrawTextf formats according to a format specifier and draws the resulting
+// text on the destination image like DrawText.
+func (f *BitmapFont) DrawTextf(dst draw.Image, pos image.Point, format string, args ...any) {
+	f.DrawText(dst, pos, fmt.Sprintf(format, args...))
+}
+
+// MeasureTextf formats according to a format specifier and calculates the
+// bounding box for the resulting text like MeasureText.
+func (f *BitmapFont) MeasureTextf(format string, args ...any) image.Rectangle {
+	return f.MeasureText(fmt.Sprintf(format, args...))
+}
+
+func (f *BitmapFont) drawText(dst draw.Image, pos image.Point, text string, style *Style) {
+	f.drawLayout(dst, pos, f.layout(text, style, &f.stats), style)
+}
+
+// drawLayout draws the highlight, the shadow and the glyphs of the layout
+// with the given style.
+func (f *BitmapFont) drawLayout(dst draw.Image, pos image.Point, l *layout, style *Style) {
+	l.drawHighlight(dst, fixed.P(pos.X, pos.Y), style)
+	l = l.drawnGlyphs(style)
+	l.drawShadow(dst, fixed.P(pos.X, pos.Y), style)
+	l.draw(newImageDrawer(dst, l, style), pos, &f.stats)
+}
+
+func (f *BitmapFont) measureText(text string, style *Style) image.Rectangle {
+	return measureLayout(f.layout(text, style, nil), style)
+}
+
+// measureLayout calculates the bounding box of the layout like MeasureText,
+// including the shadow and the highlight of the style.
+func measureLayout(l *layout, style *Style) image.Rectangle {
+	bounds := l.bounds()
+	bounds = bounds.Union(style.shadowBounds(bounds))
+	for _, box := range l.highlightBoxes(style) {
+		bounds = bounds.Union(box)
 	}
+	return bounds
 }
 
-func (f *BitmapFont) char(r rune) (c Char, ok bool) {
-	c, ok = f.Descriptor.Chars[r]
-	if !ok {
+// variant returns the font or its prescaled variant whose size is closest to
+// the effective scale of the style, and the factor its glyphs must be scaled
+// with to reach this scale.
+func (f *BitmapFont) variant(style *Style) (*BitmapFont, float64) {
+	s := style.scale()
+	v, k := nearestFont(f.Variants, f, 1, s)
+	return v, s / k
+}
+
+// char returns the character data for the given rune. If the font does not
+// include the rune, found is false and the fallback character '?' is
+// returned, if present.
+func (f *BitmapFont) char(r rune) (c Char, found, ok bool) {
+	c, found = f.Descriptor.Chars[r]
+	if !found {
 		c, ok = f.Descriptor.Chars['?']
-		return c, ok
+		return c, false, ok
 	}
-	return c, ok
+	return c, true, true
 }
 
+// A drawer draws the glyph bitmaps of a layout. Draw draws the source
+// rectangle of the glyph's image scaled to the destination rectangle, and
+// DrawTransformed draws it transformed by an affine transformation, e.g. for
+// slanted text or fractional positions, covering the given bounds.
 type drawer interface {
-	Draw(r image.Rectangle, src image.Image, sp image.Point)
+	Draw(dr image.Rectangle, src glyphSource, sr image.Rectangle)
+	DrawTransformed(m f64.Aff3, bounds image.Rectangle, src glyphSource, sr image.Rectangle)
 }
 
+// A glyphSource returns the image a glyph's bitmap is drawn from, or nil if
+// it can't be loaded. Drawers that only measure the glyphs don't call it, so
+// that measuring a text doesn't load the page sheets of a font loaded with
+// Loader.LazyPages.
+type glyphSource func() image.Image
+
 type imageDrawer struct {
-	draw.Image
+	dst draw.Image
+	// color is the color the glyphs are tinted with, if not nil.
+	color color.Color
+	// compositor composites the glyphs with the destination image. If it
+	// is nil they are drawn with draw.Over.
+	compositor draw.Drawer
+}
+
+// newImageDrawer returns a drawer that draws the glyphs of the layout on the
+// destination image with the clip rectangle, the color and the compositor of
+// the style. The glyphs of colored layouts keep their own colors.
+func newImageDrawer(dst draw.Image, l *layout, style *Style) imageDrawer {
+	d := imageDrawer{dst: style.clip(dst), color: style.color(), compositor: style.compositor()}
+	if l.colored {
+		d.color = nil
+	}
+	return d
+}
+
+func (d imageDrawer) Draw(dr image.Rectangle, glyph glyphSource, sr image.Rectangle) {
+	src := glyph()
+	if src == nil {
+		return
+	}
+	if d.color != nil {
+		src = newTintedImage(src, d.color)
+	}
+	if dr.Size() == sr.Size() {
+		if d.compositor == nil {
+			draw.Draw(d.dst, dr, src, sr.Min, draw.Over)
+		} else {
+			d.compositor.Draw(d.dst, dr, src, sr.Min)
+		}
+		return
+	}
+	composite(d.dst, d.compositor, dr, func(dst draw.Image, op draw.Op) {
+		if dr.Dx() >= sr.Dx() {
+			xdraw.NearestNeighbor.Scale(dst, dr, src, sr, op, nil)
+		} else {
+			xdraw.ApproxBiLinear.Scale(dst, dr, src, sr, op, nil)
+		}
+	})
+}
+
+func (d imageDrawer) DrawTransformed(m f64.Aff3, bounds image.Rectangle, glyph glyphSource, sr image.Rectangle) {
+	src := glyph()
+	if src == nil {
+		return
+	}
+	if d.color != nil {
+		src = newTintedImage(src, d.color)
+	}
+	composite(d.dst, d.compositor, bounds, func(dst draw.Image, op draw.Op) {
+		xdraw.BiLinear.Transform(dst, m, src, sr, op, nil)
+	})
 }
 
-func (dst imageDrawer) Draw(r image.Rectangle, src image.Image, sp image.Point) {
-	draw.Draw(dst, r, src, sp, draw.Over)
+// composite calls the draw function, which draws within the rectangle r,
+// with the destination image and the compositor, if it is nil or a draw.Op.
+// For other compositors it is called with a temporary transparent image and
+// draw.Src instead, which is then composited with the destination image.
+func composite(dst draw.Image, compositor draw.Drawer, r image.Rectangle, fn func(dst draw.Image, op draw.Op)) {
+	switch c := compositor.(type) {
+	case nil:
+		fn(dst, draw.Over)
+		return
+	case draw.Op:
+		fn(dst, c)
+		return
+	}
+	tmp := image.NewRGBA(r.Intersect(dst.Bounds()))
+	if tmp.Rect.Empty() {
+		return
+	}
+	fn(tmp, draw.Src)
+	compositor.Draw(dst, tmp.Rect, tmp, tmp.Rect.Min)
 }
 
 type boundsMeasurer struct {
 	bounds image.Rectangle
 }
 
-func (m *boundsMeasurer) Draw(r image.Rectangle, src image.Image, sp image.Point) {
-	_, _ = src, sp
-	m.bounds = m.bounds.Union(r)
+func (m *boundsMeasurer) Draw(dr image.Rectangle, _ glyphSource, _ image.Rectangle) {
+	m.bounds = m.bounds.Union(dr)
+}
+
+func (m *boundsMeasurer) DrawTransformed(_ f64.Aff3, bounds image.Rectangle, _ glyphSource, _ image.Rectangle) {
+	m.bounds = m.bounds.Union(bounds)
+}
+
+// A tintedImage is an image whose colors are multiplied with a tint color.
+type tintedImage struct {
+	image.Image
+	r, g, b, a uint32
+}
+
+func newTintedImage(img image.Image, c color.Color) *tintedImage {
+	r, g, b, a := c.RGBA()
+	return &tintedImage{Image: img, r: r, g: g, b: b, a: a}
+}
+
+func (t *tintedImage) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (t *tintedImage) At(x, y int) color.Color {
+	return t.RGBA64At(x, y)
+}
+
+func (t *tintedImage) RGBA64At(x, y int) color.RGBA64 {
+	r, g, b, a := t.Image.At(x, y).RGBA()
+	return color.RGBA64{
+		R: uint16(r * t.r / 0xffff),
+		G: uint16(g * t.g / 0xffff),
+		B: uint16(b * t.b / 0xffff),
+		A: uint16(a * t.a / 0xffff),
+	}
 }
 
 func closeChecked(c io.Closer, err *error) {
@@ -0,0 +1,344 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestMeasureTextf(t *testing.T) {
+	f := testFont("0123456789", 1)
+	if got, want := f.MeasureTextf("%d%s", 12, "3"), f.MeasureText("123"); got != want {
+		t.Errorf("MeasureTextf: got %v, want %v", got, want)
+	}
+	if got, want := f.MeasureTextf("%03d", 7).Dx(), 3*5-1; got != want {
+		t.Errorf("MeasureTextf: got width %d, want %d", got, want)
+	}
+}
+
+func TestDrawTextf(t *testing.T) {
+	f := testFont("0123456789", 1)
+	f.DrawTextf(image.NewNRGBA(image.Rect(0, 0, 32, 16)), image.Pt(0, 8), "%d", 42)
+	if got := f.Stats().GlyphsDrawn; got != 2 {
+		t.Errorf("DrawTextf drew %d glyphs, want 2", got)
+	}
+}
+
+// pngSheets returns a SheetReaderFunc that provides an empty PNG image of
+// the given size for every page.
+func pngSheets(t *testing.T, size image.Point) SheetReaderFunc {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rectangle{Max: size})); err != nil {
+		t.Fatal(err)
+	}
+	return func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}
+}
+
+func TestLoaderVerifyPageSizes(t *testing.T) {
+	l := Loader{VerifyPageSizes: true}
+	if _, err := l.Read(strings.NewReader(testDescriptor), pngSheets(t, image.Pt(16, 8))); err != nil {
+		t.Fatalf("matching page size: %v", err)
+	}
+	if _, err := Read(strings.NewReader(testDescriptor), pngSheets(t, image.Pt(8, 8))); err != nil {
+		t.Fatalf("page sizes are not verified by default: %v", err)
+	}
+	_, err := l.Read(strings.NewReader(testDescriptor), pngSheets(t, image.Pt(8, 8)))
+	want := "sheet has size 8x8, but the descriptor declares 16x8"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func TestCommonScale(t *testing.T) {
+	c := Common{ScaleW: 256, ScaleH: 128}
+	if got, want := c.Scale(), image.Pt(256, 128); got != want {
+		t.Errorf("got scale %v, want %v", got, want)
+	}
+}
+
+func TestLoaderPlaceholders(t *testing.T) {
+	descriptor := strings.Replace(testDescriptor, "chars count=2\n",
+		"page id=1 file=\"missing.png\"\nchars count=2\n", 1)
+	descriptor = strings.Replace(descriptor, "xadvance=5 page=0 chnl=15\nkernings", "xadvance=5 page=1 chnl=15\nkernings", 1)
+	good := pngSheets(t, image.Pt(16, 8))
+	sheets := func(filename string) (io.ReadCloser, error) {
+		if filename == "missing.png" {
+			return nil, fs.ErrNotExist
+		}
+		return good(filename)
+	}
+
+	if f, err := Read(strings.NewReader(descriptor), sheets); err == nil || f != nil {
+		t.Fatalf("without placeholders: got font %v and error %v, want only an error", f, err)
+	}
+
+	l := Loader{Placeholders: true}
+	f, err := l.Read(strings.NewReader(descriptor), sheets)
+	if f == nil {
+		t.Fatalf("got no font, error %v", err)
+	}
+	var pageErr *PageError
+	if !errors.As(err, &pageErr) || pageErr.PageID != 1 || !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("got error %v, want a PageError for page 1", err)
+	}
+	placeholder := f.PageSheets[1]
+	if placeholder == nil || placeholder.Bounds().Size() != image.Pt(16, 8) {
+		t.Fatalf("got placeholder %v, want a 16x8 image", placeholder)
+	}
+	if got := color.RGBAModel.Convert(placeholder.At(0, 0)); got != (color.RGBA{R: 0xFF, B: 0xFF, A: 0xFF}) {
+		t.Errorf("got placeholder color %v, want magenta", got)
+	}
+	if got := f.PageSheets[0].Bounds().Size(); got != image.Pt(16, 8) {
+		t.Errorf("got intact page of size %v, want 16x8", got)
+	}
+}
+
+func TestLoaderUnreferencedPages(t *testing.T) {
+	descriptor := strings.Replace(testDescriptor, "chars count=2\n",
+		"page id=1 file=\"unused.png\"\nchars count=2\n", 1)
+	var read []string
+	good := pngSheets(t, image.Pt(16, 8))
+	sheets := func(filename string) (io.ReadCloser, error) {
+		read = append(read, filename)
+		return good(filename)
+	}
+
+	f, err := Read(strings.NewReader(descriptor), sheets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.PageSheets[1]; ok || !reflect.DeepEqual(read, []string{"sheet.png"}) {
+		t.Errorf("read sheets %q, want the unreferenced page to be skipped", read)
+	}
+
+	read = nil
+	l := Loader{IncludeUnreferencedPages: true, Concurrency: 1}
+	if f, err = l.Read(strings.NewReader(descriptor), sheets); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.PageSheets[1]; !ok || !reflect.DeepEqual(read, []string{"sheet.png", "unused.png"}) {
+		t.Errorf("read sheets %q, want all pages", read)
+	}
+}
+
+// pngData returns the encoded PNG image of an empty sheet of the given size.
+func pngData(t *testing.T, size image.Point) []byte {
+	t.Helper()
+	rc, err := pngSheets(t, size)("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fonts/test.fnt":   {Data: []byte(testDescriptor)},
+		"fonts/sheet.png":  {Data: pngData(t, image.Pt(16, 8))},
+		"fonts/broken.fnt": {Data: []byte("info face=\"Broken\"\ncommon lineHeight=8\n")},
+	}
+	f, err := LoadFS(fsys, "fonts/test.fnt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Descriptor.Info.Face != "Test" || f.PageSheets[0].Bounds().Size() != image.Pt(16, 8) {
+		t.Errorf("got face %q and sheet %v", f.Descriptor.Info.Face, f.PageSheets[0].Bounds())
+	}
+	if _, err := LoadFS(fsys, "test.fnt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing descriptor: got error %v, want %v", err, fs.ErrNotExist)
+	}
+	if _, err := LoadFS(fsys, "fonts/broken.fnt"); err == nil || !strings.HasPrefix(err.Error(), "broken.fnt:") {
+		t.Errorf("got error %v, want a position in broken.fnt", err)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	sheets := map[string][]byte{"sheet.png": pngData(t, image.Pt(16, 8))}
+	f, err := ReadAll([]byte(testDescriptor), sheets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.PageSheets) != 1 || f.PageSheets[0].Bounds().Size() != image.Pt(16, 8) {
+		t.Errorf("got page sheets %v", f.PageSheets)
+	}
+	_, err = ReadAll([]byte(testDescriptor), nil)
+	if err == nil || !strings.Contains(err.Error(), `missing sheet image "sheet.png"`) {
+		t.Errorf("got error %v, want a missing sheet image", err)
+	}
+}
+
+// multiPageDescriptor returns a descriptor in text format with n pages and
+// a char on each page. The sheet image of page i is named "page_i.png".
+func multiPageDescriptor(n int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "info face=\"Test\" size=8\ncommon lineHeight=8 base=6 scaleW=16 scaleH=8 pages=%d\n", n)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "page id=%d file=\"page_%d.png\"\n", i, i)
+	}
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "char id=%d x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=%d chnl=15\n", 'A'+i, i)
+	}
+	return sb.String()
+}
+
+func TestLoaderConcurrency(t *testing.T) {
+	const pages, concurrency = 6, 2
+	// The sheet of page i is i+1 pixels wide, so that mixed up pages are
+	// detected.
+	data := make(map[string][]byte)
+	for i := 0; i < pages; i++ {
+		data[fmt.Sprintf("page_%d.png", i)] = pngData(t, image.Pt(i+1, 1))
+	}
+	var active, maxActive atomic.Int32
+	started := make(chan struct{})
+	var once sync.Once
+	sheets := func(filename string) (io.ReadCloser, error) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for m := maxActive.Load(); n > m && !maxActive.CompareAndSwap(m, n); m = maxActive.Load() {
+		}
+		if n == concurrency {
+			once.Do(func() { close(started) })
+		}
+		// Wait until the workers run concurrently.
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("pages are not read concurrently")
+		}
+		return io.NopCloser(bytes.NewReader(data[filename])), nil
+	}
+
+	l := Loader{Concurrency: concurrency}
+	f, err := l.Read(strings.NewReader(multiPageDescriptor(pages)), sheets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := maxActive.Load(); got != concurrency {
+		t.Errorf("got %d concurrently read pages, want %d", got, concurrency)
+	}
+	for i := 0; i < pages; i++ {
+		if got := f.PageSheets[i].Bounds().Dx(); got != i+1 {
+			t.Errorf("page %d: got sheet of width %d, want %d", i, got, i+1)
+		}
+	}
+}
+
+func TestLoaderPropagatesPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "broken sheet reader" {
+			t.Errorf("got panic value %v, want the panic of the sheet reader", r)
+		}
+	}()
+	_, _ = Read(strings.NewReader(multiPageDescriptor(3)), func(string) (io.ReadCloser, error) {
+		panic("broken sheet reader")
+	})
+	t.Error("no panic")
+}
+
+func TestPageError(t *testing.T) {
+	descriptor := strings.Replace(multiPageDescriptor(2), `file="page_1.png"`, `file="emoji.png"`, 1)
+	good := pngSheets(t, image.Pt(16, 8))
+	sheets := func(filename string) (io.ReadCloser, error) {
+		if filename == "emoji.png" {
+			return io.NopCloser(strings.NewReader("not an image")), nil
+		}
+		return good(filename)
+	}
+	_, err := Read(strings.NewReader(descriptor), sheets)
+	var pageErr *PageError
+	if !errors.As(err, &pageErr) {
+		t.Fatalf("got error %v, want a PageError", err)
+	}
+	if pageErr.PageID != 1 || pageErr.File != "emoji.png" || !errors.Is(err, image.ErrFormat) {
+		t.Errorf("got page error %+v, want a format error of page 1 (emoji.png)", pageErr)
+	}
+	if want := "bmfont: page 1 (emoji.png): image: unknown format"; err.Error() != want {
+		t.Errorf("got error message %q, want %q", err.Error(), want)
+	}
+}
+
+func TestDrawTextColor(t *testing.T) {
+	f := opaqueFont("A")
+	// The glyph has a white and a half transparent gray pixel in its top row.
+	sheet := f.PageSheets[0].(*image.NRGBA)
+	sheet.SetNRGBA(1, 0, color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80})
+	dst := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	f.DrawTextColor(dst, image.Pt(0, 6), "A", color.RGBA{G: 0xff, A: 0xff})
+	if got, want := dst.RGBAAt(0, 0), (color.RGBA{G: 0xff, A: 0xff}); got != want {
+		t.Errorf("got white pixel tinted %v, want %v", got, want)
+	}
+	if got, want := dst.RGBAAt(1, 0), (color.RGBA{G: 0x40, A: 0x80}); got != want {
+		t.Errorf("got gray pixel tinted %v, want %v", got, want)
+	}
+	if got := dst.RGBAAt(4, 0); got != (color.RGBA{}) {
+		t.Errorf("got %v outside the glyph, want transparent", got)
+	}
+}
+
+// drawerFunc is a draw.Drawer implemented by a function.
+type drawerFunc func(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point)
+
+func (fn drawerFunc) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	fn(dst, r, src, sp)
+}
+
+func TestCompositor(t *testing.T) {
+	f := testFont("A", 1)
+	// The glyph has a half transparent white and a transparent pixel.
+	sheet := f.PageSheets[0].(*image.NRGBA)
+	sheet.SetNRGBA(0, 0, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x80})
+	red := color.RGBA{R: 0xff, A: 0xff}
+	tests := []struct {
+		name       string
+		compositor draw.Drawer
+		want       [2]color.RGBA
+	}{
+		{"over", nil, [2]color.RGBA{{R: 0xff, G: 0x80, B: 0x80, A: 0xff}, red}},
+		{"src", draw.Src, [2]color.RGBA{{R: 0x80, G: 0x80, B: 0x80, A: 0x80}, {}}},
+		{
+			"custom",
+			drawerFunc(func(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+				// Draws the coverage of the glyph in blue.
+				draw.DrawMask(dst, r, image.NewUniform(color.RGBA{B: 0xff, A: 0xff}), image.Point{}, src, sp, draw.Src)
+			}),
+			[2]color.RGBA{{B: 0x80, A: 0x80}, {}},
+		},
+	}
+	for _, tt := range tests {
+		dst := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+		f.DrawTextOpts(dst, image.Pt(0, 6), "A", WithCompositor(tt.compositor))
+		got := [2]color.RGBA{dst.RGBAAt(0, 0), dst.RGBAAt(1, 0)}
+		if got != tt.want {
+			t.Errorf("%s: got pixels %v, want %v", tt.name, got, tt.want)
+		}
+		if got := dst.RGBAAt(6, 6); got != red {
+			t.Errorf("%s: got %v outside the glyph, want the unchanged %v", tt.name, got, red)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// A bundle is a zip archive containing a font descriptor file (.fnt) in text
+// format and the page sheet images it references, for distributing a font as
+// a single file. The descriptor is the only .fnt file in the root directory
+// of the archive, and the page files are stored relative to it.
+
+// WriteBundle writes a bundle with the font descriptor file at the given
+// path and the page sheet image files it references to w. The files are
+// stored unchanged.
+func WriteBundle(w io.Writer, descriptorPath string) (err error) {
+	desc, err := LoadDescriptor(descriptorPath)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	defer closeChecked(zw, &err)
+	dir := filepath.Dir(descriptorPath)
+	if err := addFile(zw, filepath.Base(descriptorPath), descriptorPath); err != nil {
+		return err
+	}
+	added := make(map[string]bool)
+	for _, id := range sortedKeys(desc.Pages) {
+		file := desc.Pages[id].File
+		if added[file] {
+			continue
+		}
+		added[file] = true
+		name := path.Clean(filepath.ToSlash(file))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("bmfont: page file %q is outside of the descriptor's directory", file)
+		}
+		if err := addFile(zw, name, filepath.Join(dir, file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addFile(zw *zip.Writer, name, path string) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer closeChecked(f, &err)
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// LoadBundle loads a bitmap font from a bundle file created by WriteBundle.
+func LoadBundle(path string) (f *BitmapFont, err error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeChecked(zr, &err)
+	return readBundle(&zr.Reader)
+}
+
+// ReadBundle reads a bitmap font from a bundle created by WriteBundle with
+// the given size in bytes.
+func ReadBundle(r io.ReaderAt, size int64) (*BitmapFont, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return readBundle(zr)
+}
+
+func readBundle(zr *zip.Reader) (f *BitmapFont, err error) {
+	name, err := bundleDescriptor(zr)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closeChecked(rc, &err)
+	return Read(rc, func(filename string) (io.ReadCloser, error) {
+		return zr.Open(path.Clean(filepath.ToSlash(filename)))
+	})
+}
+
+// bundleDescriptor returns the name of the descriptor file in the bundle.
+func bundleDescriptor(zr *zip.Reader) (string, error) {
+	for _, file := range zr.File {
+		if !strings.Contains(file.Name, "/") && strings.EqualFold(path.Ext(file.Name), ".fnt") {
+			return file.Name, nil
+		}
+	}
+	return "", errors.New("bmfont: bundle contains no font descriptor (.fnt) file")
+}
+
+// ExtractBundle extracts the files of the bundle file at the given path
+// into the given directory, which is created if necessary.
+func ExtractBundle(bundlePath, dir string) (err error) {
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer closeChecked(zr, &err)
+	if _, err := bundleDescriptor(&zr.Reader); err != nil {
+		return err
+	}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if !filepath.IsLocal(file.Name) {
+			return fmt.Errorf("bmfont: bundle entry %q is outside of the target directory", file.Name)
+		}
+		if err := extractFile(file, filepath.Join(dir, filepath.FromSlash(file.Name))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(file *zip.File, dst string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer closeChecked(rc, &err)
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer closeChecked(f, &err)
+	_, err = io.Copy(f, rc)
+	return err
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// saveTestFont saves the font in a temporary directory and returns the
+// path of its descriptor.
+func saveTestFont(t *testing.T, f *BitmapFont) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.fnt")
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	path := saveTestFont(t, opaqueFont("AB"))
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ReadBundle(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Descriptor.Chars) != 2 || f.PageSheets[0] == nil {
+		t.Errorf("got %d chars and page sheets %v, want 2 chars and page 0", len(f.Descriptor.Chars), f.PageSheets)
+	}
+
+	bundle := filepath.Join(t.TempDir(), "test.zip")
+	if err := os.WriteFile(bundle, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "extracted")
+	if err := ExtractBundle(bundle, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"test.fnt", "sheet.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("extracted file %s: %v", name, err)
+		}
+	}
+	if _, err := LoadBundle(bundle); err != nil {
+		t.Errorf("LoadBundle: %v", err)
+	}
+}
+
+func TestExtractBundleOutside(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"test.fnt", "../evil.png"} {
+		if _, err := zw.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "evil.zip")
+	if err := os.WriteFile(bundle, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractBundle(bundle, filepath.Join(dir, "out")); err == nil {
+		t.Error("ExtractBundle extracted an entry outside of the target directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.png")); err == nil {
+		t.Error("the entry outside of the target directory was written")
+	}
+}
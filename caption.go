@@ -0,0 +1,132 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// CaptionAnchor specifies where in its region a caption is placed.
+type CaptionAnchor int
+
+const (
+	CaptionTop    CaptionAnchor = iota // attached to the top edge
+	CaptionBottom                      // attached to the bottom edge
+	CaptionCenter                      // centered vertically
+)
+
+// CaptionOptions configure how DrawCaption draws a caption.
+// A nil *CaptionOptions is valid and selects the defaults.
+type CaptionOptions struct {
+	// Anchor specifies where in the region the caption is placed.
+	Anchor CaptionAnchor
+	// Color is the fill color of the text. The default is white.
+	Color color.Color
+	// OutlineColor is the color of the outline around the glyphs.
+	// The default is black.
+	OutlineColor color.Color
+	// OutlineWidth is the thickness of the outline in pixels. If it is 0
+	// the outline width is derived from the scale of the text. A negative
+	// value disables the outline.
+	OutlineWidth int
+	// MaxScale limits the scale factor the text is enlarged with.
+	// If it is 0 the text is enlarged as long as it fits into the region.
+	MaxScale float64
+}
+
+// DrawCaption draws an outlined caption text into the given region of the
+// destination image, e.g. for captioning photos and thumbnails. The text is
+// centered horizontally, wrapped at word boundaries to fit the width of the
+// region and scaled to the largest size that still fits into the region.
+// Scale factors of 1 and greater are rounded down to whole numbers to keep
+// the glyphs crisp.
+func (f *BitmapFont) DrawCaption(dst draw.Image, region image.Rectangle, text string, opts *CaptionOptions) {
+	if opts == nil {
+		opts = &CaptionOptions{}
+	}
+	style, outline := f.fitCaption(region, text, opts)
+	bounds := f.measureText(text, style)
+	pos := image.Pt(region.Min.X+outline, region.Min.Y+outline-bounds.Min.Y)
+	switch opts.Anchor {
+	case CaptionBottom:
+		pos.Y = region.Max.Y - outline - bounds.Max.Y
+	case CaptionCenter:
+		pos.Y = region.Min.Y + (region.Dy()-bounds.Dy())/2 - bounds.Min.Y
+	}
+	if outline > 0 {
+		outlineStyle := *style
+		outlineStyle.Color = opts.OutlineColor
+		if outlineStyle.Color == nil {
+			outlineStyle.Color = color.Black
+		}
+		for dy := -outline; dy <= outline; dy++ {
+			for dx := -outline; dx <= outline; dx++ {
+				if dx*dx+dy*dy > outline*outline {
+					continue
+				}
+				f.drawText(dst, pos.Add(image.Pt(dx, dy)), text, &outlineStyle)
+			}
+		}
+	}
+	f.drawText(dst, pos, text, style)
+}
+
+// fitCaption determines the style with the largest scale at which the
+// caption text fits into the region, and the outline width for this scale.
+func (f *BitmapFont) fitCaption(region image.Rectangle, text string, opts *CaptionOptions) (style *Style, outline int) {
+	fill := opts.Color
+	if fill == nil {
+		fill = color.White
+	}
+	outlineWidth := func(scale float64) int {
+		switch {
+		case opts.OutlineWidth < 0:
+			return 0
+		case opts.OutlineWidth > 0:
+			return opts.OutlineWidth
+		}
+		return max(1, int(math.Round(scale)))
+	}
+	styleFor := func(scale float64) *Style {
+		return &Style{
+			Color:    fill,
+			Scale:    scale,
+			MaxWidth: max(1, region.Dx()-2*outlineWidth(scale)),
+			Align:    AlignCenter,
+		}
+	}
+	fits := func(scale float64) bool {
+		style := styleFor(scale)
+		bounds := f.measureText(text, style)
+		o := outlineWidth(scale)
+		return bounds.Dx()+2*o <= region.Dx() && bounds.Dy()+2*o <= region.Dy()
+	}
+
+	lo := 1.0 / 64
+	hi := float64(region.Dy()) / float64(max(1, f.Descriptor.Common.LineHeight))
+	if opts.MaxScale > 0 {
+		hi = min(hi, opts.MaxScale)
+	}
+	hi = max(hi, lo)
+	if fits(hi) {
+		lo = hi
+	}
+	for i := 0; i < 20 && hi-lo > 1.0/256; i++ {
+		mid := (lo + hi) / 2
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	scale := lo
+	if scale >= 1 {
+		scale = math.Floor(scale)
+	}
+	return styleFor(scale), outlineWidth(scale)
+}
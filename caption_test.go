@@ -0,0 +1,70 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFitCaption(t *testing.T) {
+	f := opaqueFont("AB ")
+	region := image.Rect(0, 0, 100, 40)
+	style, outline := f.fitCaption(region, "AB AB", &CaptionOptions{})
+	if style.Scale < 1 || style.Scale != float64(int(style.Scale)) {
+		t.Errorf("got scale %g, want a whole number of at least 1", style.Scale)
+	}
+	if outline != int(style.Scale) {
+		t.Errorf("got outline width %d, want the scale %g", outline, style.Scale)
+	}
+	bounds := f.measureText("AB AB", style)
+	if bounds.Dx()+2*outline > region.Dx() || bounds.Dy()+2*outline > region.Dy() {
+		t.Errorf("caption of size %v with outline %d doesn't fit into %v", bounds.Size(), outline, region)
+	}
+	style, _ = f.fitCaption(region, "AB AB", &CaptionOptions{MaxScale: 2})
+	if style.Scale != 2 {
+		t.Errorf("got scale %g, want the maximum scale 2", style.Scale)
+	}
+}
+
+func TestDrawCaptionAnchor(t *testing.T) {
+	f := opaqueFont("A")
+	dst := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	f.DrawCaption(dst, dst.Bounds(), "A", &CaptionOptions{Anchor: CaptionBottom, MaxScale: 1, OutlineWidth: -1})
+	white := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	var top, bottom int
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			if dst.RGBAAt(x, y) == white {
+				if y < 20 {
+					top++
+				} else {
+					bottom++
+				}
+			}
+		}
+	}
+	if top != 0 || bottom == 0 {
+		t.Errorf("got %d pixels in the top half and %d in the bottom half, want the caption at the bottom", top, bottom)
+	}
+}
+
+func TestDrawCaptionCenter(t *testing.T) {
+	f := opaqueFont("A")
+	dst := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	f.DrawCaption(dst, dst.Bounds(), "A", &CaptionOptions{Anchor: CaptionCenter, MaxScale: 1, OutlineWidth: -1})
+	var opaque image.Rectangle
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			if dst.RGBAAt(x, y).A != 0 {
+				opaque = opaque.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if want := image.Rect(18, 17, 22, 23); opaque != want {
+		t.Errorf("got caption at %v, want it centered at %v", opaque, want)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fzipp/bmfont"
+)
+
+var benchCmd = &command{
+	name:    "bench",
+	summary: "measure parse, load, draw and measure performance of a font",
+	run:     runBench,
+}
+
+func runBench(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt [corpus.txt]")
+	duration := fs.Duration("duration", time.Second, "minimum running `time` of each benchmark")
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	font, err := bmfont.Load(path)
+	if err != nil {
+		return err
+	}
+	corpus := defaultPangram
+	if fs.NArg() == 2 {
+		b, err := os.ReadFile(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		corpus = strings.ReplaceAll(string(b), "\r\n", "\n")
+	}
+	lines := strings.Split(strings.TrimSuffix(corpus, "\n"), "\n")
+
+	// Create a destination image large enough for the widest line, and
+	// count the glyphs drawn per pass over the corpus.
+	var size image.Point
+	for _, ln := range lines {
+		b := font.MeasureText(ln)
+		size.X = max(size.X, b.Max.X)
+		size.Y = max(size.Y, b.Max.Y)
+	}
+	base := font.Descriptor.Common.Base
+	dst := image.NewRGBA(image.Rect(0, 0, size.X, base+size.Y))
+	font.ResetStats()
+	for _, ln := range lines {
+		font.DrawText(dst, image.Pt(0, base), ln)
+	}
+	glyphs := font.Stats().GlyphsDrawn
+
+	fmt.Printf("%-8s %8s %14s %16s\n", "", "runs", "time/run", "glyphs/s")
+	run := func(name string, glyphsPerRun int64, fn func() error) error {
+		n, elapsed, err := benchmark(*duration, fn)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		perRun := elapsed / time.Duration(n)
+		rate := "-"
+		if glyphsPerRun > 0 {
+			rate = fmt.Sprintf("%.0f", float64(glyphsPerRun)*float64(n)/elapsed.Seconds())
+		}
+		fmt.Printf("%-8s %8d %14s %16s\n", name, n, perRun, rate)
+		return nil
+	}
+	if err := run("parse", 0, func() error {
+		_, err := bmfont.ReadDescriptor(bytes.NewReader(data))
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := run("load", 0, func() error {
+		_, err := bmfont.Load(path)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := run("draw", glyphs, func() error {
+		for _, ln := range lines {
+			font.DrawText(dst, image.Pt(0, base), ln)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return run("measure", glyphs, func() error {
+		for _, ln := range lines {
+			font.MeasureText(ln)
+		}
+		return nil
+	})
+}
+
+// benchmark calls fn repeatedly until the total running time is at least
+// the given duration, and returns the number of calls and the running time.
+func benchmark(d time.Duration, fn func() error) (n int, elapsed time.Duration, err error) {
+	start := time.Now()
+	for elapsed < d || n == 0 {
+		if err := fn(); err != nil {
+			return n, elapsed, err
+		}
+		n++
+		elapsed = time.Since(start)
+	}
+	return n, elapsed, nil
+}
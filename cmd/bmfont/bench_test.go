@@ -0,0 +1,41 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchmark(t *testing.T) {
+	calls := 0
+	n, elapsed, err := benchmark(0, func() error {
+		calls++
+		return nil
+	})
+	if err != nil || n != 1 || calls != 1 {
+		t.Errorf("benchmark(0) = %d, %v, %v with %d calls, want exactly one call", n, elapsed, err, calls)
+	}
+
+	errFail := errors.New("fail")
+	if _, _, err := benchmark(time.Second, func() error { return errFail }); err != errFail {
+		t.Errorf("got error %v, want %v", err, errFail)
+	}
+}
+
+func TestBenchCommand(t *testing.T) {
+	out := runCommand(t, benchCmd, "-duration", "1ms", writeTestFont(t, "AB "))
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want a header and 4 benchmarks:\n%s", len(lines), out)
+	}
+	for i, name := range []string{"parse", "load", "draw", "measure"} {
+		if fields := strings.Fields(lines[i+1]); len(fields) != 4 || fields[0] != name {
+			t.Errorf("line %d: got %q, want the %s benchmark", i+2, lines[i+1], name)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+var bundleCmd = &command{
+	name:    "bundle",
+	summary: "pack a font descriptor and its page images into a single archive",
+	run:     runBundle,
+}
+
+var unbundleCmd = &command{
+	name:    "unbundle",
+	summary: "extract the font descriptor and page images from an archive",
+	run:     runUnbundle,
+}
+
+func runBundle(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt")
+	out := fs.String("o", "", "output bundle `file` (default: name of the font with extension .zip)")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+	output := *out
+	if output == "" {
+		output = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + ".zip"
+	}
+	return writeOutput(output, func(w *os.File) error {
+		return bmfont.WriteBundle(w, path)
+	})
+}
+
+func runUnbundle(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "bundle.zip")
+	dir := fs.String("d", ".", "output `directory`")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return bmfont.ExtractBundle(fs.Arg(0), *dir)
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+var captionCmd = &command{
+	name:    "caption",
+	summary: "draw an outlined caption onto an image",
+	run:     runCaption,
+}
+
+var anchorNames = map[string]bmfont.CaptionAnchor{
+	"top":    bmfont.CaptionTop,
+	"bottom": bmfont.CaptionBottom,
+	"center": bmfont.CaptionCenter,
+}
+
+func runCaption(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt image [text]")
+	out := fs.String("o", "", "output image `file`, format by extension: .png, .jpg or .gif (default PNG to standard output)")
+	anchor := fs.String("anchor", "bottom", "`position` of the caption: top, bottom or center")
+	fg := &colorFlag{c: color.White}
+	fs.Var(fg, "color", "text `color` as #rrggbb or #rrggbbaa")
+	outline := &colorFlag{c: color.Black}
+	fs.Var(outline, "outline", "outline `color`")
+	outlineWidth := fs.Int("outline-width", 0, "outline thickness in `pixels` (0 = derived from the text size, -1 = no outline)")
+	margin := fs.Int("margin", 0, "distance of the caption from the image edges in `pixels` (default 5% of the smaller side)")
+	maxScale := fs.Float64("maxscale", 0, "maximum scale `factor` of the text (0 = unlimited)")
+	_ = fs.Parse(args)
+	if fs.NArg() < 2 || fs.NArg() > 3 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	a, ok := anchorNames[*anchor]
+	if !ok {
+		return fmt.Errorf("invalid anchor %q, must be top, bottom or center", *anchor)
+	}
+	encode, err := imageEncoder(*out)
+	if err != nil {
+		return err
+	}
+
+	font, err := bmfont.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	src, err := decodeImage(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	text, err := textArg(fs.Args()[2:])
+	if err != nil {
+		return err
+	}
+
+	b := src.Bounds()
+	img := image.NewRGBA(b)
+	draw.Draw(img, b, src, b.Min, draw.Src)
+	m := *margin
+	if m <= 0 {
+		m = min(b.Dx(), b.Dy()) / 20
+	}
+	region := b.Inset(m)
+	// The caption uses at most a third of the image height unless it is
+	// centered.
+	if third := b.Dy() / 3; a != bmfont.CaptionCenter && region.Dy() > third {
+		if a == bmfont.CaptionTop {
+			region.Max.Y = region.Min.Y + third
+		} else {
+			region.Min.Y = region.Max.Y - third
+		}
+	}
+	font.DrawCaption(img, region, text, &bmfont.CaptionOptions{
+		Anchor:       a,
+		Color:        fg.c,
+		OutlineColor: outline.c,
+		OutlineWidth: *outlineWidth,
+		MaxScale:     *maxScale,
+	})
+	return writeOutput(*out, func(w *os.File) error {
+		return encode(w, img)
+	})
+}
+
+// imageEncoder returns the encoder for the image format indicated by the
+// extension of the file name. The format is PNG if the name is empty or "-".
+func imageEncoder(name string) (func(io.Writer, image.Image) error, error) {
+	if name == "" || name == "-" {
+		return png.Encode, nil
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png":
+		return png.Encode, nil
+	case ".jpg", ".jpeg":
+		return func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, img, &jpeg.Options{Quality: 90})
+		}, nil
+	case ".gif":
+		return func(w io.Writer, img image.Image) error {
+			return gif.Encode(w, img, nil)
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported output image format: %s", name)
+}
+
+func decodeImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
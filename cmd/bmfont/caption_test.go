@@ -0,0 +1,53 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaptionCommand(t *testing.T) {
+	dir := t.TempDir()
+	photo := image.NewRGBA(image.Rect(0, 0, 60, 60))
+	draw.Draw(photo, photo.Bounds(), image.NewUniform(color.RGBA{B: 0xFF, A: 0xFF}), image.Point{}, draw.Src)
+	in := filepath.Join(dir, "photo.png")
+	f, err := os.Create(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, photo); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	out := filepath.Join(dir, "captioned.png")
+	runCommand(t, captionCmd, "-o", out, "-anchor", "top", writeTestFont(t, "AB"), in, "AB")
+	img := decodePNG(t, out)
+	if img.Bounds() != photo.Bounds() {
+		t.Fatalf("got bounds %v, want %v", img.Bounds(), photo.Bounds())
+	}
+	// The caption is in the top third of the image.
+	var top, rest int
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 60; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r == 0xFFFF {
+				if y < 20 {
+					top++
+				} else {
+					rest++
+				}
+			}
+		}
+	}
+	if top == 0 || rest != 0 {
+		t.Errorf("got %d white pixels in the top third and %d below, want the caption at the top", top, rest)
+	}
+}
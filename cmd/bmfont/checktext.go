@@ -0,0 +1,189 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fzipp/bmfont"
+)
+
+var checkTextCmd = &command{
+	name:    "check-text",
+	summary: "check localization strings for missing characters and maximum widths",
+	run:     runCheckText,
+}
+
+func runCheckText(cmd *command, args []string) error {
+	flags := newFlagSet(cmd, "font.fnt file.json|directory...")
+	widthsFile := flags.String("widths", "", "JSON `file` mapping string keys or key patterns like \"menu.*\" to maximum widths in pixels")
+	scale := flags.Float64("scale", 1, "scale `factor` of the text")
+	_ = flags.Parse(args)
+	if flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	desc, err := bmfont.LoadDescriptor(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var widths maxWidths
+	if *widthsFile != "" {
+		if widths, err = loadMaxWidths(*widthsFile); err != nil {
+			return err
+		}
+	}
+	// Measuring only depends on the metrics, so the page sheets are not
+	// loaded.
+	font := &bmfont.BitmapFont{Descriptor: desc}
+	style := &bmfont.Style{Scale: *scale}
+
+	violations := 0
+	for _, p := range flags.Args()[1:] {
+		err := filepath.WalkDir(p, func(file string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(file), ".json") {
+				return err
+			}
+			strs, err := loadStrings(file)
+			if err != nil {
+				return err
+			}
+			for _, key := range sortedStringKeys(strs) {
+				for _, v := range checkString(font, style, strs[key], widths.forKey(key)) {
+					fmt.Printf("%s: %s: %s\n", file, key, v)
+					violations++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if violations > 0 {
+		fmt.Fprintf(os.Stderr, "bmfont: %d violations\n", violations)
+		os.Exit(1)
+	}
+	return nil
+}
+
+// checkString reports the characters of the string that are missing in the
+// font and whether its widest line exceeds the maximum width, unless it is 0.
+func checkString(font *bmfont.BitmapFont, style *bmfont.Style, s string, maxWidth int) []string {
+	var violations []string
+	seen := make(map[rune]bool)
+	for _, r := range s {
+		if seen[r] || unicode.IsControl(r) {
+			continue
+		}
+		seen[r] = true
+		if _, ok := font.Descriptor.Chars[r]; !ok {
+			violations = append(violations, "missing "+runeName(r))
+		}
+	}
+	if maxWidth > 0 {
+		width := 0
+		for _, ln := range font.MeasureLines(s, style) {
+			width = max(width, ln.Width)
+		}
+		if width > maxWidth {
+			violations = append(violations, fmt.Sprintf("width %d exceeds maximum width %d", width, maxWidth))
+		}
+	}
+	return violations
+}
+
+// maxWidths maps string keys or key patterns, as understood by path.Match,
+// to maximum widths in pixels.
+type maxWidths map[string]int
+
+func loadMaxWidths(file string) (maxWidths, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var w maxWidths
+	if err := json.Unmarshal(b, &w); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for pattern := range w {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s: invalid key pattern %q", file, pattern)
+		}
+	}
+	return w, nil
+}
+
+// forKey returns the maximum width for the key: the width of the key itself
+// if present, otherwise the width of the longest matching pattern, or 0 if
+// there is none.
+func (w maxWidths) forKey(key string) int {
+	if width, ok := w[key]; ok {
+		return width
+	}
+	best, width := -1, 0
+	for pattern, wd := range w {
+		if ok, _ := path.Match(pattern, key); ok && len(pattern) > best {
+			best, width = len(pattern), wd
+		}
+	}
+	return width
+}
+
+// loadStrings reads a JSON localization file. Nested objects and arrays are
+// flattened into keys joined by dots, e.g. "menu.file.open". Values other
+// than strings are ignored.
+func loadStrings(file string) (map[string]string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	strs := make(map[string]string)
+	flattenStrings(strs, "", v)
+	return strs, nil
+}
+
+func flattenStrings(strs map[string]string, prefix string, v any) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := v.(type) {
+	case string:
+		strs[prefix] = v
+	case map[string]any:
+		for key, elem := range v {
+			flattenStrings(strs, join(key), elem)
+		}
+	case []any:
+		for i, elem := range v {
+			flattenStrings(strs, join(strconv.Itoa(i)), elem)
+		}
+	}
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+)
+
+func TestCheckString(t *testing.T) {
+	desc, err := bmfont.LoadDescriptor(writeTestFont(t, "AB "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	font := &bmfont.BitmapFont{Descriptor: desc}
+	tests := []struct {
+		s        string
+		maxWidth int
+		want     []string
+	}{
+		{"AB A", 0, nil},
+		{"AB A", 20, nil},
+		{"AB A", 19, []string{"width 20 exceeds maximum width 19"}},
+		{"AB\nBA", 10, nil},
+		{"ACCD\t", 0, []string{"missing U+0043 'C'", "missing U+0044 'D'"}},
+	}
+	for _, tt := range tests {
+		got := checkString(font, &bmfont.Style{Scale: 1}, tt.s, tt.maxWidth)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("checkString(%q, %d) = %q, want %q", tt.s, tt.maxWidth, got, tt.want)
+		}
+	}
+}
+
+func TestMaxWidthsForKey(t *testing.T) {
+	w := maxWidths{"menu.*": 100, "menu.file.*": 80, "menu.title": 200}
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"menu.title", 200},
+		{"menu.help", 100},
+		{"menu.file.open", 80},
+		{"dialog.ok", 0},
+	}
+	for _, tt := range tests {
+		if got := w.forKey(tt.key); got != tt.want {
+			t.Errorf("forKey(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLoadStrings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "en.json")
+	data := `{"menu": {"file": "File", "items": ["Open", "Save"]}, "count": 3, "ok": "OK"}`
+	if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadStrings(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"menu.file":    "File",
+		"menu.items.0": "Open",
+		"menu.items.1": "Save",
+		"ok":           "OK",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCheckTextCommand(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.json"), []byte(`{"title": "AB BA"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	widths := filepath.Join(t.TempDir(), "widths.json")
+	if err := os.WriteFile(widths, []byte(`{"title": 25}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out := runCommand(t, checkTextCmd, "-widths", widths, writeTestFont(t, "AB "), dir); out != "" {
+		t.Errorf("got violations %q, want none", out)
+	}
+}
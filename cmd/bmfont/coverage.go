@@ -0,0 +1,169 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"unicode"
+
+	"github.com/fzipp/bmfont"
+)
+
+var coverageCmd = &command{
+	name:    "coverage",
+	summary: "report characters in text files that are missing in a font",
+	run:     runCoverage,
+}
+
+// A location is a position in a text file.
+type location struct {
+	file      string
+	line, col int
+}
+
+func (l location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.file, l.line, l.col)
+}
+
+// A missingRune is a rune that is not covered by a font, with the locations
+// where it occurs.
+type missingRune struct {
+	r         rune
+	count     int
+	locations []location
+}
+
+func runCoverage(cmd *command, args []string) error {
+	flags := newFlagSet(cmd, "font.fnt file|directory...")
+	maxLocations := flags.Int("locations", 5, "maximum `number` of locations listed per missing character")
+	_ = flags.Parse(args)
+	if flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	desc, err := bmfont.LoadDescriptor(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	missing, err := findMissingRunes(desc, flags.Args()[1:], *maxLocations)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		if err := printJSON(coverageJSON(missing)); err != nil {
+			return err
+		}
+	} else {
+		printCoverage(missing)
+	}
+	if len(missing) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func printCoverage(missing []*missingRune) {
+	for _, m := range missing {
+		fmt.Printf("missing %s: %d occurrences\n", runeName(m.r), m.count)
+		for _, loc := range m.locations {
+			fmt.Printf("\t%s\n", loc)
+		}
+		if more := m.count - len(m.locations); more > 0 {
+			fmt.Printf("\t... and %d more\n", more)
+		}
+	}
+}
+
+type missingRuneJSON struct {
+	Code      string   `json:"code"`
+	Char      string   `json:"char"`
+	Count     int      `json:"count"`
+	Locations []string `json:"locations"`
+}
+
+func coverageJSON(missing []*missingRune) []missingRuneJSON {
+	out := make([]missingRuneJSON, len(missing))
+	for i, m := range missing {
+		out[i] = missingRuneJSON{
+			Code:      fmt.Sprintf("U+%04X", m.r),
+			Char:      string(m.r),
+			Count:     m.count,
+			Locations: make([]string, len(m.locations)),
+		}
+		for j, loc := range m.locations {
+			out[i].Locations[j] = loc.String()
+		}
+	}
+	return out
+}
+
+// findMissingRunes scans the files, and the files in the directories, for
+// runes that are not covered by the font descriptor. Control characters
+// like line breaks and tabs, and byte order marks are ignored. The result
+// is sorted by rune.
+func findMissingRunes(desc *bmfont.Descriptor, paths []string, maxLocations int) ([]*missingRune, error) {
+	found := make(map[rune]*missingRune)
+	scan := func(path string) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return scanMissingRunes(f, path, desc, found, maxLocations)
+	}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			return scan(p)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	missing := make([]*missingRune, 0, len(found))
+	for _, m := range found {
+		missing = append(missing, m)
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].r < missing[j].r })
+	return missing, nil
+}
+
+func scanMissingRunes(r io.Reader, name string, desc *bmfont.Descriptor, found map[rune]*missingRune, maxLocations int) error {
+	br := bufio.NewReader(r)
+	loc := location{file: name, line: 1, col: 1}
+	for {
+		c, _, err := br.ReadRune()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := desc.Chars[c]; !ok && !unicode.IsControl(c) && c != unicode.ReplacementChar && c != '\ufeff' {
+			m := found[c]
+			if m == nil {
+				m = &missingRune{r: c}
+				found[c] = m
+			}
+			m.count++
+			if len(m.locations) < maxLocations {
+				m.locations = append(m.locations, loc)
+			}
+		}
+		loc.col++
+		if c == '\n' {
+			loc.line++
+			loc.col = 1
+		}
+	}
+}
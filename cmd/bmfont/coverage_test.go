@@ -0,0 +1,61 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+)
+
+func TestFindMissingRunes(t *testing.T) {
+	desc, err := bmfont.LoadDescriptor(writeTestFont(t, "AB "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"a.txt":     "\ufeffAB C\r\n\tCé",
+		"sub/b.txt": "BAC",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing, err := findMissingRunes(desc, []string{dir}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 2 || missing[0].r != 'C' || missing[1].r != 'é' {
+		t.Fatalf("got %d missing runes, want C and é", len(missing))
+	}
+	c := missing[0]
+	if c.count != 3 || len(c.locations) != 2 {
+		t.Errorf("got %d occurrences of C with %d locations, want 3 with 2", c.count, len(c.locations))
+	}
+	a := filepath.Join(dir, "a.txt")
+	if want := (location{file: a, line: 1, col: 5}); c.locations[0] != want {
+		t.Errorf("got first location %v, want %v", c.locations[0], want)
+	}
+	if want := (location{file: a, line: 2, col: 2}); c.locations[1] != want {
+		t.Errorf("got second location %v, want %v", c.locations[1], want)
+	}
+}
+
+func TestCoverageJSON(t *testing.T) {
+	missing := []*missingRune{{r: 'é', count: 3, locations: []location{{file: "a.txt", line: 2, col: 4}}}}
+	got := coverageJSON(missing)
+	want := []missingRuneJSON{{Code: "U+00E9", Char: "é", Count: 3, Locations: []string{"a.txt:2:4"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"sort"
+
+	"github.com/fzipp/bmfont"
+)
+
+var diffCmd = &command{
+	name:    "diff",
+	summary: "compare the descriptors of two fonts",
+	run:     runDiff,
+}
+
+func runDiff(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "old.fnt new.fnt")
+	imageOut := fs.String("image", "", "write a side-by-side comparison of the sample text to this PNG `file`")
+	text := fs.String("text", defaultPangram, "sample `text` for the comparison image")
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	oldDesc, err := bmfont.LoadDescriptor(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	newDesc, err := bmfont.LoadDescriptor(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	changes := bmfont.DiffDescriptors(oldDesc, newDesc)
+	if *jsonOutput {
+		out := make([]changeJSON, len(changes))
+		for i, c := range changes {
+			out[i] = changeJSON{Op: c.Kind.String(), Item: c.Item, Field: c.Field, Old: c.Old, New: c.New}
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else {
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+	if *imageOut != "" {
+		oldFont, err := bmfont.Load(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		newFont, err := bmfont.Load(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		img := renderComparison(oldFont, newFont, *text)
+		return writeOutput(*imageOut, func(w *os.File) error {
+			return png.Encode(w, img)
+		})
+	}
+	return nil
+}
+
+// changeJSON is the JSON representation of a bmfont.Change.
+type changeJSON struct {
+	// Op is "+" for added, "-" for removed and "~" for changed items.
+	Op    string `json:"op"`
+	Item  string `json:"item"`
+	Field string `json:"field,omitempty"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+func runeName(r rune) string {
+	if r > ' ' && r != 0x7f {
+		return fmt.Sprintf("U+%04X %q", r, r)
+	}
+	return fmt.Sprintf("U+%04X", r)
+}
+
+func unionKeys[K rune | int, V any](a, b map[K]V) []K {
+	keys := make([]K, 0, len(a))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// renderComparison renders the text with both fonts side by side.
+func renderComparison(a, b *bmfont.BitmapFont, text string) *image.RGBA {
+	const margin = 8
+	style := &bmfont.Style{Padding: bmfont.Padding{Up: margin, Right: margin, Down: margin, Left: margin}}
+	ia := a.RenderToImage(text, style)
+	ib := b.RenderToImage(text, style)
+	ra, rb := ia.Bounds(), ib.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, ra.Dx()+1+rb.Dx(), max(ra.Dy(), rb.Dy())))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 0x20}), image.Point{}, draw.Src)
+	draw.Draw(img, ra, ia, image.Point{}, draw.Over)
+	draw.Draw(img, image.Rect(ra.Dx(), 0, ra.Dx()+1, img.Bounds().Dy()), image.NewUniform(color.Gray{Y: 0x80}), image.Point{}, draw.Src)
+	draw.Draw(img, rb.Add(image.Pt(ra.Dx()+1, 0)), ib, image.Point{}, draw.Over)
+	return img
+}
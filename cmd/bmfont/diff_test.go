@@ -0,0 +1,46 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+)
+
+func TestDiffCommand(t *testing.T) {
+	oldFont, newFont := writeTestFont(t, "AB"), writeTestFont(t, "ABC")
+	img := filepath.Join(t.TempDir(), "diff.png")
+	out := runCommand(t, diffCmd, "-image", img, "-text", "AB", oldFont, newFont)
+	if !strings.Contains(out, "U+0043") || !strings.HasPrefix(out, "+") {
+		t.Errorf("got output %q, want the added char C", out)
+	}
+	if strings.Contains(out, "U+0041") {
+		t.Errorf("got output %q, want no change for the unchanged char A", out)
+	}
+	a, err := bmfont.Load(oldFont)
+	if err != nil {
+		t.Fatal(err)
+	}
+	side := a.RenderToImage("AB", &bmfont.Style{Padding: bmfont.Padding{Up: 8, Right: 8, Down: 8, Left: 8}}).Bounds()
+	if got, want := decodePNG(t, img).Bounds().Dx(), 2*side.Dx()+1; got != want {
+		t.Errorf("got comparison image width %d, want %d", got, want)
+	}
+}
+
+func TestDiffCommandJSON(t *testing.T) {
+	enableJSONOutput(t)
+	out := runCommand(t, diffCmd, writeTestFont(t, "AB"), writeTestFont(t, "ABC"))
+	var got []changeJSON
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+	if len(got) == 0 || got[0].Op != "+" || !strings.Contains(got[0].Item, "U+0043") {
+		t.Errorf("got changes %+v, want the added char C first", got)
+	}
+}
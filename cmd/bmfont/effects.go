@@ -0,0 +1,76 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+var effectsCmd = &command{
+	name:    "effects",
+	summary: "bake outline, shadow and gradient effects into a font",
+	run:     runEffects,
+}
+
+func runEffects(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt")
+	out := fs.String("o", "", "output descriptor `file` (required)")
+	outline := fs.Int("outline", 0, "outline thickness in `pixels`")
+	outlineColor := &colorFlag{c: color.Black}
+	fs.Var(outlineColor, "outline-color", "outline `color`")
+	shadow := fs.String("shadow", "0,0", "drop shadow offset in pixels: `x,y`")
+	shadowColor := &colorFlag{c: color.NRGBA{A: 0x80}}
+	fs.Var(shadowColor, "shadow-color", "shadow `color`")
+	gradientTop := &colorFlag{}
+	fs.Var(gradientTop, "gradient-top", "gradient `color` at the top of a line (default: no gradient)")
+	gradientBottom := &colorFlag{}
+	fs.Var(gradientBottom, "gradient-bottom", "gradient `color` at the bottom of a line (default: no gradient)")
+	maxPageSize := fs.String("maxpagesize", "", "maximum size of the pages: `width`x`height` (default: current page size)")
+	spacing := fs.Int("spacing", 1, "spacing between glyphs in the pages in `pixels`")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || *out == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	sh, err := parseInts(*shadow, 2)
+	if err != nil {
+		return fmt.Errorf("invalid shadow offset: %w", err)
+	}
+	font, err := bmfont.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(*out), filepath.Ext(*out))
+	opts := &bmfont.EffectsOptions{
+		Outline:        *outline,
+		OutlineColor:   outlineColor.c,
+		Shadow:         image.Pt(sh[0], sh[1]),
+		ShadowColor:    shadowColor.c,
+		GradientTop:    gradientTop.c,
+		GradientBottom: gradientBottom.c,
+		Spacing:        *spacing,
+		PageFile: func(id int) string {
+			return fmt.Sprintf("%s_%d.png", name, id)
+		},
+	}
+	if *maxPageSize != "" {
+		opts.MaxPageSize, err = parseSize(*maxPageSize)
+		if err != nil {
+			return err
+		}
+	}
+	baked, err := bmfont.BakeEffects(font, opts)
+	if err != nil {
+		return err
+	}
+	return baked.Save(*out)
+}
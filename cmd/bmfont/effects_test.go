@@ -0,0 +1,29 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+)
+
+func TestEffectsCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "outlined.fnt")
+	runCommand(t, effectsCmd, "-o", out, "-outline", "2", "-maxpagesize", "32x32", writeTestFont(t, "AB"))
+	font, err := bmfont.Load(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := font.Descriptor.Chars['A']
+	if ch.Width != 8 || ch.Height != 10 || ch.XOffset != -2 || ch.YOffset != -2 {
+		t.Errorf("got glyph %dx%d at offset %d,%d, want 8x10 at -2,-2", ch.Width, ch.Height, ch.XOffset, ch.YOffset)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(out), "outlined_0.png")); err != nil {
+		t.Errorf("page sheet not saved: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+// colorFlag is a flag.Value for colors in hexadecimal notation: #rgb,
+// #rrggbb or #rrggbbaa, with or without the leading '#'.
+type colorFlag struct {
+	c color.Color
+}
+
+func (f *colorFlag) String() string {
+	if f.c == nil {
+		return ""
+	}
+	c := color.NRGBAModel.Convert(f.c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func (f *colorFlag) Set(s string) error {
+	c, err := parseColor(s)
+	if err != nil {
+		return err
+	}
+	f.c = c
+	return nil
+}
+
+func parseColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return nil, fmt.Errorf("invalid color %q, expected #rrggbb or #rrggbbaa", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// alignFlag is a flag.Value for a horizontal text alignment:
+// left, center, right or justify.
+type alignFlag struct {
+	a bmfont.Alignment
+}
+
+var alignNames = []string{
+	bmfont.AlignLeft:    "left",
+	bmfont.AlignCenter:  "center",
+	bmfont.AlignRight:   "right",
+	bmfont.AlignJustify: "justify",
+}
+
+func (f *alignFlag) String() string {
+	if int(f.a) < len(alignNames) {
+		return alignNames[f.a]
+	}
+	return ""
+}
+
+func (f *alignFlag) Set(s string) error {
+	for a, name := range alignNames {
+		if name == s {
+			f.a = bmfont.Alignment(a)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid alignment %q, expected %s", s, strings.Join(alignNames, ", "))
+}
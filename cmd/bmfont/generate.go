@@ -0,0 +1,151 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+var generateCmd = &command{
+	name:    "generate",
+	summary: "generate a bitmap font from a TrueType or OpenType font",
+	run:     runGenerate,
+}
+
+func runGenerate(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.ttf")
+	out := fs.String("o", "", "output descriptor `file` (default: name of the input font with extension .fnt)")
+	size := fs.Float64("size", 32, "font size in `pixels`")
+	chars := fs.String("chars", "", "`characters` to include (default: printable ASCII)")
+	ranges := fs.String("ranges", "", "code point `ranges` to include, e.g. 32-126,0x400-0x4ff")
+	padding := fs.String("padding", "0,0,0,0", "padding around each glyph: `up,right,down,left`")
+	spacing := fs.String("spacing", "1,1", "spacing between glyphs in the pages: `horizontal,vertical`")
+	outline := fs.Int("outline", 0, "outline thickness in `pixels`")
+	pageSize := fs.String("pagesize", "512x512", "size of the page images: `width`x`height`")
+	format := fs.String("format", "text", "descriptor `format`: text, binary, xml or json")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	input := fs.Arg(0)
+	ttf, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	descFormat, ok := descriptorFormats[*format]
+	if !ok {
+		return fmt.Errorf("unsupported descriptor format %q", *format)
+	}
+	output := *out
+	if output == "" {
+		output = strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)) + ".fnt"
+	}
+	name := strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+
+	opts := &bmfont.GenerateOptions{
+		Size:    *size,
+		Outline: *outline,
+		PageFile: func(id int) string {
+			return fmt.Sprintf("%s_%d.png", name, id)
+		},
+	}
+	opts.Runes = []rune(*chars)
+	rs, err := parseRanges(*ranges)
+	if err != nil {
+		return err
+	}
+	opts.Runes = append(opts.Runes, rs...)
+	pad, err := parseInts(*padding, 4)
+	if err != nil {
+		return fmt.Errorf("invalid padding: %w", err)
+	}
+	opts.Padding = bmfont.Padding{Up: pad[0], Right: pad[1], Down: pad[2], Left: pad[3]}
+	sp, err := parseInts(*spacing, 2)
+	if err != nil {
+		return fmt.Errorf("invalid spacing: %w", err)
+	}
+	opts.Spacing = bmfont.Spacing{Horizontal: sp[0], Vertical: sp[1]}
+	opts.PageSize, err = parseSize(*pageSize)
+	if err != nil {
+		return err
+	}
+
+	font, err := bmfont.Generate(ttf, opts)
+	if err != nil {
+		return err
+	}
+	return font.SaveFormat(output, descFormat)
+}
+
+var descriptorFormats = map[string]bmfont.DescriptorFormat{
+	"text":   bmfont.TextFormat,
+	"binary": bmfont.BinaryFormat,
+	"xml":    bmfont.XMLFormat,
+	"json":   bmfont.JSONFormat,
+}
+
+// parseRanges parses a comma separated list of code points and code point
+// ranges like "32-126,0x400-0x4ff,0x20ac".
+func parseRanges(s string) ([]rune, error) {
+	var runes []rune
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		lo, err := strconv.ParseInt(first, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid code point range %q", part)
+		}
+		hi := lo
+		if isRange {
+			hi, err = strconv.ParseInt(last, 0, 32)
+			if err != nil || hi < lo {
+				return nil, fmt.Errorf("invalid code point range %q", part)
+			}
+		}
+		for r := lo; r <= hi; r++ {
+			runes = append(runes, rune(r))
+		}
+	}
+	return runes, nil
+}
+
+// parseInts parses a comma separated list of exactly n integers.
+func parseInts(s string, n int) ([]int, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d comma separated values, got %q", n, s)
+	}
+	values := make([]int, n)
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// parseSize parses a size in the form "WIDTHxHEIGHT".
+func parseSize(s string) (image.Point, error) {
+	w, h, ok := strings.Cut(s, "x")
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(h)
+	if !ok || err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return image.Point{}, fmt.Errorf("invalid size %q, expected WIDTHxHEIGHT", s)
+	}
+	return image.Pt(width, height), nil
+}
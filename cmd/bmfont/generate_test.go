@@ -0,0 +1,34 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestGenerateCommand(t *testing.T) {
+	dir := t.TempDir()
+	ttf := filepath.Join(dir, "go.ttf")
+	if err := os.WriteFile(ttf, goregular.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "go.fnt")
+	runCommand(t, generateCmd, "-o", out, "-size", "12", "-ranges", "0x41-0x43", "-chars", "x", ttf)
+	f, err := bmfont.Load(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(f.Descriptor.Chars); got != 4 {
+		t.Errorf("got %d chars, want 4", got)
+	}
+	if got := f.Descriptor.Pages[0].File; got != "go_0.png" {
+		t.Errorf("got page file %q, want %q", got, "go_0.png")
+	}
+}
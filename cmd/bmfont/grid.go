@@ -0,0 +1,98 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+var gridCmd = &command{
+	name:    "grid",
+	summary: "create a bitmap font from a grid or strip sprite sheet image",
+	run:     runGrid,
+}
+
+func runGrid(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "sheet.png")
+	out := fs.String("o", "", "output descriptor `file` (default: name of the sheet with extension .fnt)")
+	cell := fs.String("cell", "", "size of the grid cells: `width`x`height` (required)")
+	chars := fs.String("chars", "", "`characters` of the cells in reading order (default: printable ASCII)")
+	charsFile := fs.String("charsfile", "", "read the characters of the cells from this `file`")
+	margin := fs.String("margin", "0,0", "offset of the first cell: `x,y`")
+	gap := fs.String("gap", "0,0", "space between adjacent cells: `horizontal,vertical`")
+	key := &colorFlag{}
+	fs.Var(key, "key", "background `color` to make transparent (default: use the alpha channel)")
+	trim := fs.Bool("trim", false, "trim the glyph widths to their opaque pixels")
+	letterSpacing := fs.Int("letterspacing", 1, "space added after trimmed glyphs in `pixels`")
+	spaceWidth := fs.Int("spacewidth", 0, "advance of empty cells in `pixels` when trimming (default: half the cell width)")
+	base := fs.Int("base", 0, "distance of the base line from the top of a cell in `pixels` (default: cell height)")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || *cell == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	input := fs.Arg(0)
+	cellSize, err := parseSize(*cell)
+	if err != nil {
+		return err
+	}
+	charset := *chars
+	if *charsFile != "" {
+		b, err := os.ReadFile(*charsFile)
+		if err != nil {
+			return err
+		}
+		charset = strings.NewReplacer("\r", "", "\n", "").Replace(strings.TrimPrefix(string(b), "\ufeff"))
+	}
+	if charset == "" {
+		var sb strings.Builder
+		for r := ' '; r <= '~'; r++ {
+			sb.WriteRune(r)
+		}
+		charset = sb.String()
+	}
+	m, err := parseInts(*margin, 2)
+	if err != nil {
+		return fmt.Errorf("invalid margin: %w", err)
+	}
+	g, err := parseInts(*gap, 2)
+	if err != nil {
+		return fmt.Errorf("invalid gap: %w", err)
+	}
+	sheet, err := decodeImage(input)
+	if err != nil {
+		return err
+	}
+
+	output := *out
+	if output == "" {
+		output = strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)) + ".fnt"
+	}
+	name := strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+	font, err := bmfont.FromGrid(sheet, &bmfont.GridOptions{
+		CellSize:      cellSize,
+		Charset:       charset,
+		Margin:        image.Pt(m[0], m[1]),
+		Gap:           image.Pt(g[0], g[1]),
+		Key:           key.c,
+		Trim:          *trim,
+		LetterSpacing: *letterSpacing,
+		SpaceWidth:    *spaceWidth,
+		Base:          *base,
+		PageFile:      name + "_0.png",
+	})
+	if err != nil {
+		return err
+	}
+	font.Descriptor.Info.Face = name
+	return font.Save(output)
+}
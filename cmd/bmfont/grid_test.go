@@ -0,0 +1,50 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+)
+
+func TestGridCommand(t *testing.T) {
+	dir := t.TempDir()
+	sheet := image.NewNRGBA(image.Rect(0, 0, 16, 8))
+	draw.Draw(sheet, image.Rect(0, 0, 6, 8), image.White, image.Point{}, draw.Src)
+	draw.Draw(sheet, image.Rect(8, 0, 10, 8), image.White, image.Point{}, draw.Src)
+	in := filepath.Join(dir, "retro.png")
+	f, err := os.Create(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, sheet); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	out := filepath.Join(dir, "out.fnt")
+	runCommand(t, gridCmd, "-o", out, "-cell", "8x8", "-chars", "AI", "-trim", in)
+	font, err := bmfont.Load(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := font.Descriptor.Info.Face; got != "out" {
+		t.Errorf("got face %q, want %q", got, "out")
+	}
+	for r, advance := range map[rune]int{'A': 7, 'I': 3} {
+		if got := font.Descriptor.Chars[r].XAdvance; got != advance {
+			t.Errorf("char %q: got advance %d, want %d", r, got, advance)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out_0.png")); err != nil {
+		t.Errorf("page sheet not saved: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+var infoCmd = &command{
+	name:    "info",
+	summary: "print information about a font",
+	run:     runInfo,
+}
+
+type fontInfo struct {
+	Face       string       `json:"face"`
+	Size       int          `json:"size"`
+	Bold       bool         `json:"bold"`
+	Italic     bool         `json:"italic"`
+	LineHeight int          `json:"lineHeight"`
+	Base       int          `json:"base"`
+	ScaleW     int          `json:"scaleW"`
+	ScaleH     int          `json:"scaleH"`
+	Pages      []pageInfo   `json:"pages"`
+	Glyphs     int          `json:"glyphs"`
+	Blocks     []blockCount `json:"blocks"`
+	Kernings   int          `json:"kernings"`
+	FillRate   float64      `json:"fillRate"`
+	Features   []string     `json:"features"`
+	Languages  []string     `json:"languages,omitempty"`
+	Scripts    []string     `json:"scripts,omitempty"`
+	Usage      string       `json:"usage,omitempty"`
+	Notes      string       `json:"notes,omitempty"`
+}
+
+type pageInfo struct {
+	ID     int    `json:"id"`
+	File   string `json:"file"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Error  string `json:"error,omitempty"`
+}
+
+type blockCount struct {
+	Block  string `json:"block"`
+	Glyphs int    `json:"glyphs"`
+}
+
+func runInfo(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt")
+	asJSON := fs.Bool("json", false, "print the information as JSON (like the global -json flag)")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+	desc, err := bmfont.LoadDescriptor(path)
+	if err != nil {
+		return err
+	}
+	info := describeFont(desc, filepath.Dir(path))
+	if *asJSON || *jsonOutput {
+		return printJSON(info)
+	}
+	printInfo(os.Stdout, info)
+	return nil
+}
+
+func describeFont(desc *bmfont.Descriptor, dir string) *fontInfo {
+	info := &fontInfo{
+		Face:       desc.Info.Face,
+		Size:       desc.Info.Size,
+		Bold:       desc.Info.Bold,
+		Italic:     desc.Info.Italic,
+		LineHeight: desc.Common.LineHeight,
+		Base:       desc.Common.Base,
+		ScaleW:     desc.Common.ScaleW,
+		ScaleH:     desc.Common.ScaleH,
+		Glyphs:     len(desc.Chars),
+		Kernings:   len(desc.Kerning),
+		Features:   []string{},
+		Languages:  desc.Info.Languages,
+		Scripts:    desc.Info.Scripts,
+		Usage:      desc.Info.Usage,
+		Notes:      desc.Info.Notes,
+	}
+	if desc.Features != 0 {
+		info.Features = strings.Split(desc.Features.String(), "|")
+	}
+
+	ids := make([]int, 0, len(desc.Pages))
+	for id := range desc.Pages {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	pageArea := 0
+	for _, id := range ids {
+		page := desc.Pages[id]
+		p := pageInfo{ID: id, File: page.File}
+		cfg, err := decodeImageConfig(filepath.Join(dir, page.File))
+		if err != nil {
+			p.Error = err.Error()
+			p.Width, p.Height = desc.Common.ScaleW, desc.Common.ScaleH
+		} else {
+			p.Width, p.Height = cfg.Width, cfg.Height
+		}
+		pageArea += p.Width * p.Height
+		info.Pages = append(info.Pages, p)
+	}
+
+	counts := make(map[string]int)
+	glyphArea := 0
+	for r, ch := range desc.Chars {
+		counts[blockName(r)]++
+		glyphArea += ch.Width * ch.Height
+	}
+	for _, b := range unicodeBlocks {
+		if n := counts[b.name]; n > 0 {
+			info.Blocks = append(info.Blocks, blockCount{Block: b.name, Glyphs: n})
+		}
+	}
+	if n := counts["Other"]; n > 0 {
+		info.Blocks = append(info.Blocks, blockCount{Block: "Other", Glyphs: n})
+	}
+	if pageArea > 0 {
+		info.FillRate = float64(glyphArea) / float64(pageArea)
+	}
+	return info
+}
+
+func decodeImageConfig(path string) (image.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	return cfg, err
+}
+
+func printInfo(w io.Writer, info *fontInfo) {
+	style := ""
+	if info.Bold {
+		style += " bold"
+	}
+	if info.Italic {
+		style += " italic"
+	}
+	fmt.Fprintf(w, "face:        %s%s\n", info.Face, style)
+	fmt.Fprintf(w, "size:        %d\n", info.Size)
+	fmt.Fprintf(w, "line height: %d\n", info.LineHeight)
+	fmt.Fprintf(w, "base:        %d\n", info.Base)
+	fmt.Fprintf(w, "scale:       %dx%d\n", info.ScaleW, info.ScaleH)
+	fmt.Fprintf(w, "pages:       %d\n", len(info.Pages))
+	for _, p := range info.Pages {
+		if p.Error != "" {
+			fmt.Fprintf(w, "  %d: %s (%s)\n", p.ID, p.File, p.Error)
+			continue
+		}
+		fmt.Fprintf(w, "  %d: %s (%dx%d)\n", p.ID, p.File, p.Width, p.Height)
+	}
+	fmt.Fprintf(w, "glyphs:      %d\n", info.Glyphs)
+	for _, b := range info.Blocks {
+		fmt.Fprintf(w, "  %-40s%d\n", b.Block, b.Glyphs)
+	}
+	fmt.Fprintf(w, "kernings:    %d\n", info.Kernings)
+	fmt.Fprintf(w, "fill rate:   %.1f%%\n", info.FillRate*100)
+	if len(info.Features) > 0 {
+		fmt.Fprintf(w, "features:    %s\n", strings.Join(info.Features, ", "))
+	}
+	if len(info.Languages) > 0 {
+		fmt.Fprintf(w, "languages:   %s\n", strings.Join(info.Languages, ", "))
+	}
+	if len(info.Scripts) > 0 {
+		fmt.Fprintf(w, "scripts:     %s\n", strings.Join(info.Scripts, ", "))
+	}
+	if info.Usage != "" {
+		fmt.Fprintf(w, "usage:       %s\n", info.Usage)
+	}
+	if info.Notes != "" {
+		fmt.Fprintf(w, "notes:       %s\n", info.Notes)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInfoJSON(t *testing.T) {
+	font := writeTestFont(t, "AB ä")
+	var info fontInfo
+	if err := json.Unmarshal([]byte(runCommand(t, infoCmd, "-json", font)), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Face != "Test" || info.Glyphs != 4 || info.LineHeight != 8 {
+		t.Errorf("got info %+v", info)
+	}
+	if len(info.Pages) != 1 || info.Pages[0].Width != 64 || info.Pages[0].Height != 8 || info.Pages[0].Error != "" {
+		t.Errorf("got pages %+v, want one 64x8 page", info.Pages)
+	}
+	if len(info.Blocks) != 2 || info.Blocks[0].Glyphs != 3 || info.Blocks[1].Glyphs != 1 {
+		t.Errorf("got blocks %+v, want 3 Basic Latin and 1 Latin-1 glyph", info.Blocks)
+	}
+	// 3 glyphs of 4×6 pixels on a 64×8 page.
+	if want := 72.0 / 512; info.FillRate != want {
+		t.Errorf("got fill rate %g, want %g", info.FillRate, want)
+	}
+}
+
+func TestInfoText(t *testing.T) {
+	out := runCommand(t, infoCmd, writeTestFont(t, "AB"))
+	for _, want := range []string{"face:        Test\n", "glyphs:      2\n", "test_0.png (64x8)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Bmfont is a tool for working with bitmap fonts in AngelCode's BMFont format.
+//
+// Usage:
+//
+//	bmfont      <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	render      render text to a PNG image
+//	info        print information about a font
+//	validate    check a font for inconsistencies
+//	generate    generate a bitmap font from a TrueType or OpenType font
+//	specimen    render a specimen sheet of a font
+//	diff        compare the descriptors of two fonts
+//	optimize    trim, dedupe and repack the glyphs of a font
+//	measure     print the size of a text rendered with a font
+//	wrap        wrap text to a pixel width using the metrics of a font
+//	coverage    report characters in text files that are missing in a font
+//	serve       start a web server for previewing text rendered with a font
+//	bundle      pack a font descriptor and its page images into a single archive
+//	unbundle    extract the font descriptor and page images from an archive
+//	quads       print the glyph quads of a laid out text as JSON
+//	caption     draw an outlined caption onto an image
+//	check-text  check localization strings for missing characters and maximum widths
+//	bench       measure parse, load, draw and measure performance of a font
+//	grid        create a bitmap font from a grid or strip sprite sheet image
+//	effects     bake outline, shadow and gradient effects into a font
+//
+// Use "bmfont <command> -h" for more information about a command.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+var jsonOutput = flag.Bool("json", false, "print the results of the info, validate, diff, coverage and measure commands as JSON")
+
+type command struct {
+	name    string
+	summary string
+	run     func(cmd *command, args []string) error
+}
+
+var commands = []*command{
+	renderCmd,
+	infoCmd,
+	validateCmd,
+	generateCmd,
+	specimenCmd,
+	diffCmd,
+	optimizeCmd,
+	measureCmd,
+	wrapCmd,
+	coverageCmd,
+	serveCmd,
+	bundleCmd,
+	unbundleCmd,
+	quadsCmd,
+	captionCmd,
+	checkTextCmd,
+	benchCmd,
+	gridCmd,
+	effectsCmd,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: bmfont [-json] <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "The global flags are:")
+	fmt.Fprintln(os.Stderr)
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "The commands are:")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-12s%s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Use "bmfont <command> -h" for more information about a command.`)
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bmfont: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+	}
+	name := flag.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(cmd, flag.Args()[1:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "bmfont: unknown command %q\n", name)
+	usage()
+}
+
+// newFlagSet creates the flag set for a command with a usage message
+// showing the given argument synopsis.
+func newFlagSet(cmd *command, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: bmfont %s [flags] %s\n\n", cmd.name, synopsis)
+		fmt.Fprintf(fs.Output(), "The %s command is used to %s.\n\nFlags:\n", cmd.name, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// printJSON writes the value as indented JSON to standard output.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+)
+
+// writeTestFont writes a font with the given characters to a temporary
+// directory and returns the path of its descriptor. The glyphs are opaque
+// white 4×6 pixel boxes with an advance of 5 pixels, except for the space.
+func writeTestFont(t *testing.T, chars string) string {
+	t.Helper()
+	dir := t.TempDir()
+	desc := &bmfont.Descriptor{
+		Info:    bmfont.Info{Face: "Test", Size: 8, Unicode: true},
+		Common:  bmfont.Common{LineHeight: 8, Base: 6, ScaleW: 64, ScaleH: 8},
+		Pages:   map[int]bmfont.Page{0: {ID: 0, File: "test_0.png"}},
+		Chars:   make(map[rune]bmfont.Char),
+		Kerning: make(map[bmfont.CharPair]bmfont.Kerning),
+	}
+	x := 0
+	for _, r := range chars {
+		ch := bmfont.Char{ID: r, X: x, Width: 4, Height: 6, XAdvance: 5, Channel: bmfont.All}
+		if r == ' ' {
+			ch.Width, ch.Height = 0, 0
+		}
+		desc.Chars[r] = ch
+		x += ch.Width
+	}
+	sheet := image.NewNRGBA(image.Rect(0, 0, 64, 8))
+	draw.Draw(sheet, image.Rect(0, 0, x, 6), image.White, image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := desc.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "test.fnt")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := png.Encode(&buf, sheet); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "test_0.png"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runCommand runs the command with the arguments and returns what it
+// printed to standard output.
+func runCommand(t *testing.T, cmd *command, args ...string) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- b
+	}()
+	err = cmd.run(cmd, args)
+	os.Stdout = stdout
+	w.Close()
+	b := <-out
+	if err != nil {
+		t.Fatalf("%s %q: %v", cmd.name, args, err)
+	}
+	return string(b)
+}
+
+// decodePNG decodes the PNG image file at the path.
+func decodePNG(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// enableJSONOutput sets the global -json flag for the duration of the test.
+func enableJSONOutput(t *testing.T) {
+	t.Helper()
+	*jsonOutput = true
+	t.Cleanup(func() { *jsonOutput = false })
+}
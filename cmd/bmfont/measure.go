@@ -0,0 +1,91 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"github.com/fzipp/bmfont"
+)
+
+var measureCmd = &command{
+	name:    "measure",
+	summary: "print the size of a text rendered with a font",
+	run:     runMeasure,
+}
+
+type measureJSON struct {
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	Lines  []lineJSON `json:"lines"`
+	// Fits reports whether the text fits into the size given by the -fit
+	// flag, if any.
+	Fits *bool `json:"fits,omitempty"`
+}
+
+type lineJSON struct {
+	Text  string `json:"text"`
+	Width int    `json:"width"`
+}
+
+func runMeasure(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt [text]")
+	width := fs.Int("width", 0, "wrap lines at this width in `pixels` (0 = no wrapping)")
+	scale := fs.Float64("scale", 1, "scale `factor` of the text")
+	fit := fs.String("fit", "", "exit with status 1 if the text does not fit into this size: `width`x`height`")
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var box image.Point
+	if *fit != "" {
+		var err error
+		if box, err = parseSize(*fit); err != nil {
+			return err
+		}
+	}
+	font, err := bmfont.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	text, err := textArg(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	lines := font.MeasureLines(text, &bmfont.Style{Scale: *scale, MaxWidth: *width})
+	var bounds image.Rectangle
+	for _, ln := range lines {
+		bounds = bounds.Union(ln.Bounds)
+	}
+	fits := *fit == "" || bounds.Dx() <= box.X && bounds.Dy() <= box.Y
+	if *jsonOutput {
+		out := measureJSON{Width: bounds.Dx(), Height: bounds.Dy(), Lines: []lineJSON{}}
+		if *fit != "" {
+			out.Fits = &fits
+		}
+		for _, ln := range lines {
+			out.Lines = append(out.Lines, lineJSON{Text: ln.Text, Width: ln.Width})
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("width:  %d\n", bounds.Dx())
+		fmt.Printf("height: %d\n", bounds.Dy())
+		fmt.Printf("lines:  %d\n", len(lines))
+		for i, ln := range lines {
+			fmt.Printf("%4d: %4d %q\n", i+1, ln.Width, ln.Text)
+		}
+	}
+	if !fits {
+		fmt.Fprintf(os.Stderr, "bmfont: text of size %dx%d does not fit into %dx%d\n",
+			bounds.Dx(), bounds.Dy(), box.X, box.Y)
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMeasureCommand(t *testing.T) {
+	out := runCommand(t, measureCmd, "-width", "12", "-fit", "20x20", writeTestFont(t, "AB "), "AB AB")
+	want := "width:  9\nheight: 14\nlines:  2\n   1:   10 \"AB\"\n   2:   10 \"AB\"\n"
+	if out != want {
+		t.Errorf("got output\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestMeasureCommandJSON(t *testing.T) {
+	enableJSONOutput(t)
+	out := runCommand(t, measureCmd, "-width", "12", "-fit", "20x20", writeTestFont(t, "AB "), "AB AB")
+	var got measureJSON
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+	want := measureJSON{
+		Width:  9,
+		Height: 14,
+		Lines:  []lineJSON{{Text: "AB", Width: 10}, {Text: "AB", Width: 10}},
+	}
+	if got.Fits == nil || !*got.Fits {
+		t.Errorf("got fits %v, want true", got.Fits)
+	}
+	got.Fits = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
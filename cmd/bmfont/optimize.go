@@ -0,0 +1,89 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+var optimizeCmd = &command{
+	name:    "optimize",
+	summary: "trim, dedupe and repack the glyphs of a font",
+	run:     runOptimize,
+}
+
+func runOptimize(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt")
+	out := fs.String("o", "", "output descriptor `file` (required)")
+	trim := fs.Bool("trim", true, "remove transparent borders around the glyphs")
+	dedupe := fs.Bool("dedupe", true, "store identical glyph bitmaps only once")
+	maxPageSize := fs.String("maxpagesize", "", "maximum size of the pages: `width`x`height` (default: current page size)")
+	spacing := fs.Int("spacing", 1, "spacing between glyphs in the pages in `pixels`")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || *out == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	font, err := bmfont.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(*out), filepath.Ext(*out))
+	opts := &bmfont.OptimizeOptions{
+		Trim:    *trim,
+		Dedupe:  *dedupe,
+		Spacing: *spacing,
+		PageFile: func(id int) string {
+			return fmt.Sprintf("%s_%d.png", name, id)
+		},
+	}
+	if *maxPageSize != "" {
+		opts.MaxPageSize, err = parseSize(*maxPageSize)
+		if err != nil {
+			return err
+		}
+	}
+	optimized, err := bmfont.Optimize(font, opts)
+	if err != nil {
+		return err
+	}
+	if err := optimized.Save(*out); err != nil {
+		return err
+	}
+	before, after := textureMemory(font), textureMemory(optimized)
+	fmt.Printf("before: %d pages, %s\n", len(font.PageSheets), formatBytes(before))
+	fmt.Printf("after:  %d pages of %dx%d, %s\n", len(optimized.PageSheets),
+		optimized.Descriptor.Common.ScaleW, optimized.Descriptor.Common.ScaleH, formatBytes(after))
+	if before > 0 {
+		fmt.Printf("saved:  %.1f%%\n", 100*float64(before-after)/float64(before))
+	}
+	return nil
+}
+
+// textureMemory returns the memory in bytes the page sheets of the font
+// occupy as uncompressed RGBA textures.
+func textureMemory(f *bmfont.BitmapFont) int {
+	n := 0
+	for _, sheet := range f.PageSheets {
+		size := sheet.Bounds().Size()
+		n += size.X * size.Y * 4
+	}
+	return n
+}
+
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
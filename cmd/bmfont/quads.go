@@ -0,0 +1,120 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"sort"
+
+	"github.com/fzipp/bmfont"
+)
+
+var quadsCmd = &command{
+	name:    "quads",
+	summary: "print the glyph quads of a laid out text as JSON",
+	run:     runQuads,
+}
+
+type quadsOutput struct {
+	ScaleW     int        `json:"scaleW"`
+	ScaleH     int        `json:"scaleH"`
+	LineHeight int        `json:"lineHeight"`
+	Base       int        `json:"base"`
+	Pages      []pageFile `json:"pages"`
+	Quads      []quad     `json:"quads"`
+}
+
+type pageFile struct {
+	ID   int    `json:"id"`
+	File string `json:"file"`
+}
+
+// A quad is a glyph's destination rectangle, relative to the start
+// position of the text on the base line of the first line, together with
+// its source rectangle within the page sheet, both in pixels and as texture
+// coordinates in the range [0, 1].
+type quad struct {
+	Char   string  `json:"char"`
+	Page   int     `json:"page"`
+	X      int     `json:"x"`
+	Y      int     `json:"y"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	SrcX   int     `json:"srcX"`
+	SrcY   int     `json:"srcY"`
+	SrcW   int     `json:"srcWidth"`
+	SrcH   int     `json:"srcHeight"`
+	U0     float64 `json:"u0"`
+	V0     float64 `json:"v0"`
+	U1     float64 `json:"u1"`
+	V1     float64 `json:"v1"`
+}
+
+func runQuads(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt [text]")
+	width := fs.Int("width", 0, "wrap lines at this width in `pixels` (0 = no wrapping)")
+	scale := fs.Float64("scale", 1, "scale `factor` of the text")
+	var align alignFlag
+	fs.Var(&align, "align", "alignment of wrapped lines: left, center, right or justify")
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	desc, err := bmfont.LoadDescriptor(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	text, err := textArg(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	// The layout only depends on the metrics, so the page sheets are not
+	// loaded.
+	font := &bmfont.BitmapFont{Descriptor: desc}
+	style := &bmfont.Style{Scale: *scale, MaxWidth: *width, Align: align.a}
+
+	common := desc.Common
+	out := quadsOutput{
+		ScaleW:     common.ScaleW,
+		ScaleH:     common.ScaleH,
+		LineHeight: common.LineHeight,
+		Base:       common.Base,
+		Pages:      []pageFile{},
+		Quads:      []quad{},
+	}
+	for id, page := range desc.Pages {
+		out.Pages = append(out.Pages, pageFile{ID: id, File: page.File})
+	}
+	sort.Slice(out.Pages, func(i, j int) bool {
+		return out.Pages[i].ID < out.Pages[j].ID
+	})
+	for _, q := range font.Quads(text, style) {
+		out.Quads = append(out.Quads, quad{
+			Char:   string(q.Rune),
+			Page:   q.Page,
+			X:      q.Dst.Min.X,
+			Y:      q.Dst.Min.Y,
+			Width:  q.Dst.Dx(),
+			Height: q.Dst.Dy(),
+			SrcX:   q.Src.Min.X,
+			SrcY:   q.Src.Min.Y,
+			SrcW:   q.Src.Dx(),
+			SrcH:   q.Src.Dy(),
+			U0:     texCoord(q.Src.Min.X, common.ScaleW),
+			V0:     texCoord(q.Src.Min.Y, common.ScaleH),
+			U1:     texCoord(q.Src.Max.X, common.ScaleW),
+			V1:     texCoord(q.Src.Max.Y, common.ScaleH),
+		})
+	}
+	return printJSON(out)
+}
+
+func texCoord(v, size int) float64 {
+	if size == 0 {
+		return 0
+	}
+	return float64(v) / float64(size)
+}
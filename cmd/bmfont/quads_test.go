@@ -0,0 +1,32 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQuadsCommand(t *testing.T) {
+	var out quadsOutput
+	if err := json.Unmarshal([]byte(runCommand(t, quadsCmd, writeTestFont(t, "AB"), "BA")), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Pages) != 1 || out.Pages[0].File != "test_0.png" {
+		t.Errorf("got pages %+v", out.Pages)
+	}
+	want := []quad{
+		{Char: "B", X: 0, Y: -6, Width: 4, Height: 6, SrcX: 4, SrcW: 4, SrcH: 6, U0: 4.0 / 64, U1: 8.0 / 64, V1: 6.0 / 8},
+		{Char: "A", X: 5, Y: -6, Width: 4, Height: 6, SrcX: 0, SrcW: 4, SrcH: 6, U0: 0, U1: 4.0 / 64, V1: 6.0 / 8},
+	}
+	if len(out.Quads) != len(want) {
+		t.Fatalf("got %d quads, want %d", len(out.Quads), len(want))
+	}
+	for i := range want {
+		if out.Quads[i] != want[i] {
+			t.Errorf("quad %d: got %+v, want %+v", i, out.Quads[i], want[i])
+		}
+	}
+}
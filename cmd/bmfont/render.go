@@ -0,0 +1,92 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fzipp/bmfont"
+)
+
+var renderCmd = &command{
+	name:    "render",
+	summary: "render text to a PNG image",
+	run:     runRender,
+}
+
+func runRender(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt [text]")
+	out := fs.String("o", "", "output PNG `file` (default standard output)")
+	fg := &colorFlag{c: color.White}
+	fs.Var(fg, "color", "text `color` as #rrggbb or #rrggbbaa")
+	bg := &colorFlag{}
+	fs.Var(bg, "bg", "background `color` (default transparent)")
+	width := fs.Int("width", 0, "wrap lines at this width in `pixels` (0 = no wrapping)")
+	align := &alignFlag{}
+	fs.Var(align, "align", "horizontal `alignment` of the lines: left, center, right or justify")
+	scale := fs.Float64("scale", 1, "scale `factor` of the text")
+	padding := fs.Int("padding", 0, "padding around the text in `pixels`")
+	tracking := fs.Float64("tracking", 0, "`pixels` added to the advance of each character")
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	font, err := bmfont.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	text, err := textArg(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	style := &bmfont.Style{
+		Color:      fg.c,
+		Background: bg.c,
+		Padding:    bmfont.Padding{Up: *padding, Right: *padding, Down: *padding, Left: *padding},
+		Scale:      *scale,
+		MaxWidth:   *width,
+		Align:      align.a,
+		Tracking:   *tracking,
+	}
+	return writeOutput(*out, func(w *os.File) error {
+		return font.EncodeText(w, "png", text, style)
+	})
+}
+
+// textArg returns the text given as the only argument, or, if there is no
+// argument, the text read from standard input without a trailing newline.
+func textArg(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	b, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n"), nil
+}
+
+// writeOutput calls write with the named file created for writing, or with
+// standard output if the name is empty or "-".
+func writeOutput(name string, write func(w *os.File) error) (err error) {
+	if name == "" || name == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cErr := f.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}()
+	return write(f)
+}
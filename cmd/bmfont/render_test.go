@@ -0,0 +1,45 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	font := writeTestFont(t, "AB")
+	out := filepath.Join(t.TempDir(), "out.png")
+	runCommand(t, renderCmd, "-o", out, "-color", "#ff0000", "-padding", "2", font, "AB")
+	img := decodePNG(t, out)
+	if got, want := img.Bounds().Size(), image.Pt(2+9+2, 2+6+2); got != want {
+		t.Errorf("got image size %v, want %v", got, want)
+	}
+	if got := color.NRGBAModel.Convert(img.At(2, 2)); got != (color.NRGBA{R: 0xFF, A: 0xFF}) {
+		t.Errorf("got %v at the top left of the text, want red", got)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s    string
+		want color.NRGBA
+	}{
+		{"#fff", color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}},
+		{"102030", color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xFF}},
+		{"#10203040", color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0x40}},
+	}
+	for _, tt := range tests {
+		got, err := parseColor(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("parseColor(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+	if _, err := parseColor("#12"); err == nil {
+		t.Error("parseColor accepted an invalid color")
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fzipp/bmfont"
+)
+
+var serveCmd = &command{
+	name:    "serve",
+	summary: "start a web server for previewing text rendered with a font",
+	run:     runServe,
+}
+
+func runServe(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt")
+	addr := fs.String("addr", "localhost:8080", "listen `address`")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	p := &previewServer{path: fs.Arg(0), reloaded: make(chan struct{})}
+	if err := p.reload(); err != nil {
+		return err
+	}
+	go p.watch(500 * time.Millisecond)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", p.serveIndex)
+	mux.HandleFunc("/render", p.serveRender)
+	mux.HandleFunc("/events", p.serveEvents)
+	log.Printf("serving preview of %s on http://%s/", p.path, *addr)
+	return http.ListenAndServe(*addr, mux)
+}
+
+// A previewServer serves a web page for previewing texts rendered with a
+// font. It reloads the font when its files change on disk and notifies the
+// open pages.
+type previewServer struct {
+	path string
+
+	mu       sync.RWMutex
+	font     *bmfont.BitmapFont
+	modTimes map[string]time.Time
+	err      error
+	// reloaded is closed and replaced after each reload.
+	reloaded chan struct{}
+}
+
+func (p *previewServer) reload() error {
+	font, err := bmfont.Load(p.path)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+	if err == nil {
+		p.font = font
+	}
+	p.modTimes = p.currentModTimes()
+	close(p.reloaded)
+	p.reloaded = make(chan struct{})
+	return err
+}
+
+// currentModTimes returns the modification times of the descriptor file
+// and of the page sheet image files of the current font.
+func (p *previewServer) currentModTimes() map[string]time.Time {
+	files := []string{p.path}
+	if p.font != nil {
+		dir := filepath.Dir(p.path)
+		for _, page := range p.font.Descriptor.Pages {
+			files = append(files, filepath.Join(dir, page.File))
+		}
+	}
+	times := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			times[file] = fi.ModTime()
+		}
+	}
+	return times
+}
+
+func (p *previewServer) watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		if !p.changed() {
+			continue
+		}
+		if err := p.reload(); err != nil {
+			log.Printf("reloading %s: %v", p.path, err)
+			continue
+		}
+		log.Printf("reloaded %s", p.path)
+	}
+}
+
+// changed reports whether the files of the font were modified, added or
+// removed since the last reload.
+func (p *previewServer) changed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	current := p.currentModTimes()
+	for file, t := range current {
+		if !t.Equal(p.modTimes[file]) {
+			return true
+		}
+	}
+	return len(current) != len(p.modTimes)
+}
+
+func (p *previewServer) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, indexHTML, html.EscapeString(filepath.Base(p.path)))
+}
+
+func (p *previewServer) serveRender(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	style := &bmfont.Style{
+		Padding: bmfont.Padding{Up: 8, Right: 8, Down: 8, Left: 8},
+	}
+	var err error
+	if style.Color, err = optionalColor(q.Get("color")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if style.Background, err = optionalColor(q.Get("bg")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	style.Scale, _ = strconv.ParseFloat(q.Get("scale"), 64)
+	style.MaxWidth, _ = strconv.Atoi(q.Get("width"))
+	var align alignFlag
+	if a := q.Get("align"); a != "" {
+		if err := align.Set(a); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	style.Align = align.a
+
+	p.mu.RLock()
+	font, loadErr := p.font, p.err
+	p.mu.RUnlock()
+	if loadErr != nil {
+		http.Error(w, loadErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := font.EncodeText(w, "png", q.Get("text"), style); err != nil {
+		log.Println(err)
+	}
+}
+
+// serveEvents streams a server-sent event each time the font is reloaded.
+func (p *previewServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+	for {
+		p.mu.RLock()
+		reloaded := p.reloaded
+		p.mu.RUnlock()
+		select {
+		case <-reloaded:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func optionalColor(s string) (color.Color, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return parseColor(s)
+}
+
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%[1]s - bmfont preview</title>
+<style>
+body { font-family: sans-serif; margin: 1em; background: #eee; }
+textarea { width: 100%%; height: 6em; }
+#preview { margin-top: 1em; padding: 1em; background: repeating-conic-gradient(#ccc 0 25%%, #fff 0 50%%) 0 0 / 16px 16px; }
+label { margin-right: 1em; }
+</style>
+</head>
+<body>
+<h1>%[1]s</h1>
+<textarea id="text">hello, world</textarea>
+<div>
+<label>Color <input type="color" id="color" value="#ffffff"></label>
+<label>Background <input type="checkbox" id="usebg"> <input type="color" id="bg" value="#000000"></label>
+<label>Scale <input type="number" id="scale" value="1" min="0.25" step="0.25"></label>
+<label>Wrap width <input type="number" id="width" value="0" min="0"></label>
+<label>Align <select id="align"><option>left</option><option>center</option><option>right</option></select></label>
+</div>
+<div id="preview"><img id="img" alt=""></div>
+<script>
+const ids = ["text", "color", "usebg", "bg", "scale", "width", "align"];
+const el = id => document.getElementById(id);
+function update() {
+	const q = new URLSearchParams({
+		text: el("text").value, color: el("color").value,
+		scale: el("scale").value, width: el("width").value,
+		align: el("align").value, t: Date.now(),
+	});
+	if (el("usebg").checked) q.set("bg", el("bg").value);
+	el("img").src = "/render?" + q;
+}
+ids.forEach(id => el(id).addEventListener("input", update));
+new EventSource("/events").onmessage = update;
+update();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,74 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreviewServer(t *testing.T) {
+	p := &previewServer{path: writeTestFont(t, "AB"), reloaded: make(chan struct{})}
+	if err := p.reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	p.serveRender(rec, httptest.NewRequest("GET", "/render?text=AB&scale=2&bg=%23000000", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("got status %d with content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Bounds().Dx(), 8+18+8; got != want {
+		t.Errorf("got image width %d, want %d", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	p.serveRender(rec, httptest.NewRequest("GET", "/render?text=AB&color=nope", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for an invalid color, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	p.serveIndex(rec, httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(rec.Body.String(), "<title>test.fnt - bmfont preview</title>") {
+		t.Error("index page lacks the font name in the title")
+	}
+}
+
+func TestPreviewServerReload(t *testing.T) {
+	path := writeTestFont(t, "AB")
+	p := &previewServer{path: path, reloaded: make(chan struct{})}
+	if err := p.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if p.changed() {
+		t.Error("got a change right after reloading")
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !p.changed() {
+		t.Error("got no change after the descriptor was modified")
+	}
+	reloaded := p.reloaded
+	if err := p.reload(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reloaded:
+	default:
+		t.Error("reload did not notify the open pages")
+	}
+}
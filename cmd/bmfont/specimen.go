@@ -0,0 +1,123 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/fzipp/bmfont"
+)
+
+var specimenCmd = &command{
+	name:    "specimen",
+	summary: "render a specimen sheet of a font",
+	run:     runSpecimen,
+}
+
+const defaultPangram = "The quick brown fox jumps over the lazy dog."
+
+func runSpecimen(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt")
+	out := fs.String("o", "", "output PNG `file` (default standard output)")
+	cols := fs.Int("cols", 16, "number of glyph `columns`")
+	scales := fs.String("scales", "1,2,3", "comma separated `factors` the sample text is rendered at")
+	text := fs.String("text", defaultPangram, "sample `text`")
+	bg := &colorFlag{c: color.Gray{Y: 0x20}}
+	fs.Var(bg, "bg", "background `color`")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 || *cols < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	font, err := bmfont.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var factors []float64
+	for _, s := range strings.Split(*scales, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || f <= 0 {
+			return fmt.Errorf("invalid scale factor %q", s)
+		}
+		factors = append(factors, f)
+	}
+	img := renderSpecimen(font, *cols, factors, *text, bg.c)
+	return writeOutput(*out, func(w *os.File) error {
+		return png.Encode(w, img)
+	})
+}
+
+// renderSpecimen renders all glyphs of the font in a grid with their code
+// points as labels, followed by the sample text at the given scales.
+func renderSpecimen(f *bmfont.BitmapFont, cols int, scales []float64, text string, bg color.Color) *image.RGBA {
+	const margin = 8
+	labelFace := basicfont.Face7x13
+	labelHeight := labelFace.Metrics().Height.Ceil()
+	labelColor := color.Gray{Y: 0x90}
+
+	desc := f.Descriptor
+	runes := make([]rune, 0, len(desc.Chars))
+	cell := image.Pt(7*len("U+0000"), 0)
+	for r, ch := range desc.Chars {
+		runes = append(runes, r)
+		cell.X = max(cell.X, ch.XAdvance, ch.XOffset+ch.Width)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	cell.X += margin
+	cell.Y = max(desc.Common.LineHeight, 1) + labelHeight + margin
+	rows := (len(runes) + cols - 1) / cols
+
+	title := fmt.Sprintf("%s %dpx, %d glyphs", desc.Info.Face, desc.Info.Size, len(runes))
+	samples := make([]*image.RGBA, len(scales))
+	width := max(cols*cell.X, 7*len(title))
+	height := margin + labelHeight + margin + rows*cell.Y
+	for i, s := range scales {
+		samples[i] = f.RenderToImage(text, &bmfont.Style{Scale: s})
+		width = max(width, samples[i].Bounds().Dx())
+		height += labelHeight + samples[i].Bounds().Dy() + margin
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width+2*margin, height+margin))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	label := func(pos image.Point, s string) {
+		d := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(labelColor),
+			Face: labelFace,
+			Dot:  fixed.P(pos.X, pos.Y+labelFace.Metrics().Ascent.Ceil()),
+		}
+		d.DrawString(s)
+	}
+
+	y := margin
+	label(image.Pt(margin, y), title)
+	y += labelHeight + margin
+	for i, r := range runes {
+		pos := image.Pt(margin+(i%cols)*cell.X, y+(i/cols)*cell.Y)
+		label(pos, fmt.Sprintf("U+%04X", r))
+		f.DrawText(img, pos.Add(image.Pt(0, labelHeight+desc.Common.Base)), string(r))
+	}
+	y += rows * cell.Y
+	for i, s := range scales {
+		label(image.Pt(margin, y), fmt.Sprintf("scale %g", s))
+		y += labelHeight
+		sample := samples[i]
+		draw.Draw(img, sample.Bounds().Add(image.Pt(margin, y)), sample, image.Point{}, draw.Over)
+		y += sample.Bounds().Dy() + margin
+	}
+	return img
+}
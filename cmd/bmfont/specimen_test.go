@@ -0,0 +1,50 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+)
+
+func TestRenderSpecimen(t *testing.T) {
+	font, err := bmfont.Load(writeTestFont(t, "ABC"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bg := color.Black
+	grid := renderSpecimen(font, 3, []float64{1}, "ABC", bg)
+	column := renderSpecimen(font, 1, []float64{1}, "ABC", bg)
+	if column.Bounds().Dy() <= grid.Bounds().Dy() {
+		t.Errorf("got height %d for one column and %d for three columns, want more rows to be taller",
+			column.Bounds().Dy(), grid.Bounds().Dy())
+	}
+	scaled := renderSpecimen(font, 3, []float64{1, 2}, "ABC", bg)
+	// The sample at scale 2 adds a label, 12 pixels of text and a margin.
+	if got, want := scaled.Bounds().Dy()-grid.Bounds().Dy(), 13+12+8; got != want {
+		t.Errorf("the sample at scale 2 added %d pixels, want %d", got, want)
+	}
+	white := 0
+	for i := 0; i < len(grid.Pix); i += 4 {
+		if grid.Pix[i] == 0xFF && grid.Pix[i+1] == 0xFF && grid.Pix[i+2] == 0xFF {
+			white++
+		}
+	}
+	// Each glyph is drawn once in the grid and once in the sample.
+	if want := 2 * 3 * 4 * 6; white != want {
+		t.Errorf("got %d white pixels, want %d", white, want)
+	}
+}
+
+func TestSpecimenCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "specimen.png")
+	runCommand(t, specimenCmd, "-o", out, "-scales", "1,2", writeTestFont(t, "AB"))
+	if img := decodePNG(t, out); img.Bounds().Empty() {
+		t.Error("got an empty specimen image")
+	}
+}
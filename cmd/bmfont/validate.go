@@ -0,0 +1,84 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fzipp/bmfont"
+)
+
+var validateCmd = &command{
+	name:    "validate",
+	summary: "check a font for inconsistencies",
+	run:     runValidate,
+}
+
+type validationJSON struct {
+	Valid    bool     `json:"valid"`
+	Problems []string `json:"problems"`
+}
+
+func runValidate(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt")
+	strict := fs.Bool("strict", false, "reject tags and attributes not defined by the BMFont specification")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+	opts := &bmfont.ParseOptions{DisallowUnknown: *strict}
+	desc, err := opts.LoadDescriptor(path)
+	if err != nil {
+		return err
+	}
+	problems := validateFont(desc, filepath.Dir(path))
+	if *jsonOutput {
+		if problems == nil {
+			problems = []string{}
+		}
+		if err := printJSON(validationJSON{Valid: len(problems) == 0, Problems: problems}); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// validateFont checks the descriptor and the page sheet image files in the
+// directory for inconsistencies and returns a description of each problem.
+func validateFont(desc *bmfont.Descriptor, dir string) []string {
+	var problems []string
+	common := desc.Common
+	for _, id := range sortedIDs(desc.Pages) {
+		page := desc.Pages[id]
+		cfg, err := decodeImageConfig(filepath.Join(dir, page.File))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("page %d: %v", id, err))
+			continue
+		}
+		if cfg.Width != common.ScaleW || cfg.Height != common.ScaleH {
+			problems = append(problems, fmt.Sprintf("page %d: size %dx%d differs from the declared size %dx%d",
+				id, cfg.Width, cfg.Height, common.ScaleW, common.ScaleH))
+		}
+	}
+	for _, f := range desc.Validate() {
+		problems = append(problems, f.String())
+	}
+	return problems
+}
+
+func sortedIDs[K rune | int, V any](m map[K]V) []K {
+	return unionKeys(m, nil)
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fzipp/bmfont"
+)
+
+func TestValidateFont(t *testing.T) {
+	path := writeTestFont(t, "AB")
+	desc, err := bmfont.LoadDescriptor(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(path)
+	if problems := validateFont(desc, dir); len(problems) != 0 {
+		t.Errorf("got problems %q for a valid font, want none", problems)
+	}
+
+	desc.Common.ScaleW = 128
+	desc.Pages[1] = bmfont.Page{ID: 1, File: "missing.png"}
+	problems := validateFont(desc, dir)
+	if len(problems) < 2 {
+		t.Fatalf("got problems %q, want the page size mismatch and the missing page", problems)
+	}
+	if !strings.Contains(problems[0], "page 0: size 64x8 differs from the declared size 128x8") {
+		t.Errorf("got problem %q, want the page size mismatch", problems[0])
+	}
+	if !strings.HasPrefix(problems[1], "page 1: ") {
+		t.Errorf("got problem %q, want the missing page", problems[1])
+	}
+}
+
+func TestValidateCommandJSON(t *testing.T) {
+	enableJSONOutput(t)
+	out := runCommand(t, validateCmd, writeTestFont(t, "AB"))
+	var got validationJSON
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+	if !got.Valid || got.Problems == nil || len(got.Problems) != 0 {
+		t.Errorf("got %+v, want a valid font with an empty problem list", got)
+	}
+}
+
+func TestInfoCommandJSON(t *testing.T) {
+	enableJSONOutput(t)
+	out := runCommand(t, infoCmd, writeTestFont(t, "AB"))
+	if !json.Valid([]byte(out)) {
+		t.Errorf("got invalid JSON output %q", out)
+	}
+}
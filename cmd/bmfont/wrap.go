@@ -0,0 +1,44 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fzipp/bmfont"
+)
+
+var wrapCmd = &command{
+	name:    "wrap",
+	summary: "wrap text to a pixel width using the metrics of a font",
+	run:     runWrap,
+}
+
+func runWrap(cmd *command, args []string) error {
+	fs := newFlagSet(cmd, "font.fnt [text]")
+	width := fs.Int("width", 0, "maximum line width in `pixels` (required)")
+	scale := fs.Float64("scale", 1, "scale `factor` of the text")
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 || *width <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	desc, err := bmfont.LoadDescriptor(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	text, err := textArg(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	// Wrapping only depends on the metrics, so the page sheets are not
+	// loaded.
+	font := &bmfont.BitmapFont{Descriptor: desc}
+	for _, ln := range font.MeasureLines(text, &bmfont.Style{Scale: *scale, MaxWidth: *width}) {
+		fmt.Println(ln.Text)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestWrapCommand(t *testing.T) {
+	font := writeTestFont(t, "AB ")
+	if got, want := runCommand(t, wrapCmd, "-width", "15", font, "AB A B\nBA"), "AB\nA B\nBA\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := runCommand(t, wrapCmd, "-width", "12", "-scale", "2", font, "AB"), "A\nB\n"; got != want {
+		t.Errorf("scale 2: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"slices"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// A CollisionShape approximates the opaque area of a glyph of a laid out
+// text, as reported by CollisionShapes, e.g. for physics-based effects.
+type CollisionShape struct {
+	// Rune is the character the glyph represents, like Quad.Rune.
+	Rune rune
+	// Bounds is the bounding box of the opaque pixels of the glyph.
+	Bounds image.Rectangle
+	// Polygon is the convex hull of the opaque pixels of the glyph, with
+	// the vertices in order along its boundary.
+	Polygon []image.Point
+}
+
+// CollisionShapes lays out the text with the given style like Quads and
+// returns the collision shapes of the glyphs in drawing order, as if the
+// text was drawn at position (0, 0). Pixels are opaque if their alpha value
+// is at least half of the maximum. Glyphs without opaque pixels, like
+// spaces, are omitted.
+func (f *BitmapFont) CollisionShapes(text string, style *Style) []CollisionShape {
+	l := f.layout(text, style, nil)
+	hulls := make(map[rune][]image.Point)
+	var shapes []CollisionShape
+	l.eachGlyph(func(_ int, g *glyph) {
+		ch := g.char
+		hull, ok := hulls[ch.ID]
+		if !ok || g.image != nil {
+			hull = opaqueHull(g.sheet(), ch.Bounds())
+			if g.image == nil {
+				hulls[ch.ID] = hull
+			}
+		}
+		if len(hull) == 0 {
+			return
+		}
+		dst := l.glyphRect(fixed.Point26_6{}, *g)
+		shape := CollisionShape{Rune: ch.ID, Polygon: make([]image.Point, len(hull))}
+		for i, p := range hull {
+			q := image.Pt(
+				dst.Min.X+p.X*dst.Dx()/max(1, ch.Width),
+				dst.Min.Y+p.Y*dst.Dy()/max(1, ch.Height),
+			)
+			shape.Polygon[i] = q
+			if i == 0 {
+				shape.Bounds = image.Rectangle{Min: q, Max: q}
+			}
+			shape.Bounds.Min.X = min(shape.Bounds.Min.X, q.X)
+			shape.Bounds.Min.Y = min(shape.Bounds.Min.Y, q.Y)
+			shape.Bounds.Max.X = max(shape.Bounds.Max.X, q.X)
+			shape.Bounds.Max.Y = max(shape.Bounds.Max.Y, q.Y)
+		}
+		shapes = append(shapes, shape)
+	})
+	return shapes
+}
+
+// opaqueHull returns the convex hull of the opaque pixels within the given
+// rectangle of the page sheet, relative to the rectangle's top left corner.
+// The hull encloses the pixels completely, so its vertices are pixel
+// corners.
+func opaqueHull(sheet image.Image, r image.Rectangle) []image.Point {
+	if sheet == nil {
+		return nil
+	}
+	var points []image.Point
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		first, last := -1, -1
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if _, _, _, a := sheet.At(x, y).RGBA(); a >= 0x8000 {
+				if first < 0 {
+					first = x
+				}
+				last = x
+			}
+		}
+		if first < 0 {
+			continue
+		}
+		// Only the outermost pixels of each row can be hull vertices.
+		dy := y - r.Min.Y
+		points = append(points,
+			image.Pt(first-r.Min.X, dy), image.Pt(first-r.Min.X, dy+1),
+			image.Pt(last-r.Min.X+1, dy), image.Pt(last-r.Min.X+1, dy+1),
+		)
+	}
+	return convexHull(points)
+}
+
+// convexHull returns the vertices of the convex hull of the points in order
+// along its boundary, using Andrew's monotone chain algorithm.
+func convexHull(points []image.Point) []image.Point {
+	if len(points) < 3 {
+		return points
+	}
+	slices.SortFunc(points, func(a, b image.Point) int {
+		if a.X != b.X {
+			return a.X - b.X
+		}
+		return a.Y - b.Y
+	})
+	cross := func(o, a, b image.Point) int {
+		return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+	}
+	hull := make([]image.Point, 0, 2*len(points))
+	for _, p := range points {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(points) - 2; i >= 0; i-- {
+		p := points[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+func TestCollisionShapes(t *testing.T) {
+	f := opaqueFont("A B")
+	// The shapes are positioned relative to the base line at y = 0, where
+	// the glyphs of the test font end.
+	shapes := f.CollisionShapes("A B", nil)
+	if len(shapes) != 2 {
+		t.Fatalf("got %d shapes, want 2 without the space", len(shapes))
+	}
+	for i, want := range []struct {
+		r      rune
+		bounds image.Rectangle
+	}{
+		{'A', image.Rect(0, -6, 4, 0)},
+		{'B', image.Rect(10, -6, 14, 0)},
+	} {
+		s := shapes[i]
+		if s.Rune != want.r || s.Bounds != want.bounds || len(s.Polygon) != 4 {
+			t.Errorf("shape %d: got %q with bounds %v and polygon %v, want %q with bounds %v and 4 vertices",
+				i, s.Rune, s.Bounds, s.Polygon, want.r, want.bounds)
+		}
+	}
+
+	scaled := f.CollisionShapes("A", &Style{Scale: 2})
+	if len(scaled) != 1 || scaled[0].Bounds != image.Rect(0, -12, 8, 0) {
+		t.Errorf("got scaled shapes %+v, want bounds (0,-12)-(8,0)", scaled)
+	}
+}
+
+func TestOpaqueHull(t *testing.T) {
+	// A triangle of opaque pixels with its right angle at the top left,
+	// and a semi-transparent pixel that is ignored.
+	sheet := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 3; y++ {
+		draw.Draw(sheet, image.Rect(0, y, 3-y, y+1), image.White, image.Point{}, draw.Src)
+	}
+	sheet.SetNRGBA(3, 3, color.NRGBA{A: 0x40})
+	got := opaqueHull(sheet, sheet.Bounds())
+	want := []image.Point{{0, 0}, {3, 0}, {3, 1}, {1, 3}, {0, 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got hull %v, want %v", got, want)
+	}
+	if got := opaqueHull(image.NewNRGBA(image.Rect(0, 0, 2, 2)), image.Rect(0, 0, 2, 2)); len(got) != 0 {
+		t.Errorf("got hull %v for transparent pixels, want none", got)
+	}
+}
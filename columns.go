@@ -0,0 +1,92 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/draw"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// Columns describes a multi-column layout, e.g. for manuals, credits
+// screens or newspaper-style panels. Text flows from the bottom of a column
+// to the top of the next column on the right.
+type Columns struct {
+	// Count is the number of columns.
+	Count int
+	// Width and Height are the size of each column in pixels.
+	Width, Height int
+	// Gap is the horizontal space between adjacent columns in pixels.
+	Gap int
+}
+
+// Bounds returns the rectangle covered by the columns if their top left
+// corner is at the given position.
+func (c Columns) Bounds(pos image.Point) image.Rectangle {
+	return image.Rectangle{
+		Min: pos,
+		Max: pos.Add(image.Pt(c.Count*c.Width+max(0, c.Count-1)*c.Gap, c.Height)),
+	}
+}
+
+// DrawColumns draws the text with the given style wrapped into the columns,
+// whose top left corner is at the given position. The MaxWidth of the style
+// is replaced by the column width, and the lines are aligned within the
+// columns. It returns the remaining text that did not fit into the columns,
+// which is empty if the text fit completely. If the style transforms the
+// case of the text, the remaining text is part of the transformed text.
+func (f *BitmapFont) DrawColumns(dst draw.Image, pos image.Point, text string, columns Columns, style *Style) (overflow string) {
+	l, overflow := f.layoutColumns(text, columns, style, &f.stats)
+	l = l.drawnGlyphs(style)
+	l.draw(newImageDrawer(dst, l, style), pos, &f.stats)
+	return overflow
+}
+
+// FitColumns determines how much of the text with the given style fits into
+// the columns, without drawing it. It returns the text that fits and the
+// remaining text, like DrawColumns.
+func (f *BitmapFont) FitColumns(text string, columns Columns, style *Style) (fit, overflow string) {
+	text = style.transform(text)
+	_, overflow = f.layoutColumns(text, columns, style, nil)
+	return text[:len(text)-len(overflow)], overflow
+}
+
+// layoutColumns lays out the text in columns. The positions of the layout
+// are relative to the top left corner of the first column.
+func (f *BitmapFont) layoutColumns(text string, columns Columns, style *Style, stats *fontStats) (l *layout, overflow string) {
+	var colStyle Style
+	if style != nil {
+		colStyle = *style
+	}
+	colStyle.MaxWidth = colStyle.logical(columns.Width)
+	text = colStyle.transform(text)
+	colStyle.Case = KeepCase
+	colStyle.MaxLines = 0
+	l = f.layout(text, &colStyle, stats)
+	lineHeight := colStyle.lineAdvance(l.font.Descriptor.Common.LineHeight, l.scale)
+	base := scaled(l.font.Descriptor.Common.Base, l.scale)
+	perColumn := 0
+	if lineHeight > 0 {
+		perColumn = int(fixed.I(columns.Height) / lineHeight)
+	}
+	n := min(len(l.lines), perColumn*max(0, columns.Count))
+	if n < len(l.lines) {
+		overflow = text[l.lines[n].start:]
+		l.lines = l.lines[:n]
+	}
+	for i := range l.lines {
+		col, row := i/perColumn, i%perColumn
+		dx := fixed.I(col * (columns.Width + columns.Gap))
+		y := colStyle.baseline(base + lineHeight*fixed.Int26_6(row))
+		l.lines[i].y = y
+		for j := range l.lines[i].glyphs {
+			g := &l.lines[i].glyphs[j]
+			g.dot.X += dx
+			g.dot.Y = y
+		}
+	}
+	return l, overflow
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestFitColumns(t *testing.T) {
+	f := testFont("A ", 1)
+	columns := Columns{Count: 2, Width: 15, Height: 16, Gap: 2}
+	if got, want := columns.Bounds(image.Pt(1, 1)), image.Rect(1, 1, 33, 17); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+	// Two words fit into a line, two lines into a column.
+	text := strings.TrimSpace(strings.Repeat("A ", 10))
+	fit, overflow := f.FitColumns(text, columns, nil)
+	if fit+overflow != text {
+		t.Errorf("got fit %q and overflow %q, want the parts of %q", fit, overflow, text)
+	}
+	if overflow != "A A" {
+		t.Errorf("got overflow %q, want %q", overflow, "A A")
+	}
+}
+
+func TestDrawColumns(t *testing.T) {
+	f := opaqueFont("A ")
+	columns := Columns{Count: 2, Width: 15, Height: 8, Gap: 2}
+	dst := image.NewRGBA(columns.Bounds(image.Point{}))
+	overflow := f.DrawColumns(dst, image.Point{}, "A A A A A", columns, nil)
+	if overflow != "A" {
+		t.Errorf("got overflow %q, want %q", overflow, "A")
+	}
+	white := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	// The second line is at the top of the second column.
+	if got := dst.RGBAAt(17+1, 1); got != white {
+		t.Errorf("got %v at the start of the second column, want white", got)
+	}
+}
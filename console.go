@@ -0,0 +1,155 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+)
+
+// A Console is a scrollable text console, e.g. for debug output in games.
+// It keeps a limited number of lines in a ring buffer, dropping the oldest
+// lines when it is full, and draws the most recent lines that fit into a
+// destination rectangle. Lines that are wider than the rectangle are wrapped.
+type Console struct {
+	font *BitmapFont
+	// Style is the style of the text. Its Color is the default color of the
+	// lines, its Scale the scale of the text. Its MaxWidth and Align are
+	// ignored.
+	Style *Style
+
+	lines  []consoleLine
+	head   int
+	count  int
+	scroll int
+}
+
+type consoleLine struct {
+	text  string
+	color color.Color
+}
+
+// NewConsole creates a new console that draws text with the given font and
+// keeps at most the given number of lines.
+func NewConsole(font *BitmapFont, capacity int) *Console {
+	return &Console{
+		font:  font,
+		lines: make([]consoleLine, max(1, capacity)),
+	}
+}
+
+// Println appends the text to the console. If the text contains newlines
+// it is split into multiple lines.
+func (c *Console) Println(text string) {
+	c.PrintlnColor(nil, text)
+}
+
+// PrintlnColor appends the text in the given color to the console. If the
+// color is nil, the color of the console's style is used. If the text
+// contains newlines it is split into multiple lines.
+func (c *Console) PrintlnColor(col color.Color, text string) {
+	for _, s := range strings.Split(text, "\n") {
+		c.lines[(c.head+c.count)%len(c.lines)] = consoleLine{text: s, color: col}
+		if c.count < len(c.lines) {
+			c.count++
+		} else {
+			c.head = (c.head + 1) % len(c.lines)
+		}
+	}
+}
+
+// Write appends the bytes as text to the console, so that a console can be
+// used as an io.Writer, e.g. as the output of a log.Logger. A trailing
+// newline is removed. It never returns an error.
+func (c *Console) Write(p []byte) (n int, err error) {
+	c.Println(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// Clear removes all lines from the console and resets the scroll offset.
+func (c *Console) Clear() {
+	c.head, c.count, c.scroll = 0, 0, 0
+	clear(c.lines)
+}
+
+// Len returns the number of lines stored in the console.
+func (c *Console) Len() int {
+	return c.count
+}
+
+// ScrollOffset returns the number of visual lines the console is scrolled
+// back from the most recent line. Wrapped lines count as multiple visual
+// lines.
+func (c *Console) ScrollOffset() int {
+	return c.scroll
+}
+
+// SetScrollOffset sets the number of visual lines the console is scrolled
+// back from the most recent line. Negative offsets are treated as 0, and
+// offsets beyond the oldest line are limited when the console is drawn.
+func (c *Console) SetScrollOffset(offset int) {
+	c.scroll = max(0, offset)
+}
+
+// Scroll changes the scroll offset by the given number of visual lines.
+// Positive values scroll back in the history, negative values towards the
+// most recent line.
+func (c *Console) Scroll(lines int) {
+	c.SetScrollOffset(c.scroll + lines)
+}
+
+// Draw draws the visible lines of the console into the given rectangle of
+// the destination image. The most recent line is at the bottom of the
+// rectangle, unless the console is scrolled back. Glyphs outside of the
+// rectangle are clipped.
+func (c *Console) Draw(dst draw.Image, r image.Rectangle) {
+	style := Style{}
+	if c.Style != nil {
+		style = *c.Style
+	}
+	style.MaxWidth = style.logical(r.Dx())
+	style.Align = AlignLeft
+	caseStyle := style
+	style.Case = KeepCase
+	lineHeight := style.lineAdvance(c.font.Descriptor.Common.LineHeight, style.scale()).Round()
+	if lineHeight <= 0 {
+		return
+	}
+	baseline := int(math.Round(float64(c.font.Descriptor.Common.Base) * style.scale()))
+	visible := r.Dy() / lineHeight
+
+	type row struct {
+		text  string
+		color color.Color
+	}
+	var rows []row // from bottom to top
+	for i := c.count - 1; i >= 0; i-- {
+		ln := c.lines[(c.head+i)%len(c.lines)]
+		text := caseStyle.transform(ln.text)
+		l := c.font.layout(text, &style, nil)
+		for j := len(l.lines) - 1; j >= 0; j-- {
+			rows = append(rows, row{
+				text:  strings.TrimRight(text[l.lines[j].start:l.lines[j].end], " "),
+				color: ln.color,
+			})
+		}
+	}
+	scroll := min(c.scroll, max(0, len(rows)-visible))
+
+	clipped := clipImage(dst, r)
+	rowStyle := style
+	rowStyle.MaxWidth = 0
+	for k := scroll; k < len(rows) && k < scroll+visible+1; k++ {
+		top := r.Max.Y - (k-scroll+1)*lineHeight
+		rowStyle.Color = style.Color
+		if rows[k].color != nil {
+			rowStyle.Color = rows[k].color
+		}
+		c.font.drawText(clipped, image.Pt(r.Min.X, top+baseline), rows[k].text, &rowStyle)
+	}
+}
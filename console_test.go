@@ -0,0 +1,63 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// consoleTexts returns the texts of the lines of the console from the
+// oldest to the most recent one.
+func consoleTexts(c *Console) []string {
+	var texts []string
+	for i := 0; i < c.Len(); i++ {
+		texts = append(texts, c.lines[(c.head+i)%len(c.lines)].text)
+	}
+	return texts
+}
+
+func TestConsoleCapacity(t *testing.T) {
+	c := NewConsole(testFont("abcd", 1), 3)
+	c.Println("a\nb")
+	c.Println("c")
+	fmt.Fprintln(c, "d")
+	if got, want := consoleTexts(c), []string{"b", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("got lines %q, want %q", got, want)
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("got %d lines after Clear, want 0", c.Len())
+	}
+}
+
+func TestConsoleDraw(t *testing.T) {
+	f := opaqueFont("A")
+	c := NewConsole(f, 10)
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	c.PrintlnColor(red, "A")
+	c.Println("A")
+	c.Style = &Style{Color: color.White}
+	dst := image.NewRGBA(image.Rect(0, 0, 20, 16))
+	c.Draw(dst, dst.Bounds())
+	// The most recent line is at the bottom.
+	if got := dst.RGBAAt(1, 9); got != (color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}) {
+		t.Errorf("got %v in the bottom line, want white", got)
+	}
+	if got := dst.RGBAAt(1, 1); got != red {
+		t.Errorf("got %v in the top line, want %v", got, red)
+	}
+
+	// With room for one line, scrolling back shows the older line.
+	c.Scroll(1)
+	dst = image.NewRGBA(image.Rect(0, 0, 20, 8))
+	c.Draw(dst, dst.Bounds())
+	if got := dst.RGBAAt(1, 1); got != red {
+		t.Errorf("got %v after scrolling back, want %v", got, red)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// ddsHeader is the header of a DDS (DirectDraw Surface) image file after
+// the magic number "DDS ".
+type ddsHeader struct {
+	Size              uint32
+	Flags             uint32
+	Height            uint32
+	Width             uint32
+	PitchOrLinearSize uint32
+	Depth             uint32
+	MipMapCount       uint32
+	Reserved1         [11]uint32
+	PixelFormat       ddsPixelFormat
+	Caps              [4]uint32
+	Reserved2         uint32
+}
+
+type ddsPixelFormat struct {
+	Size        uint32
+	Flags       uint32
+	FourCC      [4]byte
+	RGBBitCount uint32
+	RBitMask    uint32
+	GBitMask    uint32
+	BBitMask    uint32
+	ABitMask    uint32
+}
+
+// DDS pixel format flags.
+const (
+	ddsAlphaPixels = 0x1
+	ddsAlpha       = 0x2
+	ddsFourCC      = 0x4
+	ddsRGB         = 0x40
+	ddsLuminance   = 0x20000
+)
+
+func readDDSHeader(r io.Reader) (*ddsHeader, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil || string(magic[:]) != "DDS " {
+		return nil, errors.New("bmfont: not a DDS image")
+	}
+	var h ddsHeader
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return nil, fmt.Errorf("bmfont: invalid DDS header: %w", err)
+	}
+	pf := &h.PixelFormat
+	if pf.Flags&ddsFourCC != 0 {
+		switch string(pf.FourCC[:]) {
+		case "DXT1", "DXT3", "DXT5":
+			return &h, nil
+		}
+		return nil, fmt.Errorf("bmfont: unsupported DDS compression %q", pf.FourCC[:])
+	}
+	switch pf.RGBBitCount {
+	case 8, 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("bmfont: unsupported DDS pixel format with %d bits per pixel", pf.RGBBitCount)
+	}
+	if pf.Flags&(ddsRGB|ddsLuminance|ddsAlpha) == 0 {
+		return nil, errors.New("bmfont: unsupported DDS pixel format")
+	}
+	return &h, nil
+}
+
+// gray reports whether the DDS image is a luminance image without an
+// alpha channel, which is decoded as *image.Gray.
+func (h *ddsHeader) gray() bool {
+	pf := &h.PixelFormat
+	return pf.Flags&ddsFourCC == 0 && pf.Flags&ddsLuminance != 0 && pf.Flags&ddsAlphaPixels == 0
+}
+
+// decodeDDSConfig returns the color model and dimensions of a DDS image
+// without decoding the pixels.
+func decodeDDSConfig(r io.Reader) (image.Config, error) {
+	h, err := readDDSHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	model := color.NRGBAModel
+	if h.gray() {
+		model = color.GrayModel
+	}
+	return image.Config{ColorModel: model, Width: int(h.Width), Height: int(h.Height)}, nil
+}
+
+// decodeDDS decodes the main image of a DDS file, as written by BMFont for
+// GPU pipelines, either uncompressed with arbitrary channel bit masks or
+// compressed with DXT1, DXT3 or DXT5. Luminance images without alpha are
+// returned as *image.Gray and all others as *image.NRGBA. Mipmaps are
+// ignored.
+func decodeDDS(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readDDSHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	w, ht := int(h.Width), int(h.Height)
+	rect := image.Rect(0, 0, w, ht)
+	pf := &h.PixelFormat
+	if pf.Flags&ddsFourCC != 0 {
+		img := image.NewNRGBA(rect)
+		if err := decodeDXT(br, img, string(pf.FourCC[:])); err != nil {
+			return nil, fmt.Errorf("bmfont: invalid DDS image data: %w", err)
+		}
+		return img, nil
+	}
+
+	bpp := int(pf.RGBBitCount) / 8
+	row := make([]byte, w*bpp)
+	var gray *image.Gray
+	var nrgba *image.NRGBA
+	if h.gray() {
+		gray = image.NewGray(rect)
+	} else {
+		nrgba = image.NewNRGBA(rect)
+	}
+	rMask, aMask := pf.RBitMask, pf.ABitMask
+	if pf.Flags&(ddsAlphaPixels|ddsAlpha) == 0 {
+		aMask = 0
+	}
+	for y := 0; y < ht; y++ {
+		if _, err := io.ReadFull(br, row); err != nil {
+			return nil, fmt.Errorf("bmfont: invalid DDS image data: %w", err)
+		}
+		for x := 0; x < w; x++ {
+			var v uint32
+			for i := bpp - 1; i >= 0; i-- {
+				v = v<<8 | uint32(row[x*bpp+i])
+			}
+			a := uint8(0xff)
+			if aMask != 0 {
+				a = maskedChannel(v, aMask)
+			}
+			switch {
+			case gray != nil:
+				gray.Pix[gray.PixOffset(x, y)] = maskedChannel(v, rMask)
+			case pf.Flags&ddsLuminance != 0:
+				l := maskedChannel(v, rMask)
+				nrgba.SetNRGBA(x, y, color.NRGBA{R: l, G: l, B: l, A: a})
+			case pf.Flags&ddsRGB != 0:
+				nrgba.SetNRGBA(x, y, color.NRGBA{
+					R: maskedChannel(v, rMask),
+					G: maskedChannel(v, pf.GBitMask),
+					B: maskedChannel(v, pf.BBitMask),
+					A: a,
+				})
+			default:
+				// alpha only
+				nrgba.SetNRGBA(x, y, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: a})
+			}
+		}
+	}
+	if gray != nil {
+		return gray, nil
+	}
+	return nrgba, nil
+}
+
+// maskedChannel extracts the channel selected by the bit mask from the
+// pixel value and scales it to 8 bits.
+func maskedChannel(v, mask uint32) uint8 {
+	if mask == 0 {
+		return 0
+	}
+	shift := 0
+	for mask>>shift&1 == 0 {
+		shift++
+	}
+	maxValue := uint64(mask >> shift)
+	return uint8((uint64(v&mask>>shift)*0xff + maxValue/2) / maxValue)
+}
+
+// decodeDXT decompresses the 4×4 pixel blocks of DXT1, DXT3 or DXT5
+// compressed image data into img.
+func decodeDXT(r io.Reader, img *image.NRGBA, format string) error {
+	blockSize := 16
+	if format == "DXT1" {
+		blockSize = 8
+	}
+	block := make([]byte, blockSize)
+	var alphas [16]uint8
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	for by := 0; by < h; by += 4 {
+		for bx := 0; bx < w; bx += 4 {
+			if _, err := io.ReadFull(r, block); err != nil {
+				return err
+			}
+			colors := block
+			switch format {
+			case "DXT3":
+				for i := range alphas {
+					alphas[i] = (block[i/2] >> (4 * (i % 2)) & 0xf) * 0x11
+				}
+				colors = block[8:]
+			case "DXT5":
+				dxt5Alphas(block[:8], &alphas)
+				colors = block[8:]
+			}
+			palette := dxtPalette(colors, format == "DXT1")
+			indices := binary.LittleEndian.Uint32(colors[4:])
+			for i := 0; i < 16; i++ {
+				x, y := bx+i%4, by+i/4
+				if x >= w || y >= h {
+					continue
+				}
+				c := palette[indices>>(2*i)&3]
+				if format != "DXT1" {
+					c.A = alphas[i]
+				}
+				img.SetNRGBA(x, y, c)
+			}
+		}
+	}
+	return nil
+}
+
+// dxtPalette returns the four colors of a DXT color block. In DXT1 blocks
+// whose first color is not greater than the second one the fourth color is
+// transparent black.
+func dxtPalette(block []byte, dxt1 bool) [4]color.NRGBA {
+	c0 := binary.LittleEndian.Uint16(block[0:])
+	c1 := binary.LittleEndian.Uint16(block[2:])
+	var p [4]color.NRGBA
+	p[0], p[1] = rgb565(c0), rgb565(c1)
+	mix := func(a, b color.NRGBA, wa, wb, d int) color.NRGBA {
+		return color.NRGBA{
+			R: uint8((wa*int(a.R) + wb*int(b.R)) / d),
+			G: uint8((wa*int(a.G) + wb*int(b.G)) / d),
+			B: uint8((wa*int(a.B) + wb*int(b.B)) / d),
+			A: 0xff,
+		}
+	}
+	if c0 > c1 || !dxt1 {
+		p[2] = mix(p[0], p[1], 2, 1, 3)
+		p[3] = mix(p[0], p[1], 1, 2, 3)
+	} else {
+		p[2] = mix(p[0], p[1], 1, 1, 2)
+	}
+	return p
+}
+
+func rgb565(c uint16) color.NRGBA {
+	r, g, b := c>>11&0x1f, c>>5&0x3f, c&0x1f
+	return color.NRGBA{
+		R: uint8(r<<3 | r>>2),
+		G: uint8(g<<2 | g>>4),
+		B: uint8(b<<3 | b>>2),
+		A: 0xff,
+	}
+}
+
+// dxt5Alphas decodes the interpolated alpha values of a DXT5 alpha block.
+func dxt5Alphas(block []byte, alphas *[16]uint8) {
+	a0, a1 := int(block[0]), int(block[1])
+	var table [8]int
+	table[0], table[1] = a0, a1
+	if a0 > a1 {
+		for i := 1; i < 7; i++ {
+			table[i+1] = ((7-i)*a0 + i*a1) / 7
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			table[i+1] = ((5-i)*a0 + i*a1) / 5
+		}
+		table[6], table[7] = 0, 0xff
+	}
+	var indices uint64
+	for i := 7; i >= 2; i-- {
+		indices = indices<<8 | uint64(block[i])
+	}
+	for i := range alphas {
+		alphas[i] = uint8(table[indices>>(3*i)&7])
+	}
+}
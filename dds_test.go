@@ -0,0 +1,134 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"testing"
+)
+
+// ddsFile returns a 2×2 DDS image file with the given pixel format and
+// pixel data.
+func ddsFile(t *testing.T, pf ddsPixelFormat, data []byte) []byte {
+	t.Helper()
+	buf := bytes.NewBufferString("DDS ")
+	pf.Size = 32
+	h := ddsHeader{Size: 124, Width: 2, Height: 2, PixelFormat: pf}
+	if err := binary.Write(buf, binary.LittleEndian, h); err != nil {
+		t.Fatal(err)
+	}
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestDecodeDDS(t *testing.T) {
+	argb := ddsPixelFormat{
+		Flags: ddsRGB | ddsAlphaPixels, RGBBitCount: 32,
+		RBitMask: 0xff0000, GBitMask: 0xff00, BBitMask: 0xff, ABitMask: 0xff000000,
+	}
+	r5g6b5 := ddsPixelFormat{Flags: ddsRGB, RGBBitCount: 16, RBitMask: 0xf800, GBitMask: 0x7e0, BBitMask: 0x1f}
+	luminance := ddsPixelFormat{Flags: ddsLuminance, RGBBitCount: 8, RBitMask: 0xff}
+	alpha := ddsPixelFormat{Flags: ddsAlpha, RGBBitCount: 8, ABitMask: 0xff}
+	dxt := func(fourCC string) ddsPixelFormat {
+		pf := ddsPixelFormat{Flags: ddsFourCC}
+		copy(pf.FourCC[:], fourCC)
+		return pf
+	}
+	// A DXT color block with red and blue, and the two colors in between,
+	// in the reading order of the 2×2 pixels that are used of the 4×4 block.
+	colorBlock := []byte{0x00, 0xf8, 0x1f, 0x00, 0x04, 0x0e, 0x00, 0x00}
+	red, blue := color.NRGBA{R: 0xff, A: 0xff}, color.NRGBA{B: 0xff, A: 0xff}
+	redBlue, blueRed := color.NRGBA{R: 170, B: 85, A: 0xff}, color.NRGBA{R: 85, B: 170, A: 0xff}
+	withAlpha := func(c color.NRGBA, a uint8) color.NRGBA {
+		c.A = a
+		return c
+	}
+
+	tests := []struct {
+		name string
+		file []byte
+		want [4]color.Color // the pixels in reading order
+	}{
+		{
+			"A8R8G8B8",
+			ddsFile(t, argb, []byte{
+				0, 0, 0xff, 0xff, 0xff, 0, 0, 0x80,
+				0, 0xff, 0, 0xff, 0, 0, 0, 0,
+			}),
+			[4]color.Color{red, withAlpha(blue, 0x80), color.NRGBA{G: 0xff, A: 0xff}, color.NRGBA{}},
+		},
+		{
+			"R5G6B5",
+			ddsFile(t, r5g6b5, []byte{0x00, 0xf8, 0x1f, 0x00, 0xe0, 0x07, 0xff, 0xff}),
+			[4]color.Color{red, blue, color.NRGBA{G: 0xff, A: 0xff}, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}},
+		},
+		{
+			"L8",
+			ddsFile(t, luminance, []byte{0x00, 0x40, 0x80, 0xff}),
+			[4]color.Color{color.Gray{Y: 0x00}, color.Gray{Y: 0x40}, color.Gray{Y: 0x80}, color.Gray{Y: 0xff}},
+		},
+		{
+			"A8",
+			ddsFile(t, alpha, []byte{0x00, 0x40, 0x80, 0xff}),
+			[4]color.Color{
+				color.NRGBA{R: 0xff, G: 0xff, B: 0xff}, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x40},
+				color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x80}, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+			},
+		},
+		{
+			"DXT1",
+			ddsFile(t, dxt("DXT1"), colorBlock),
+			[4]color.Color{red, blue, redBlue, blueRed},
+		},
+		{
+			"DXT3",
+			ddsFile(t, dxt("DXT3"), append([]byte{0xf0, 0, 0x5a, 0, 0, 0, 0, 0}, colorBlock...)),
+			[4]color.Color{withAlpha(red, 0), withAlpha(blue, 0xff), withAlpha(redBlue, 0xaa), withAlpha(blueRed, 0x55)},
+		},
+		{
+			"DXT5",
+			ddsFile(t, dxt("DXT5"), append([]byte{0xff, 0x00, 0x08, 0xa0, 0x03, 0, 0, 0}, colorBlock...)),
+			[4]color.Color{withAlpha(red, 0xff), withAlpha(blue, 0), withAlpha(redBlue, 218), withAlpha(blueRed, 36)},
+		},
+	}
+	for _, tt := range tests {
+		img, err := decodeDDS(bytes.NewReader(tt.file))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for i, want := range tt.want {
+			if got := img.At(i%2, i/2); got != want {
+				t.Errorf("%s: got pixel %d,%d %v, want %v", tt.name, i%2, i/2, got, want)
+			}
+		}
+		config, err := decodeDDSConfig(bytes.NewReader(tt.file))
+		if err != nil || config.Width != 2 || config.Height != 2 || config.ColorModel != img.ColorModel() {
+			t.Errorf("%s: got config %+v, error %v", tt.name, config, err)
+		}
+	}
+}
+
+func TestDecodeDDSErrors(t *testing.T) {
+	bc7 := ddsPixelFormat{Flags: ddsFourCC}
+	copy(bc7.FourCC[:], "DX10")
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{"not DDS", []byte("\x89PNG\r\n\x1a\n")},
+		{"truncated header", []byte("DDS \x7c\x00")},
+		{"unsupported compression", ddsFile(t, bc7, nil)},
+		{"unsupported bit count", ddsFile(t, ddsPixelFormat{Flags: ddsRGB, RGBBitCount: 12}, nil)},
+		{"truncated data", ddsFile(t, ddsPixelFormat{Flags: ddsLuminance, RGBBitCount: 8, RBitMask: 0xff}, []byte{1, 2, 3})},
+	}
+	for _, tt := range tests {
+		if _, err := decodeDDS(bytes.NewReader(tt.file)); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
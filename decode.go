@@ -0,0 +1,55 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// A sheetDecoder decodes a page sheet image format that image.Decode can't
+// detect, because its files don't start with a magic number.
+type sheetDecoder struct {
+	decode       func(io.Reader) (image.Image, error)
+	decodeConfig func(io.Reader) (image.Config, error)
+}
+
+// sheetDecoders are the decoders for page sheet image files by their file
+// name extension in lower case. Files with other extensions are decoded
+// with image.Decode.
+var sheetDecoders = map[string]sheetDecoder{
+	".tga": {decodeTGA, decodeTGAConfig},
+	".dds": {decodeDDS, decodeDDSConfig},
+}
+
+// An ImageDecoder decodes a page sheet image, see Loader.WithImageDecoder.
+type ImageDecoder func(r io.Reader) (image.Image, error)
+
+// decodeSheet decodes a page sheet image file with the given name. The
+// decoders registered by file name extension take precedence over the
+// built-in ones.
+func decodeSheet(filename string, r io.Reader, decoders map[string]ImageDecoder) (image.Image, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if decode, ok := decoders[ext]; ok {
+		return decode(r)
+	}
+	if d, ok := sheetDecoders[ext]; ok {
+		return d.decode(r)
+	}
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// decodeSheetConfig decodes the color model and dimensions of a page sheet
+// image file with the given name.
+func decodeSheetConfig(filename string, r io.Reader) (image.Config, error) {
+	if d, ok := sheetDecoders[strings.ToLower(filepath.Ext(filename))]; ok {
+		return d.decodeConfig(r)
+	}
+	config, _, err := image.DecodeConfig(r)
+	return config, err
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"image"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWithImageDecoder(t *testing.T) {
+	descriptor := strings.Replace(testDescriptor, `file="sheet.png"`, `file="sheet.RAW"`, 1)
+	sheet := image.NewNRGBA(image.Rect(0, 0, 16, 8))
+	var l Loader
+	l.WithImageDecoder("raw", func(r io.Reader) (image.Image, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if string(data) != "raw data" {
+			return nil, errors.New("unexpected data")
+		}
+		return sheet, nil
+	})
+	f, err := l.Read(strings.NewReader(descriptor), func(string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("raw data")), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.PageSheets[0] != image.Image(sheet) {
+		t.Error("page sheet was not decoded by the registered decoder")
+	}
+}
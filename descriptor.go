@@ -9,6 +9,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"unicode/utf8"
+
+	"golang.org/x/image/math/fixed"
 )
 
 // A Descriptor holds metadata for a bitmap font.
@@ -18,6 +21,20 @@ type Descriptor struct {
 	Pages   map[int]Page
 	Chars   map[rune]Char
 	Kerning map[CharPair]Kerning
+	// KerningClasses and ClassKerning define class-based kerning, an
+	// extension of the BMFont format for fonts with large kerning tables:
+	// characters are grouped into kerning classes that share kerning
+	// amounts. Entries of the Kerning map take precedence over the
+	// class-based kerning.
+	KerningClasses map[rune]CharClasses
+	ClassKerning   map[ClassPair]Kerning
+	// Features are the optional attributes and extensions that were
+	// present in the parsed descriptor.
+	Features Features
+
+	// duplicates are the findings about duplicate IDs in the parsed
+	// descriptor, which are reported by Validate.
+	duplicates []Finding
 }
 
 type Info struct {
@@ -33,6 +50,22 @@ type Info struct {
 	Padding  Padding
 	Spacing  Spacing
 	Outline  int
+	// Languages, Scripts, Usage and Notes are localization metadata, an
+	// extension of the BMFont format stored as additional attributes of the
+	// info tag, e.g. for routing fonts to locales in asset pipelines.
+	// Languages are BCP 47 language tags and Scripts are ISO 15924 script
+	// codes of the texts the font is intended for.
+	Languages []string
+	Scripts   []string
+	// Usage is the intended use of the font, e.g. "ui" or "subtitles".
+	Usage string
+	// Notes are notes of the font designer.
+	Notes string
+	// Extra holds the attributes of the info tag that are not known to the
+	// parser, e.g. tool-specific extensions. They are written back by
+	// WriteText, WriteXML and WriteJSON, but not by WriteBinary, which has
+	// no room for them. WriteJSON drops those of the pages.
+	Extra map[string]string
 }
 
 type Padding struct {
@@ -53,10 +86,14 @@ type Common struct {
 	RedChannel   ChannelInfo
 	GreenChannel ChannelInfo
 	BlueChannel  ChannelInfo
+	// Extra holds the unknown attributes of the common tag, like Info.Extra.
+	Extra map[string]string
 }
 
+// Scale returns the size of the page sheet images as declared by ScaleW
+// and ScaleH.
 func (c *Common) Scale() image.Point {
-	return image.Pt(c.ScaleH, c.ScaleH)
+	return image.Pt(c.ScaleW, c.ScaleH)
 }
 
 type ChannelInfo int
@@ -72,6 +109,8 @@ const (
 type Page struct {
 	ID   int
 	File string
+	// Extra holds the unknown attributes of the page tag, like Info.Extra.
+	Extra map[string]string
 }
 
 type Char struct {
@@ -85,6 +124,16 @@ type Char struct {
 	XAdvance int
 	Page     int
 	Channel  Channel
+	// Letter is the character as text, as given by the letter attribute
+	// that tools like Littera and GlyphDesigner emit in addition to the ID,
+	// or "" if there is none. These tools name the space character "space".
+	Letter string
+	// Extra holds the unknown attributes of the char tag, like Info.Extra.
+	Extra map[string]string
+
+	// exact holds the metrics of characters of derived fonts with sub-pixel
+	// precision. They are lost when the descriptor is written.
+	exact exactMetrics
 }
 
 func (c *Char) Pos() image.Point {
@@ -127,6 +176,12 @@ type CharPair struct {
 // kerning map.
 type Kerning struct {
 	Amount int
+	// Extra holds the unknown attributes of the kerning tag, like
+	// Info.Extra.
+	Extra map[string]string
+
+	// exact is the amount with sub-pixel precision, like Char.exact.
+	exact fixed.Int26_6
 }
 
 // LoadDescriptor loads the font descriptor data from a BMFont descriptor file in
@@ -139,13 +194,38 @@ func LoadDescriptor(path string) (d *Descriptor, err error) {
 		return nil, err
 	}
 	defer closeChecked(f, &err)
-	return parseDescriptor(filepath.Base(path), f)
+	return parseDescriptor(filepath.Base(path), f, nil)
 }
 
 // ReadDescriptor parses font descriptor data in BMFont's text format from a
 // reader. It does not load the referenced page sheet images. If you also want
 // to load the page sheet images, use the Load function to get a complete
 // BitmapFont instance.
+//
+// Tags that lack attributes required to make sense of them, e.g. a char tag
+// without a width or a common tag without a line height, are reported as
+// errors with their position.
+//
+// The input may be encoded in UTF-8, with or without a byte order mark, or
+// in UTF-16, and may have Windows line endings.
 func ReadDescriptor(r io.Reader) (d *Descriptor, err error) {
-	return parseDescriptor("bmfont", r)
+	return parseDescriptor("bmfont", r, nil)
+}
+
+// ReadDescriptors parses a sequence of concatenated font descriptors in
+// BMFont's text format from a reader, as produced by pipelines that combine
+// several .fnt files into a single stream. Each info tag after the first one
+// starts a new descriptor.
+func ReadDescriptors(r io.Reader) ([]*Descriptor, error) {
+	return parseDescriptors("bmfont", r, nil)
+}
+
+// letterRune returns the character represented by the letter of a char, and
+// whether it represents exactly one character.
+func letterRune(letter string) (rune, bool) {
+	if letter == "space" {
+		return ' ', true
+	}
+	r, size := utf8.DecodeRuneInString(letter)
+	return r, size > 0 && size == len(letter) && r != utf8.RuneError
 }
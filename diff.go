@@ -0,0 +1,171 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A Change is a difference between two font descriptors, as reported by
+// DiffDescriptors.
+type Change struct {
+	Kind ChangeKind
+	// Item names the changed item, e.g. "common", "page 1",
+	// "char U+0041 'A'" or "kerning U+0041 'A' U+0056 'V'".
+	Item string
+	// Field is the name of the changed field of the item, e.g. "XAdvance",
+	// for changed info, common and char items.
+	Field string
+	// Old and New are the old and the new value of the changed field, the
+	// page file for pages and the amount for kerning pairs. For added items
+	// Old is nil, and for removed items New is nil. They are also nil for
+	// added and removed chars.
+	Old, New any
+}
+
+// String formats the change as a line of a diff, e.g.
+// "~ char U+0041 'A' XAdvance: 12 -> 13 (+1)".
+func (c Change) String() string {
+	name := c.Item
+	if c.Field != "" {
+		name += " " + c.Field
+	}
+	switch c.Kind {
+	case ChangeAdded:
+		if c.New == nil {
+			return "+ " + name
+		}
+		return fmt.Sprintf("+ %s: %v", name, c.New)
+	case ChangeRemoved:
+		if c.Old == nil {
+			return "- " + name
+		}
+		return fmt.Sprintf("- %s: %v", name, c.Old)
+	}
+	s := fmt.Sprintf("~ %s: %v -> %v", name, c.Old, c.New)
+	va, vb := reflect.ValueOf(c.Old), reflect.ValueOf(c.New)
+	if va.CanInt() && vb.CanInt() {
+		s += fmt.Sprintf(" (%+d)", vb.Int()-va.Int())
+	}
+	return s
+}
+
+// ChangeKind is the kind of a Change.
+type ChangeKind int
+
+const (
+	ChangeModified ChangeKind = iota // the item exists in both descriptors
+	ChangeAdded                      // the item only exists in the new descriptor
+	ChangeRemoved                    // the item only exists in the old descriptor
+)
+
+// String returns "~" for modified, "+" for added and "-" for removed items.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "+"
+	case ChangeRemoved:
+		return "-"
+	}
+	return "~"
+}
+
+// DiffDescriptors compares two font descriptors and returns their
+// differences: the changed info and common attributes, the added, removed
+// and changed pages and chars, and the added, removed and changed kerning
+// pairs, in this order. Pages, chars and kerning pairs are sorted by their
+// IDs. It returns nil if the descriptors don't differ.
+func DiffDescriptors(old, new *Descriptor) []Change {
+	var changes []Change
+	changes = append(changes, fieldChanges("info", old.Info, new.Info)...)
+	changes = append(changes, fieldChanges("common", old.Common, new.Common)...)
+
+	for _, id := range unionKeys(old.Pages, new.Pages) {
+		pa, inA := old.Pages[id]
+		pb, inB := new.Pages[id]
+		item := fmt.Sprintf("page %d", id)
+		switch {
+		case !inA:
+			changes = append(changes, Change{Kind: ChangeAdded, Item: item, New: pb.File})
+		case !inB:
+			changes = append(changes, Change{Kind: ChangeRemoved, Item: item, Old: pa.File})
+		case pa.File != pb.File:
+			changes = append(changes, Change{Kind: ChangeModified, Item: item, Old: pa.File, New: pb.File})
+		}
+	}
+
+	for _, r := range unionKeys(old.Chars, new.Chars) {
+		ca, inA := old.Chars[r]
+		cb, inB := new.Chars[r]
+		item := "char " + runeName(r)
+		switch {
+		case !inA:
+			changes = append(changes, Change{Kind: ChangeAdded, Item: item})
+		case !inB:
+			changes = append(changes, Change{Kind: ChangeRemoved, Item: item})
+		default:
+			changes = append(changes, fieldChanges(item, ca, cb)...)
+		}
+	}
+
+	union := &Descriptor{Kerning: make(map[CharPair]Kerning)}
+	for p := range old.Kerning {
+		union.Kerning[p] = Kerning{}
+	}
+	for p := range new.Kerning {
+		union.Kerning[p] = Kerning{}
+	}
+	for _, p := range union.kerningPairs() {
+		ka, inA := old.Kerning[p]
+		kb, inB := new.Kerning[p]
+		item := "kerning " + runeName(p.First) + " " + runeName(p.Second)
+		switch {
+		case !inA:
+			changes = append(changes, Change{Kind: ChangeAdded, Item: item, New: kb.Amount})
+		case !inB:
+			changes = append(changes, Change{Kind: ChangeRemoved, Item: item, Old: ka.Amount})
+		case ka.Amount != kb.Amount:
+			changes = append(changes, Change{Kind: ChangeModified, Item: item, Old: ka.Amount, New: kb.Amount})
+		}
+	}
+	return changes
+}
+
+// fieldChanges returns the changes of the exported fields of two structs of
+// the same type that differ.
+func fieldChanges(item string, a, b any) []Change {
+	var changes []Change
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if !va.Type().Field(i).IsExported() {
+			continue
+		}
+		fa, fb := va.Field(i), vb.Field(i)
+		if reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			continue
+		}
+		changes = append(changes, Change{
+			Kind:  ChangeModified,
+			Item:  item,
+			Field: va.Type().Field(i).Name,
+			Old:   fa.Interface(),
+			New:   fb.Interface(),
+		})
+	}
+	return changes
+}
+
+// unionKeys returns the keys of both maps sorted in ascending order.
+func unionKeys[K rune | int, V any](a, b map[K]V) []K {
+	keys := make(map[K]bool, len(a))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return sortedKeys(keys)
+}
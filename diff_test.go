@@ -0,0 +1,49 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffDescriptors(t *testing.T) {
+	old, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := DiffDescriptors(old, old); changes != nil {
+		t.Errorf("got changes %v for equal descriptors, want none", changes)
+	}
+
+	new, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	new.Common.LineHeight++
+	new.Pages[1] = Page{ID: 1, File: "sheet_1.png"}
+	a := new.Chars['A']
+	a.XAdvance += 2
+	new.Chars['A'] = a
+	delete(new.Chars, 'B')
+	new.Chars['C'] = Char{ID: 'C'}
+	delete(new.Kerning, CharPair{First: 'A', Second: 'B'})
+
+	var got []string
+	for _, c := range DiffDescriptors(old, new) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"~ common LineHeight: 8 -> 9 (+1)",
+		"+ page 1: sheet_1.png",
+		"~ char U+0041 'A' XAdvance: 5 -> 7 (+2)",
+		"- char U+0042 'B'",
+		"+ char U+0043 'C'",
+		"- kerning U+0041 'A' U+0042 'B': -1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got changes\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
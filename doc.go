@@ -6,7 +6,15 @@
 // generator or other tools that generate output in the same format, and
 // draws texts with these fonts on images.
 //
-// The parser for the font descriptor files (.fnt) reads the text format, not the
-// binary format. Format description:
+// The parser for the font descriptor files (.fnt) reads the text format and
+// the JSON format used by web pipelines, not the binary format. Format
+// description:
 // https://www.angelcode.com/products/bmfont/doc/file_format.html
+//
+// Page sheet images in the TGA (.tga) and DDS (.dds) formats, which BMFont
+// exports, are decoded by the package itself. Other formats are decoded with
+// image.Decode. PNG, JPEG and GIF are always supported, because the package
+// links in the image/png, image/jpeg and image/gif packages for encoding
+// images. Decoders for further formats must be registered with the image
+// package or passed to Loader.WithImageDecoder.
 package bmfont
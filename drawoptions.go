@@ -0,0 +1,199 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// A DrawOption configures how DrawTextOpts and MeasureTextOpts lay out and
+// draw a text. The options modify the style of the text and are applied in
+// order, so later options override earlier ones.
+type DrawOption func(*Style)
+
+// WithStyle sets all attributes of the style, as a base for the following
+// options. A nil style resets the attributes to the default.
+func WithStyle(style *Style) DrawOption {
+	return func(s *Style) {
+		if style == nil {
+			*s = Style{}
+			return
+		}
+		*s = *style
+	}
+}
+
+// WithAlign aligns the lines of the text horizontally, within the maximum
+// width if one is set with WithMaxWidth, or else within the width of the
+// widest line.
+func WithAlign(a Alignment) DrawOption {
+	return func(s *Style) { s.Align = a }
+}
+
+// WithJustifyCharacters distributes the remaining space of lines justified
+// with AlignJustify between all characters instead of between words, e.g.
+// for CJK text.
+func WithJustifyCharacters(enabled bool) DrawOption {
+	return func(s *Style) { s.JustifyCharacters = enabled }
+}
+
+// WithMaxWidth wraps lines that are wider than the given width in pixels at
+// word boundaries.
+func WithMaxWidth(width int) DrawOption {
+	return func(s *Style) { s.MaxWidth = width }
+}
+
+// WithTruncation truncates lines that are wider than the given maximum width
+// in pixels and ends them with the given ellipsis, or with the default
+// ellipsis if it is empty, see Style.Truncate.
+func WithTruncation(maxWidth int, ellipsis string) DrawOption {
+	return func(s *Style) {
+		s.MaxWidth, s.Truncate, s.Ellipsis = maxWidth, true, ellipsis
+	}
+}
+
+// WithColor tints the glyphs with the given color, see Style.Color.
+func WithColor(c color.Color) DrawOption {
+	return func(s *Style) { s.Color = c }
+}
+
+// WithScale scales the glyphs and all metrics by the given factor.
+func WithScale(scale float64) DrawOption {
+	return func(s *Style) { s.Scale = scale }
+}
+
+// WithLineSpacing multiplies the line height of the font with the given
+// factor.
+func WithLineSpacing(spacing float64) DrawOption {
+	return func(s *Style) { s.LineSpacing = spacing }
+}
+
+// WithTracking adds the given amount in pixels to the advance of each
+// character, or removes it if it is negative.
+func WithTracking(tracking float64) DrawOption {
+	return func(s *Style) { s.Tracking = tracking }
+}
+
+// WithLineHeight overrides the line height of the font with the given
+// height in pixels.
+func WithLineHeight(height int) DrawOption {
+	return func(s *Style) { s.LineHeight = height }
+}
+
+// WithLeading adds the given amount in pixels to the distance between the
+// base lines of consecutive lines, or removes it if it is negative.
+func WithLeading(leading float64) DrawOption {
+	return func(s *Style) { s.Leading = leading }
+}
+
+// WithTabStops sets the positions of tab stops in pixels from the start of
+// a line, in ascending order.
+func WithTabStops(stops ...int) DrawOption {
+	return func(s *Style) { s.TabStops = stops }
+}
+
+// WithTabWidth sets the distance between tab stops in pixels.
+func WithTabWidth(width int) DrawOption {
+	return func(s *Style) { s.TabWidth = width }
+}
+
+// WithOpacity multiplies the alpha values of the text with the given
+// opacity between 0 (invisible) and 1 (opaque), e.g. for fading text in
+// and out. It sets the Transparency of the style to 1-opacity.
+func WithOpacity(opacity float64) DrawOption {
+	return func(s *Style) { s.Transparency = 1 - opacity }
+}
+
+// WithOutlineColor draws the outline of fonts exported with an outline in
+// the given color, separately from the glyphs, see Style.OutlineColor.
+func WithOutlineColor(c color.Color) DrawOption {
+	return func(s *Style) { s.OutlineColor = c }
+}
+
+// WithClip restricts drawing to the given rectangle of the destination image,
+// see Style.Clip.
+func WithClip(r image.Rectangle) DrawOption {
+	return func(s *Style) { s.Clip = r }
+}
+
+// WithCompositor composites the glyphs with the destination image with the
+// given compositor, e.g. draw.Src, see Style.Compositor.
+func WithCompositor(c draw.Drawer) DrawOption {
+	return func(s *Style) { s.Compositor = c }
+}
+
+// WithVisibleGlyphs draws only the first n glyphs of the text, laid out like
+// the whole text, e.g. for typewriter effects, see Style.Visible.
+func WithVisibleGlyphs(n int) DrawOption {
+	return func(s *Style) { s.Visible = &GlyphRange{End: n} }
+}
+
+// WithGlyphFunc adjusts each glyph with the given function before it is
+// drawn, see Style.GlyphFunc.
+func WithGlyphFunc(fn GlyphFunc) DrawOption {
+	return func(s *Style) { s.GlyphFunc = fn }
+}
+
+// WithHighlight fills a box in the given color behind the text, or behind
+// each line if perLine is true, with the given padding, see Style.Highlight.
+func WithHighlight(c color.Color, pad Padding, perLine bool) DrawOption {
+	return func(s *Style) { s.Highlight = &Highlight{Color: c, Padding: pad, PerLine: perLine} }
+}
+
+// WithShadow draws a drop shadow with the given offset, color and blur
+// radius behind the text, see Shadow.
+func WithShadow(offset image.Point, c color.Color, blur int) DrawOption {
+	return func(s *Style) { s.Shadow = &Shadow{Offset: offset, Color: c, Blur: blur} }
+}
+
+// WithSlant shears the glyphs by the given angle in radians to the right,
+// or to the left if it is negative, for a synthetic oblique.
+func WithSlant(angle float64) DrawOption {
+	return func(s *Style) { s.Slant = angle }
+}
+
+// WithGlyphRounding specifies how the positions of the glyphs are rounded
+// to pixels.
+func WithGlyphRounding(r GlyphRounding) DrawOption {
+	return func(s *Style) { s.GlyphRounding = r }
+}
+
+// WithKerning enables or disables the kerning of character pairs. Kerning
+// is enabled by default.
+func WithKerning(enabled bool) DrawOption {
+	return func(s *Style) { s.DisableKerning = !enabled }
+}
+
+// styleOf returns the style configured by the options.
+func styleOf(opts []DrawOption) *Style {
+	style := &Style{}
+	for _, opt := range opts {
+		opt(style)
+	}
+	return style
+}
+
+// DrawTextOpts draws the given text on the destination image starting at
+// the given position like DrawText, laid out and drawn as configured by the
+// options, e.g.
+//
+//	font.DrawTextOpts(dst, pos, text,
+//		bmfont.WithMaxWidth(200),
+//		bmfont.WithAlign(bmfont.AlignCenter),
+//	)
+//
+// The start position is on the base line of the first line of text. The
+// padding and the background color of the style are ignored.
+func (f *BitmapFont) DrawTextOpts(dst draw.Image, pos image.Point, text string, opts ...DrawOption) {
+	f.drawText(dst, pos, text, styleOf(opts))
+}
+
+// MeasureTextOpts calculates the bounding box for the given text as if it
+// was drawn at position (0, 0) with DrawTextOpts and the same options.
+func (f *BitmapFont) MeasureTextOpts(text string, opts ...DrawOption) image.Rectangle {
+	return f.measureText(text, styleOf(opts))
+}
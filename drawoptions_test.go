@@ -0,0 +1,55 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestStyleOf(t *testing.T) {
+	tests := []struct {
+		opts []DrawOption
+		want *Style
+	}{
+		{nil, &Style{}},
+		{
+			[]DrawOption{WithScale(2), WithMaxWidth(100), WithKerning(false), WithOpacity(0.25)},
+			&Style{Scale: 2, MaxWidth: 100, DisableKerning: true, Transparency: 0.75},
+		},
+		{
+			// Later options override earlier ones.
+			[]DrawOption{WithScale(2), WithStyle(&Style{Tracking: 1}), WithAlign(AlignRight), WithTruncation(50, "~")},
+			&Style{Tracking: 1, Align: AlignRight, MaxWidth: 50, Truncate: true, Ellipsis: "~"},
+		},
+		{[]DrawOption{WithScale(2), WithStyle(nil)}, &Style{}},
+	}
+	for _, tt := range tests {
+		if got := styleOf(tt.opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got style %+v, want %+v", got, tt.want)
+		}
+	}
+}
+
+func TestDrawTextOpts(t *testing.T) {
+	f := opaqueFont("AB")
+	red := color.RGBA{R: 0xff, A: 0xff}
+	dst := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	f.DrawTextOpts(dst, image.Pt(1, 8), "AB", WithColor(red))
+	if got := dst.RGBAAt(1, 2); got != red {
+		t.Errorf("got color %v at the top left of A, want %v", got, red)
+	}
+	if got := dst.RGBAAt(5, 2); got != (color.RGBA{}) {
+		t.Errorf("got color %v between A and B, want transparent", got)
+	}
+	if got, want := f.MeasureTextOpts("AB", WithScale(2)), image.Rect(0, -12, 18, 0); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+	if got, want := f.MeasureTextOpts("AB"), f.MeasureText("AB"); got != want {
+		t.Errorf("without options: got bounds %v, want %v", got, want)
+	}
+}
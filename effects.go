@@ -0,0 +1,210 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"maps"
+)
+
+// EffectsOptions configure the effects baked into the glyphs by
+// BakeEffects. A nil *EffectsOptions is valid and bakes no effects, which
+// only repacks the glyphs.
+type EffectsOptions struct {
+	// Outline is the thickness of the outline drawn around the glyphs in
+	// pixels. If it is 0 the glyphs get no outline.
+	Outline int
+	// OutlineColor is the color of the outline. The default is black.
+	OutlineColor color.Color
+	// Shadow is the offset of a drop shadow in pixels. If it is zero the
+	// glyphs get no shadow. The shadow includes the outline.
+	Shadow image.Point
+	// ShadowColor is the color of the shadow. The default is
+	// semi-transparent black.
+	ShadowColor color.Color
+	// GradientTop and GradientBottom are the colors of a vertical gradient
+	// the glyphs are multiplied with, from the top to the bottom of a line.
+	// If both are nil the glyphs get no gradient. If only one of them is
+	// set the other one is white.
+	GradientTop, GradientBottom color.Color
+	// MaxPageSize is the maximum size of the new page sheets. If it is zero
+	// the page size of the font is used as the maximum.
+	MaxPageSize image.Point
+	// Spacing is the space in pixels between the glyphs in the new page
+	// sheets. If it is 0 a spacing of 1 pixel is used.
+	Spacing int
+	// PageFile returns the file name for the page with the given ID. If it
+	// is nil the pages are named "page_0.png", "page_1.png" and so on.
+	PageFile func(id int) string
+}
+
+// BakeEffects creates a copy of the font with an outline, a drop shadow
+// and a color gradient drawn into the glyph bitmaps, so that they need not
+// be applied while drawing text. The glyphs are enlarged as needed and
+// repacked into new page sheets, and their offsets are adjusted so that the
+// glyphs stay in place. The advances are not changed. Packed fonts, which
+// store glyphs in individual color channels, are not supported.
+func BakeEffects(f *BitmapFont, opts *EffectsOptions) (*BitmapFont, error) {
+	if opts == nil {
+		opts = &EffectsOptions{}
+	}
+	src := f.Descriptor
+	if src.Common.Packed {
+		return nil, errors.New("bmfont: can't bake effects into packed fonts")
+	}
+	if opts.Outline < 0 {
+		return nil, errors.New("bmfont: negative outline thickness")
+	}
+	maxSize := opts.MaxPageSize
+	if maxSize == (image.Point{}) {
+		maxSize = image.Pt(src.Common.ScaleW, src.Common.ScaleH)
+	}
+	spacing := opts.Spacing
+	if spacing <= 0 {
+		spacing = 1
+	}
+	pageFile := opts.PageFile
+	if pageFile == nil {
+		pageFile = func(id int) string {
+			return fmt.Sprintf("page_%d.png", id)
+		}
+	}
+
+	desc := &Descriptor{
+		Info:           src.Info,
+		Common:         src.Common,
+		Pages:          make(map[int]Page),
+		Chars:          make(map[rune]Char, len(src.Chars)),
+		Kerning:        make(map[CharPair]Kerning, len(src.Kerning)),
+		Features:       src.Features,
+		KerningClasses: maps.Clone(src.KerningClasses),
+		ClassKerning:   maps.Clone(src.ClassKerning),
+	}
+	for pair, k := range src.Kerning {
+		desc.Kerning[pair] = k
+	}
+	desc.Info.Outline += opts.Outline
+	if opts.Outline > 0 {
+		desc.Common.AlphaChannel = GlyphAndOutline
+	}
+
+	runes := sortedKeys(src.Chars)
+	bitmaps := make([]*image.RGBA, len(runes))
+	for i, r := range runes {
+		ch := src.Chars[r]
+		sheet, err := f.Page(ch.Page)
+		if err != nil {
+			return nil, err
+		}
+		bitmap, offset := bakeGlyph(sheet, ch, src.Common.LineHeight, opts)
+		ch.XOffset += offset.X
+		ch.YOffset += offset.Y
+		ch.Width, ch.Height = bitmap.Rect.Dx(), bitmap.Rect.Dy()
+		ch.Channel = All
+		desc.Chars[r] = ch
+		bitmaps[i] = bitmap
+	}
+
+	sizes := make([]image.Point, len(bitmaps))
+	for i, b := range bitmaps {
+		sizes[i] = b.Rect.Size()
+	}
+	pageSize, placed, used, err := packSmallest(sizes, maxSize, spacing)
+	if err != nil {
+		return nil, err
+	}
+	out := &BitmapFont{
+		Descriptor: desc,
+		PageSheets: make(map[int]image.Image),
+	}
+	desc.Common.ScaleW, desc.Common.ScaleH = pageSize.X, pageSize.Y
+	for id := range used {
+		desc.Pages[id] = Page{ID: id, File: pageFile(id)}
+		out.PageSheets[id] = image.NewRGBA(image.Rectangle{Max: pageSize})
+	}
+	for i, p := range placed {
+		sheet := out.PageSheets[p.page].(*image.RGBA)
+		draw.Draw(sheet, image.Rectangle{Min: p.pos, Max: p.pos.Add(sizes[i])}, bitmaps[i], image.Point{}, draw.Src)
+		ch := desc.Chars[runes[i]]
+		ch.X, ch.Y, ch.Page = p.pos.X, p.pos.Y, p.page
+		desc.Chars[runes[i]] = ch
+	}
+	return out, nil
+}
+
+// bakeGlyph draws the effects for the character's glyph into a new image.
+// It returns the image and the offset of its top left corner relative to
+// the top left corner of the original glyph bitmap.
+func bakeGlyph(sheet image.Image, ch Char, lineHeight int, opts *EffectsOptions) (*image.RGBA, image.Point) {
+	if ch.Width <= 0 || ch.Height <= 0 {
+		return image.NewRGBA(image.Rectangle{}), image.Point{}
+	}
+	o, s := opts.Outline, opts.Shadow
+	margin := image.Pt(o+max(0, -s.X), o+max(0, -s.Y))
+	img := image.NewRGBA(image.Rect(0, 0,
+		margin.X+ch.Width+o+max(0, s.X),
+		margin.Y+ch.Height+o+max(0, s.Y),
+	))
+	glyphRect := image.Rectangle{Min: margin, Max: margin.Add(ch.Size())}
+	fill := image.NewRGBA(glyphRect)
+	draw.Draw(fill, glyphRect, sheet, ch.Pos(), draw.Src)
+	if opts.GradientTop != nil || opts.GradientBottom != nil {
+		applyGradient(fill, ch.YOffset-margin.Y, lineHeight, opts.GradientTop, opts.GradientBottom)
+	}
+
+	mask := image.NewAlpha(img.Bounds())
+	draw.Draw(mask, glyphRect, fill, glyphRect.Min, draw.Src)
+	if o > 0 {
+		mask = dilate(mask, o)
+	}
+	if s != (image.Point{}) {
+		shadow := opts.ShadowColor
+		if shadow == nil {
+			shadow = color.NRGBA{A: 0x80}
+		}
+		draw.DrawMask(img, img.Bounds().Add(s), image.NewUniform(shadow), image.Point{}, mask, image.Point{}, draw.Over)
+	}
+	if o > 0 {
+		outline := opts.OutlineColor
+		if outline == nil {
+			outline = color.Black
+		}
+		draw.DrawMask(img, img.Bounds(), image.NewUniform(outline), image.Point{}, mask, image.Point{}, draw.Over)
+	}
+	draw.Draw(img, glyphRect, fill, glyphRect.Min, draw.Over)
+	return img, margin.Mul(-1)
+}
+
+// applyGradient multiplies the pixels of the image with a vertical
+// gradient from the top to the bottom color over the height of a line.
+// The rows of the image start at the given y offset within the line.
+func applyGradient(img *image.RGBA, yOffset, lineHeight int, top, bottom color.Color) {
+	if top == nil {
+		top = color.White
+	}
+	if bottom == nil {
+		bottom = color.White
+	}
+	t0, t1 := color.RGBAModel.Convert(top).(color.RGBA), color.RGBAModel.Convert(bottom).(color.RGBA)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		t := 0.0
+		if lineHeight > 1 {
+			t = min(1, max(0, float64(yOffset+y)/float64(lineHeight-1)))
+		}
+		lerp := func(a, b uint8) uint32 {
+			return uint32(float64(a) + (float64(b)-float64(a))*t + 0.5)
+		}
+		g := [4]uint32{lerp(t0.R, t1.R), lerp(t0.G, t1.G), lerp(t0.B, t1.B), lerp(t0.A, t1.A)}
+		row := img.Pix[img.PixOffset(b.Min.X, y):img.PixOffset(b.Max.X, y)]
+		for i := 0; i < len(row); i++ {
+			row[i] = uint8(uint32(row[i]) * g[i%4] / 0xff)
+		}
+	}
+}
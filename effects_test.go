@@ -0,0 +1,77 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBakeEffects(t *testing.T) {
+	f := opaqueFont("AB")
+	baked, err := BakeEffects(f, &EffectsOptions{Outline: 1, Shadow: image.Pt(2, 1), MaxPageSize: image.Pt(32, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := baked.Descriptor
+	if d.Info.Outline != 1 || d.Common.AlphaChannel != GlyphAndOutline {
+		t.Errorf("got outline %d and alpha channel %v, want 1 and %v", d.Info.Outline, d.Common.AlphaChannel, GlyphAndOutline)
+	}
+	ch := d.Chars['A']
+	if ch.Width != 8 || ch.Height != 9 || ch.XOffset != -1 || ch.YOffset != -1 || ch.XAdvance != 5 {
+		t.Fatalf("got glyph %dx%d at offset %d,%d with advance %d, want 8x9 at -1,-1 with advance 5",
+			ch.Width, ch.Height, ch.XOffset, ch.YOffset, ch.XAdvance)
+	}
+	sheet, err := baked.Page(ch.Page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(sheet.At(ch.X+x, ch.Y+y)).(color.RGBA)
+	}
+	if got := at(1, 1); got != (color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}) {
+		t.Errorf("got glyph color %v, want white", got)
+	}
+	if got := at(1, 0); got != (color.RGBA{A: 0xFF}) {
+		t.Errorf("got outline color %v, want black", got)
+	}
+	if got := at(7, 4); got.A == 0 || got.A == 0xFF {
+		t.Errorf("got shadow color %v, want semi-transparent", got)
+	}
+	if got := at(0, 8); got.A != 0 {
+		t.Errorf("got color %v outside the effects, want transparent", got)
+	}
+}
+
+func TestBakeEffectsGradient(t *testing.T) {
+	f := opaqueFont("A")
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	baked, err := BakeEffects(f, &EffectsOptions{GradientTop: red, MaxPageSize: image.Pt(32, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := baked.Descriptor.Chars['A']
+	sheet, _ := baked.Page(ch.Page)
+	top := color.RGBAModel.Convert(sheet.At(ch.X, ch.Y)).(color.RGBA)
+	bottom := color.RGBAModel.Convert(sheet.At(ch.X, ch.Y+ch.Height-1)).(color.RGBA)
+	if top != red {
+		t.Errorf("got color %v at the top, want %v", top, red)
+	}
+	if bottom.G == 0 || bottom.G <= top.G {
+		t.Errorf("got color %v at the bottom, want it closer to white than %v", bottom, top)
+	}
+}
+
+func TestBakeEffectsErrors(t *testing.T) {
+	f := opaqueFont("A")
+	if _, err := BakeEffects(f, &EffectsOptions{Outline: -1}); err == nil {
+		t.Error("negative outline: got no error")
+	}
+	f.Descriptor.Common.Packed = true
+	if _, err := BakeEffects(f, nil); err == nil {
+		t.Error("packed font: got no error")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// newTextReader returns a buffered reader for descriptor input that yields
+// UTF-8 without a byte order mark. Input in UTF-16, as saved by some Windows
+// tools, is transcoded. It is detected by its byte order mark or, without
+// one, by the zero bytes of the first character, which is always ASCII in
+// a valid descriptor. A leading UTF-8 byte order mark is discarded.
+// Windows line endings (CRLF) need no conversion, because the parser
+// treats carriage returns as white space.
+func newTextReader(r io.Reader) *bufio.Reader {
+	br := bufio.NewReader(r)
+	var enc encoding.Encoding
+	if b, _ := br.Peek(2); len(b) == 2 {
+		switch {
+		case b[0] == 0xff && b[1] == 0xfe, b[0] == 0xfe && b[1] == 0xff:
+			enc = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+		case b[0] != 0 && b[1] == 0:
+			enc = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+		case b[0] == 0 && b[1] != 0:
+			enc = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+		}
+	}
+	if enc != nil {
+		br = bufio.NewReader(transform.NewReader(br, enc.NewDecoder()))
+	}
+	skipBOM(br)
+	return br
+}
+
+// skipBOM discards a leading UTF-8 byte order mark, which the parsers would
+// reject.
+func skipBOM(br *bufio.Reader) {
+	bom := []byte("\ufeff")
+	if b, _ := br.Peek(len(bom)); bytes.Equal(b, bom) {
+		_, _ = br.Discard(len(bom))
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestReadDescriptorEncodings(t *testing.T) {
+	crlf := strings.ReplaceAll(testDescriptor, "\n", "\r\n")
+	utf16 := func(endianness unicode.Endianness, bom unicode.BOMPolicy, input string) string {
+		s, err := unicode.UTF16(endianness, bom).NewEncoder().String(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	const jsonDescriptor = `{"common": {"lineHeight": 8, "base": 6}, "chars": [{"id": 65}]}`
+	inputs := map[string]string{
+		"CRLF":              crlf,
+		"UTF-8 BOM":         "\ufeff" + testDescriptor,
+		"UTF-16LE BOM":      utf16(unicode.LittleEndian, unicode.UseBOM, crlf),
+		"UTF-16BE BOM":      utf16(unicode.BigEndian, unicode.UseBOM, crlf),
+		"UTF-16LE":          utf16(unicode.LittleEndian, unicode.IgnoreBOM, crlf),
+		"UTF-16BE":          utf16(unicode.BigEndian, unicode.IgnoreBOM, crlf),
+		"UTF-8 BOM JSON":    "\ufeff" + jsonDescriptor,
+		"UTF-16LE BOM JSON": utf16(unicode.LittleEndian, unicode.UseBOM, jsonDescriptor),
+	}
+	for name, input := range inputs {
+		d, err := ReadDescriptor(strings.NewReader(input))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if d.Common.LineHeight != 8 || d.Chars['A'].ID != 'A' {
+			t.Errorf("%s: got line height %d and chars %v", name, d.Common.LineHeight, d.Chars)
+		}
+	}
+}
@@ -4,6 +4,8 @@
 
 package bmfont
 
+import "fmt"
+
 type errorList []error
 
 func (list errorList) Err() error {
@@ -17,5 +19,14 @@ func (list errorList) Error() string {
 	if len(list) == 0 {
 		return "no errors"
 	}
+	if len(list) > 1 {
+		return fmt.Sprintf("%s (and %d more errors)", list[0], len(list)-1)
+	}
 	return list[0].Error()
 }
+
+// Unwrap returns the errors of the list, so that errors.Is and errors.As
+// can inspect them.
+func (list errorList) Unwrap() []error {
+	return list
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import "strings"
+
+// Features is a set of optional descriptor attributes and extensions that
+// were present in the parsed source, to tell them apart from absent ones
+// that read as zero values.
+type Features uint
+
+const (
+	// FeatureOutline is set if the info tag has an outline attribute.
+	FeatureOutline Features = 1 << iota
+	// FeatureChannelInfo is set if the common tag has a packed attribute
+	// or channel information attributes like alphaChnl.
+	FeatureChannelInfo
+	// FeatureCharChannels is set if char tags have chnl attributes.
+	FeatureCharChannels
+	// FeatureDistanceField is set if the descriptor has signed distance
+	// field extensions, like the distanceField tag of msdf-bmfont-xml.
+	FeatureDistanceField
+	// FeatureRotation is set if char tags have rotated attributes, as
+	// emitted by some atlas packers for glyphs stored rotated by 90°.
+	FeatureRotation
+)
+
+var featureNames = []string{"outline", "channelinfo", "charchannels", "distancefield", "rotation"}
+
+// Has reports whether all features of g are included in f.
+func (f Features) Has(g Features) bool {
+	return f&g == g
+}
+
+func (f Features) String() string {
+	var names []string
+	for i, name := range featureNames {
+		if f&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// detectFeatures determines the features present in the tags.
+func detectFeatures(tags []tag) Features {
+	var f Features
+	for i := range tags {
+		t := &tags[i]
+		switch t.name {
+		case "info":
+			if t.has("outline") {
+				f |= FeatureOutline
+			}
+		case "common":
+			if t.has("packed", "alphaChnl", "redChnl", "greenChnl", "blueChnl") {
+				f |= FeatureChannelInfo
+			}
+			if t.has("distanceRange", "fieldType") {
+				f |= FeatureDistanceField
+			}
+		case "char":
+			if t.has("chnl") {
+				f |= FeatureCharChannels
+			}
+			if t.has("rotated") {
+				f |= FeatureRotation
+			}
+		case "distanceField":
+			f |= FeatureDistanceField
+		}
+	}
+	return f
+}
+
+// has reports whether the tag has any of the attributes.
+func (t *tag) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := t.attrs[name]; ok {
+			return true
+		}
+	}
+	return false
+}
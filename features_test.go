@@ -0,0 +1,58 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescriptorFeatures(t *testing.T) {
+	d, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := FeatureOutline | FeatureChannelInfo | FeatureCharChannels; d.Features != want {
+		t.Errorf("got features %v, want %v", d.Features, want)
+	}
+
+	minimal := `info face="Min" size=8
+common lineHeight=8 base=6 scaleW=16 scaleH=8 distanceRange=4
+page id=0 file="sheet.png"
+char id=65 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0 rotated=1
+`
+	if d, err = ReadDescriptor(strings.NewReader(minimal)); err != nil {
+		t.Fatal(err)
+	}
+	if want := FeatureDistanceField | FeatureRotation; d.Features != want {
+		t.Errorf("got features %v, want %v", d.Features, want)
+	}
+}
+
+func TestFeaturesHas(t *testing.T) {
+	f := FeatureOutline | FeatureRotation
+	if !f.Has(FeatureOutline) || !f.Has(FeatureOutline|FeatureRotation) {
+		t.Errorf("%v: Has reports included features as missing", f)
+	}
+	if f.Has(FeatureChannelInfo) || f.Has(FeatureOutline|FeatureChannelInfo) {
+		t.Errorf("%v: Has reports missing features as included", f)
+	}
+}
+
+func TestFeaturesString(t *testing.T) {
+	tests := []struct {
+		f    Features
+		want string
+	}{
+		{0, ""},
+		{FeatureOutline, "outline"},
+		{FeatureChannelInfo | FeatureDistanceField | FeatureRotation, "channelinfo|distancefield|rotation"},
+	}
+	for _, tt := range tests {
+		if got := tt.f.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
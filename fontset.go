@@ -0,0 +1,137 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A FontSet is a font in several resolutions, e.g. rendered at 1x and 2x
+// the size for low and high resolution displays, so that text is drawn with
+// crisp glyphs at different scales.
+type FontSet struct {
+	// Fonts maps the sizes of the resolutions relative to the base
+	// resolution to the fonts, e.g. 1 and 2 for "font.fnt" and
+	// "font@2x.fnt".
+	Fonts map[float64]*BitmapFont
+}
+
+// LoadFontSet loads the font of the base resolution from the descriptor file
+// at the given path, e.g. "font.fnt", and the fonts of the other resolutions
+// from the descriptor files in the same directory whose names have an
+// additional "@<scale>x" suffix, e.g. "font@2x.fnt" or "font@1.5x.fnt".
+// The base resolution file is optional if there are others.
+func LoadFontSet(path string) (*FontSet, error) {
+	dir, file := filepath.Split(path)
+	ext := filepath.Ext(file)
+	prefix := strings.TrimSuffix(file, ext) + "@"
+	entries, err := os.ReadDir(filepath.Join(dir, "."))
+	if err != nil {
+		return nil, err
+	}
+	set := &FontSet{Fonts: make(map[float64]*BitmapFont)}
+	for _, e := range entries {
+		name := e.Name()
+		factor, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		factor, ok = strings.CutSuffix(factor, "x"+ext)
+		scale, err := strconv.ParseFloat(factor, 64)
+		if !ok || err != nil || scale <= 0 {
+			continue
+		}
+		f, err := Load(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		set.Fonts[scale] = f
+	}
+	if _, err := os.Stat(path); err == nil || len(set.Fonts) == 0 {
+		f, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		set.Fonts[1] = f
+	}
+	return set, nil
+}
+
+// Select returns the font whose resolution best matches the given scale
+// factor relative to the base resolution, and the factor its glyphs must be
+// scaled with to reach this scale. Between two equally close resolutions the
+// larger one is selected, since scaling down keeps more detail.
+// It returns nil if the set has no fonts.
+func (s *FontSet) Select(scale float64) (f *BitmapFont, remaining float64) {
+	if scale <= 0 {
+		scale = 1
+	}
+	f, k := nearestFont(s.Fonts, nil, 0, scale)
+	if f == nil {
+		return nil, 0
+	}
+	return f, scale / k
+}
+
+// Font returns a font for drawing text with all resolutions of the set: the
+// font of the smallest resolution with the other resolutions as its
+// Variants. The drawing and measuring methods select the best matching
+// resolution for the scale of the style, where Scale is relative to the
+// smallest resolution. It shares the descriptors and page sheets with the
+// fonts of the set. Entries with a nil font or a scale that is not positive
+// and finite are ignored, and it returns an error if there are no others.
+func (s *FontSet) Font() (*BitmapFont, error) {
+	base := math.Inf(1)
+	for k, f := range s.Fonts {
+		if validResolution(k, f) {
+			base = min(base, k)
+		}
+	}
+	b := s.Fonts[base]
+	if !validResolution(base, b) {
+		return nil, errors.New("bmfont: font set has no fonts with a positive scale")
+	}
+	f := &BitmapFont{
+		Descriptor:    b.Descriptor,
+		PageSheets:    b.PageSheets,
+		pages:         b.pages,
+		Ligatures:     b.Ligatures,
+		Substitutions: b.Substitutions,
+		Variants:      make(map[float64]*BitmapFont, len(s.Fonts)-1),
+	}
+	for k, v := range s.Fonts {
+		if validResolution(k, v) && k != base {
+			f.Variants[k/base] = v
+		}
+	}
+	return f, nil
+}
+
+// validResolution reports whether the font of a set with the given scale can
+// be used for drawing text.
+func validResolution(scale float64, f *BitmapFont) bool {
+	return f != nil && scale > 0 && !math.IsInf(scale, 1)
+}
+
+// nearestFont returns the font of the map whose scale is closest to s, or
+// the given best font and its scale if none is closer, together with the
+// scale of the returned font.
+func nearestFont(fonts map[float64]*BitmapFont, best *BitmapFont, bestScale, s float64) (*BitmapFont, float64) {
+	dist := func(k float64) float64 { return math.Abs(math.Log(k / s)) }
+	for k, f := range fonts {
+		if k <= 0 || f == nil {
+			continue
+		}
+		if best == nil || dist(k) < dist(bestScale) || dist(k) == dist(bestScale) && k > bestScale {
+			best, bestScale = f, k
+		}
+	}
+	return best, bestScale
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFontSetFont(t *testing.T) {
+	f1, f2 := testFont("a", 1), testFont("a", 2)
+	tests := []struct {
+		name    string
+		fonts   map[float64]*BitmapFont
+		base    *BitmapFont
+		variant *BitmapFont
+	}{
+		{"empty", nil, nil, nil},
+		{"no positive scale", map[float64]*BitmapFont{0: f1, -1: f2}, nil, nil},
+		{"infinite scale", map[float64]*BitmapFont{math.Inf(1): f1}, nil, nil},
+		{"nil font", map[float64]*BitmapFont{1: nil}, nil, nil},
+		{"smallest nil", map[float64]*BitmapFont{0.5: nil, 1: f1, 2: f2}, f1, f2},
+		{"two resolutions", map[float64]*BitmapFont{1: f1, 2: f2}, f1, f2},
+	}
+	for _, tt := range tests {
+		set := &FontSet{Fonts: tt.fonts}
+		f, err := set.Font()
+		if tt.base == nil {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if f.Descriptor != tt.base.Descriptor {
+			t.Errorf("%s: wrong base font", tt.name)
+		}
+		if len(f.Variants) != 1 || f.Variants[2] != tt.variant {
+			t.Errorf("%s: got variants %v, want 2x variant", tt.name, f.Variants)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"math"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// exactMetrics holds the exact metrics of a character with sub-pixel
+// precision. Fonts derived from other fonts keep them, so that repeated
+// transformations don't accumulate rounding errors, and they are only
+// rounded when the glyphs are drawn. The whole pixel XOffset, YOffset and
+// XAdvance fields hold the rounded values and remain authoritative: an
+// exact value is only used as long as it rounds to its whole pixel field,
+// so it is dropped if the field is modified.
+type exactMetrics struct {
+	xOffset, yOffset, xAdvance fixed.Int26_6
+}
+
+// exactOffset returns the offset of the character with sub-pixel precision.
+func (c *Char) exactOffset() fixed.Point26_6 {
+	return fixed.Point26_6{
+		X: exact(c.XOffset, c.exact.xOffset),
+		Y: exact(c.YOffset, c.exact.yOffset),
+	}
+}
+
+// exactAdvance returns the advance of the character with sub-pixel
+// precision.
+func (c *Char) exactAdvance() fixed.Int26_6 {
+	return exact(c.XAdvance, c.exact.xAdvance)
+}
+
+// setExact sets the offset and the advance of the character to the rounded
+// exact values and keeps the exact values.
+func (c *Char) setExact(offset fixed.Point26_6, advance fixed.Int26_6) {
+	c.exact = exactMetrics{xOffset: offset.X, yOffset: offset.Y, xAdvance: advance}
+	c.XOffset, c.YOffset, c.XAdvance = offset.X.Round(), offset.Y.Round(), advance.Round()
+}
+
+// exactAmount returns the kerning amount with sub-pixel precision.
+func (k Kerning) exactAmount() fixed.Int26_6 {
+	return exact(k.Amount, k.exact)
+}
+
+// exactKerning returns a kerning with the given exact amount.
+func exactKerning(amount fixed.Int26_6) Kerning {
+	return Kerning{Amount: amount.Round(), exact: amount}
+}
+
+// exact returns the exact value v if it rounds to the whole number n, which
+// is the field it was rounded to, or n otherwise.
+func exact(n int, v fixed.Int26_6) fixed.Int26_6 {
+	if v.Round() != n {
+		return fixed.I(n)
+	}
+	return v
+}
+
+// scaledExact scales a fixed-point value by the given factor.
+func scaledExact(v fixed.Int26_6, s float64) fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(float64(v) * s))
+}
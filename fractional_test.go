@@ -0,0 +1,107 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestCharExactMetrics(t *testing.T) {
+	var ch Char
+	offset := fixed.Point26_6{X: fixed.I(1) + 16, Y: -fixed.I(2) - 48}
+	advance := fixed.I(5) + 40
+	ch.setExact(offset, advance)
+	if ch.XOffset != 1 || ch.YOffset != -3 || ch.XAdvance != 6 {
+		t.Errorf("got offset %d,%d and advance %d, want 1,-3 and 6", ch.XOffset, ch.YOffset, ch.XAdvance)
+	}
+	if got := ch.exactOffset(); got != offset {
+		t.Errorf("got exact offset %v, want %v", got, offset)
+	}
+	if got := ch.exactAdvance(); got != advance {
+		t.Errorf("got exact advance %v, want %v", got, advance)
+	}
+	k := exactKerning(-fixed.I(1) - 16)
+	if k.Amount != -1 || k.exactAmount() != -fixed.I(1)-16 {
+		t.Errorf("got kerning amount %d, exact %v, want -1 and %v", k.Amount, k.exactAmount(), -fixed.I(1)-16)
+	}
+}
+
+func TestEditedExactMetrics(t *testing.T) {
+	var ch Char
+	ch.setExact(fixed.Point26_6{X: fixed.I(1) + 16, Y: fixed.I(2) + 16}, fixed.I(5)+40)
+	// Modified whole pixel fields take precedence over the exact values.
+	ch.XOffset, ch.XAdvance = 3, 8
+	if got, want := ch.exactOffset(), (fixed.Point26_6{X: fixed.I(3), Y: fixed.I(2) + 16}); got != want {
+		t.Errorf("got exact offset %v, want %v", got, want)
+	}
+	if got, want := ch.exactAdvance(), fixed.I(8); got != want {
+		t.Errorf("got exact advance %v, want %v", got, want)
+	}
+	k := exactKerning(-fixed.I(1) - 16)
+	k.Amount = -2
+	if got, want := k.exactAmount(), -fixed.I(2); got != want {
+		t.Errorf("got exact kerning amount %v, want %v", got, want)
+	}
+}
+
+func TestFractionalAdvancesDoNotAccumulate(t *testing.T) {
+	f := testFont("A", 1)
+	ch := f.Descriptor.Chars['A']
+	ch.setExact(fixed.Point26_6{}, fixed.I(5)+32) // 5.5 pixels
+	f.Descriptor.Chars['A'] = ch
+	l := f.layout("AAAA", nil, nil)
+	if got, want := l.lines[0].width, fixed.I(22); got != want {
+		t.Errorf("got line width %v, want %v", got, want)
+	}
+	// Scaling keeps the sub-pixel precision until drawing.
+	l = f.layout("AAAA", &Style{Scale: 0.5}, nil)
+	if got, want := l.lines[0].width, fixed.I(11); got != want {
+		t.Errorf("got scaled line width %v, want %v", got, want)
+	}
+}
+
+func TestMeasureTextFixed(t *testing.T) {
+	f := testFont("A", 1)
+	got := f.MeasureTextFixed("AA", &Style{Scale: 1.5})
+	want := fixed.Rectangle26_6{
+		Min: fixed.Point26_6{X: 0, Y: -fixed.I(9)},
+		Max: fixed.Point26_6{X: fixed.I(27) / 2, Y: 0},
+	}
+	if got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+}
+
+func TestDrawTextFixed(t *testing.T) {
+	f := testFont("A", 1)
+	// The second column of the glyph is opaque white.
+	sheet := f.PageSheets[0].(*image.NRGBA)
+	for y := 0; y < 6; y++ {
+		sheet.SetNRGBA(1, y, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	}
+	pos := fixed.Point26_6{X: fixed.I(1) / 2, Y: fixed.I(6)}
+	tests := []struct {
+		rounding GlyphRounding
+		alpha    [2]int // the alpha values of the second and third column
+	}{
+		{GlyphRound, [2]int{0, 0xff}},
+		{GlyphFloor, [2]int{0xff, 0}},
+		// Resampled between two columns.
+		{GlyphFractional, [2]int{0x80, 0x80}},
+	}
+	for _, tt := range tests {
+		dst := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		f.DrawTextFixed(dst, pos, "A", &Style{GlyphRounding: tt.rounding})
+		for i, want := range tt.alpha {
+			if got := int(dst.RGBAAt(1+i, 3).A); got < want-2 || got > want+2 {
+				t.Errorf("rounding %v: got alpha %d in column %d, want %d", tt.rounding, got, 1+i, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// GenerateOptions configure how Generate creates a bitmap font from a
+// TrueType or OpenType font. A nil *GenerateOptions is valid and selects the
+// defaults.
+type GenerateOptions struct {
+	// Size is the font size in pixels. If it is 0 a size of 32 is used.
+	Size float64
+	// Runes are the characters to include in the bitmap font. Characters
+	// that are not included in the source font are skipped. If it is empty
+	// the printable ASCII characters are included.
+	Runes []rune
+	// Padding is the space in pixels added around each glyph bitmap.
+	Padding Padding
+	// Spacing is the space in pixels between the glyphs in the page sheets.
+	// If it is zero a spacing of 1 pixel in both directions is used.
+	Spacing Spacing
+	// Outline is the thickness of a black outline drawn around the white
+	// glyphs in pixels. If it is 0 the glyphs have no outline.
+	Outline int
+	// PageSize is the size of the page sheet images. If it is zero the
+	// pages are 512×512 pixels.
+	PageSize image.Point
+	// PageFile returns the file name for the page with the given ID. If it
+	// is nil the pages are named "page_0.png", "page_1.png" and so on.
+	PageFile func(id int) string
+}
+
+// Generate rasterizes the glyphs of a TrueType or OpenType font, given as the
+// contents of the font file, and packs them into the page sheets of a new
+// bitmap font. The kerning pairs between all included characters are taken
+// from the source font's kerning table.
+func Generate(ttf []byte, opts *GenerateOptions) (*BitmapFont, error) {
+	if opts == nil {
+		opts = &GenerateOptions{}
+	}
+	src, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	size := opts.Size
+	if size <= 0 {
+		size = 32
+	}
+	face, err := opentype.NewFace(src, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	runes := opts.Runes
+	if len(runes) == 0 {
+		for r := rune(' '); r <= '~'; r++ {
+			runes = append(runes, r)
+		}
+	}
+	spacing := opts.Spacing
+	if spacing == (Spacing{}) {
+		spacing = Spacing{Horizontal: 1, Vertical: 1}
+	}
+	pageSize := opts.PageSize
+	if pageSize == (image.Point{}) {
+		pageSize = image.Pt(512, 512)
+	}
+	pageFile := opts.PageFile
+	if pageFile == nil {
+		pageFile = func(id int) string {
+			return fmt.Sprintf("page_%d.png", id)
+		}
+	}
+
+	metrics := face.Metrics()
+	base := metrics.Ascent.Ceil()
+	desc := &Descriptor{
+		Info: Info{
+			Face:     familyName(src),
+			Size:     int(math.Round(size)),
+			Unicode:  true,
+			StretchH: 100,
+			Smooth:   true,
+			AA:       1,
+			Padding:  opts.Padding,
+			Spacing:  spacing,
+			Outline:  opts.Outline,
+		},
+		Common: Common{
+			LineHeight: metrics.Height.Ceil(),
+			Base:       base,
+			ScaleW:     pageSize.X,
+			ScaleH:     pageSize.Y,
+		},
+		Pages:   make(map[int]Page),
+		Chars:   make(map[rune]Char),
+		Kerning: make(map[CharPair]Kerning),
+	}
+	if opts.Outline > 0 {
+		desc.Common.AlphaChannel = GlyphAndOutline
+	}
+
+	var glyphs []*image.RGBA
+	var chars []Char
+	for _, r := range runes {
+		if _, dup := desc.Chars[r]; dup {
+			continue
+		}
+		dr, mask, maskp, advance, ok := face.Glyph(fixed.Point26_6{}, r)
+		if !ok {
+			continue
+		}
+		img, offset := renderGlyph(dr, mask, maskp, opts.Padding, opts.Outline)
+		ch := Char{
+			ID:      r,
+			Width:   img.Bounds().Dx(),
+			Height:  img.Bounds().Dy(),
+			XOffset: offset.X,
+			YOffset: base + offset.Y,
+			Channel: All,
+		}
+		ch.setExact(fixed.P(offset.X, base+offset.Y), advance)
+		desc.Chars[r] = ch
+		glyphs = append(glyphs, img)
+		chars = append(chars, ch)
+	}
+	if len(chars) == 0 {
+		return nil, errors.New("bmfont: the font includes none of the requested characters")
+	}
+
+	sizes := make([]image.Point, len(glyphs))
+	for i, img := range glyphs {
+		sizes[i] = img.Bounds().Size()
+	}
+	placed, used, err := packRects(sizes, pageSize, max(spacing.Horizontal, spacing.Vertical))
+	if err != nil {
+		return nil, fmt.Errorf("bmfont: glyphs don't fit into pages of size %dx%d", pageSize.X, pageSize.Y)
+	}
+	f := &BitmapFont{
+		Descriptor: desc,
+		PageSheets: make(map[int]image.Image),
+	}
+	for id := range used {
+		desc.Pages[id] = Page{ID: id, File: pageFile(id)}
+		f.PageSheets[id] = image.NewRGBA(image.Rectangle{Max: pageSize})
+	}
+	for i, p := range placed {
+		ch := chars[i]
+		ch.X, ch.Y, ch.Page = p.pos.X, p.pos.Y, p.page
+		desc.Chars[ch.ID] = ch
+		sheet := f.PageSheets[p.page].(*image.RGBA)
+		draw.Draw(sheet, ch.Bounds(), glyphs[i], image.Point{}, draw.Src)
+	}
+
+	for _, a := range chars {
+		for _, b := range chars {
+			if k := face.Kern(a.ID, b.ID); k != 0 {
+				desc.Kerning[CharPair{First: a.ID, Second: b.ID}] = exactKerning(k)
+			}
+		}
+	}
+	return f, nil
+}
+
+// renderGlyph renders the glyph mask in white, with padding and an optional
+// black outline, into a new image. It returns the image and the offset of
+// its top left corner relative to the pen position on the base line.
+func renderGlyph(dr image.Rectangle, mask image.Image, maskp image.Point, pad Padding, outline int) (*image.RGBA, image.Point) {
+	if dr.Empty() {
+		return image.NewRGBA(image.Rectangle{}), image.Point{}
+	}
+	margin := image.Pt(pad.Left+outline, pad.Up+outline)
+	img := image.NewRGBA(image.Rect(0, 0,
+		pad.Left+dr.Dx()+pad.Right+2*outline,
+		pad.Up+dr.Dy()+pad.Down+2*outline,
+	))
+	glyphRect := image.Rectangle{Min: margin, Max: margin.Add(dr.Size())}
+	if outline > 0 {
+		alpha := image.NewAlpha(img.Bounds())
+		draw.Draw(alpha, glyphRect, mask, maskp, draw.Src)
+		draw.DrawMask(img, img.Bounds(), image.NewUniform(color.Black), image.Point{}, dilate(alpha, outline), image.Point{}, draw.Over)
+	}
+	draw.DrawMask(img, glyphRect, image.NewUniform(color.White), image.Point{}, mask, maskp, draw.Over)
+	return img, dr.Min.Sub(margin)
+}
+
+// dilate returns a copy of the alpha mask grown by the given radius in
+// pixels, with the same bounds.
+func dilate(mask *image.Alpha, radius int) *image.Alpha {
+	b := mask.Bounds()
+	dilated := image.NewAlpha(b)
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx*dx+dy*dy > radius*radius {
+				continue
+			}
+			draw.Draw(dilated, b.Add(image.Pt(dx, dy)), mask, b.Min, draw.Over)
+		}
+	}
+	return dilated
+}
+
+func familyName(f *sfnt.Font) string {
+	name, err := f.Name(nil, sfnt.NameIDFamily)
+	if err != nil {
+		return ""
+	}
+	return name
+}
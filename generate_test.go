@@ -0,0 +1,45 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestGenerate(t *testing.T) {
+	f, err := Generate(goregular.TTF, &GenerateOptions{
+		Size:     16,
+		Runes:    []rune("AVa 一"),
+		PageSize: image.Pt(64, 64),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := f.Descriptor
+	if d.Info.Face != "Go" || d.Info.Size != 16 {
+		t.Errorf("got face %q of size %d, want Go of size 16", d.Info.Face, d.Info.Size)
+	}
+	if len(d.Chars) != 4 {
+		t.Errorf("got %d chars, want 4 without the one missing in the source font", len(d.Chars))
+	}
+	if _, ok := d.Chars['一']; ok {
+		t.Error("got a char missing in the source font")
+	}
+	for r, ch := range d.Chars {
+		sheet := f.PageSheets[ch.Page]
+		if sheet == nil || !ch.Bounds().In(sheet.Bounds()) {
+			t.Errorf("char %q: bitmap %v is outside of its page", r, ch.Bounds())
+		}
+	}
+	if ch := d.Chars['A']; ch.Width == 0 || ch.XAdvance == 0 {
+		t.Errorf("got char A %+v, want a glyph with an advance", ch)
+	}
+	if d.Common.LineHeight < 16 || d.Common.Base <= 0 || d.Common.Base > d.Common.LineHeight {
+		t.Errorf("got line height %d and base %d", d.Common.LineHeight, d.Common.Base)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image/color"
+)
+
+// A GlyphFunc is called for each glyph of a text before it is drawn, see
+// Style.GlyphFunc. It can adjust the position, the scale and the color of
+// the glyph, or skip it, e.g. for wavy, shaky or rainbow colored text.
+type GlyphFunc func(g GlyphInfo, t *GlyphTransform)
+
+// GlyphInfo describes a glyph passed to a GlyphFunc.
+type GlyphInfo struct {
+	// Rune is the character the glyph represents. It is the fallback
+	// character '?' for characters that are not included in the font.
+	Rune rune
+	// Index is the index of the glyph among all glyphs of the text, in
+	// drawing order.
+	Index int
+	// Line is the index of the line of the glyph.
+	Line int
+	// Offset is the byte offset of the character in the text, after the
+	// case transformation of the style.
+	Offset int
+}
+
+// A GlyphTransform is the adjustment of a glyph by a GlyphFunc. The layout
+// of the text is not affected by it: the other glyphs keep their positions.
+type GlyphTransform struct {
+	// DX and DY are the offset of the glyph in pixels of the destination
+	// image.
+	DX, DY float64
+	// Scale is the factor the glyph is scaled with around its pen position
+	// on the base line. It is initially 1.
+	Scale float64
+	// Color is the color the glyph is tinted with, or, for outlined text,
+	// the color inside its outline. It is initially the color of the glyph,
+	// including the transparency of the style. If it is nil the glyph is
+	// drawn with the colors of the page sheet.
+	Color color.Color
+	// Skip omits the glyph.
+	Skip bool
+}
+
+func (s *Style) glyphFunc() GlyphFunc {
+	if s == nil {
+		return nil
+	}
+	return s.GlyphFunc
+}
+
+// drawnGlyphs returns the layout with the glyphs that are drawn: those in the
+// visible range of the style, adjusted by its glyph function. If the style
+// has neither, it returns the layout itself. Layouts adjusted by a glyph
+// function are colored, so that the glyphs can have different colors.
+func (l *layout) drawnGlyphs(style *Style) *layout {
+	fn, visible := style.glyphFunc(), style.visible()
+	if fn == nil && visible == nil {
+		return l
+	}
+	c := l.clone()
+	if fn != nil && !c.colored {
+		c.colored = true
+		tint := style.color()
+		c.eachGlyph(func(_ int, g *glyph) {
+			if g.image == nil {
+				g.color = tint
+			}
+		})
+	}
+	n := 0
+	for i := range c.lines {
+		ln := &c.lines[i]
+		glyphs := ln.glyphs[:0]
+		for _, g := range ln.glyphs {
+			index := n
+			n++
+			if visible != nil && !visible.contains(index) {
+				continue
+			}
+			if fn == nil {
+				glyphs = append(glyphs, g)
+				continue
+			}
+			t := GlyphTransform{Scale: 1, Color: g.color}
+			if t.Color == nil && g.image == nil {
+				t.Color = c.glyphColor
+			}
+			fn(GlyphInfo{Rune: g.char.ID, Index: index, Line: i, Offset: g.offset}, &t)
+			if t.Skip {
+				continue
+			}
+			g.dot.X += toFixed(t.DX)
+			g.dot.Y += toFixed(t.DY)
+			if t.Scale > 0 {
+				g.scale *= t.Scale
+			}
+			g.color = t.Color
+			glyphs = append(glyphs, g)
+		}
+		ln.glyphs = glyphs
+	}
+	return c
+}
+
+// A GlyphRange is a range of glyphs of a text, by their indices in drawing
+// order, see Style.Visible. It includes the glyph at index Start, but not the
+// one at index End.
+type GlyphRange struct {
+	Start, End int
+}
+
+func (r *GlyphRange) contains(i int) bool {
+	return r.Start <= i && i < r.End
+}
+
+func (s *Style) visible() *GlyphRange {
+	if s == nil {
+		return nil
+	}
+	return s.Visible
+}
+
+// GlyphCount returns the number of glyphs of the text laid out with the given
+// style, including spaces, e.g. for determining how many glyphs remain to be
+// revealed with Style.Visible. Characters that are not included in the font
+// and have no fallback character are not counted.
+func (f *BitmapFont) GlyphCount(text string, style *Style) int {
+	return f.layout(text, style, nil).glyphCount()
+}
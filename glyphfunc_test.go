@@ -0,0 +1,104 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestGlyphFunc(t *testing.T) {
+	f := opaqueFont("AB")
+	red, blue := color.RGBA{R: 0xff, A: 0xff}, color.RGBA{B: 0xff, A: 0xff}
+	var infos []GlyphInfo
+	fn := func(g GlyphInfo, t *GlyphTransform) {
+		infos = append(infos, g)
+		switch g.Index {
+		case 0:
+			t.Color = blue
+		case 1:
+			t.Skip = true
+		case 2:
+			t.DY = 2
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 24))
+	f.DrawTextOpts(dst, image.Pt(0, 6), "AB\nA", WithColor(red), WithGlyphFunc(fn))
+
+	want := []GlyphInfo{
+		{Rune: 'A', Index: 0, Line: 0, Offset: 0},
+		{Rune: 'B', Index: 1, Line: 0, Offset: 1},
+		{Rune: 'A', Index: 2, Line: 1, Offset: 3},
+	}
+	if !slices.Equal(infos, want) {
+		t.Errorf("got glyph infos %+v, want %+v", infos, want)
+	}
+	if got := dst.RGBAAt(1, 1); got != blue {
+		t.Errorf("got first glyph color %v, want %v", got, blue)
+	}
+	if got := dst.RGBAAt(6, 1); got != (color.RGBA{}) {
+		t.Errorf("got %v for the skipped glyph, want transparent", got)
+	}
+	// The third glyph is moved down from 8..14 to 10..16 in the color of
+	// the style.
+	if got := dst.RGBAAt(1, 15); got != red {
+		t.Errorf("got %v at the bottom of the moved glyph, want %v", got, red)
+	}
+	if got := dst.RGBAAt(1, 9); got != (color.RGBA{}) {
+		t.Errorf("got %v above the moved glyph, want transparent", got)
+	}
+	// The measurements are not affected.
+	if got, want := f.MeasureTextOpts("AB\nA", WithGlyphFunc(fn)), f.MeasureText("AB\nA"); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+}
+
+func TestGlyphFuncScale(t *testing.T) {
+	f := opaqueFont("A")
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	f.DrawTextOpts(dst, image.Pt(0, 12), "A", WithGlyphFunc(func(_ GlyphInfo, t *GlyphTransform) {
+		t.Scale = 2
+	}))
+	// The glyph is scaled around its pen position on the base line.
+	if dst.RGBAAt(7, 0).A != 0xff || dst.RGBAAt(8, 0).A != 0 {
+		t.Error("glyph was not scaled to 8×12 pixels")
+	}
+}
+
+func TestVisibleGlyphs(t *testing.T) {
+	f := opaqueFont("AB ")
+	text := "AB AB"
+	full := f.layout(text, &Style{MaxWidth: 12}, nil)
+	var all []glyph
+	full.eachGlyph(func(_ int, g *glyph) { all = append(all, *g) })
+	if got := f.GlyphCount(text, &Style{MaxWidth: 12}); got != 4 {
+		t.Errorf("got glyph count %d, want 4", got)
+	}
+	for n := 0; n <= 4; n++ {
+		l := full.drawnGlyphs(&Style{Visible: &GlyphRange{End: n}})
+		if got := l.glyphCount(); got != n {
+			t.Errorf("%d visible: got %d glyphs", n, got)
+		}
+		// The visible glyphs keep the positions of the whole text.
+		l.eachGlyph(func(i int, g *glyph) {
+			if want := all[i]; g.dot != want.dot || g.r != want.r {
+				t.Errorf("%d visible: glyph %d: got %q at %v, want %q at %v", n, i, g.r, g.dot, want.r, want.dot)
+			}
+		})
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 24))
+	f.DrawTextOpts(dst, image.Pt(0, 6), text, WithMaxWidth(12), WithVisibleGlyphs(3))
+	if dst.RGBAAt(1, 9).A != 0xff || dst.RGBAAt(6, 9).A != 0 {
+		t.Error("got wrong glyphs on the second line, want only its first glyph")
+	}
+	// A range in the middle of the text.
+	l := full.drawnGlyphs(&Style{Visible: &GlyphRange{Start: 1, End: 3}})
+	if got := layoutRunes(l); !slices.Equal(got, []string{"B", "A"}) {
+		t.Errorf("got lines %q, want [B A]", got)
+	}
+}
@@ -1,3 +1,8 @@
 module github.com/fzipp/bmfont
 
 go 1.21
+
+require (
+	golang.org/x/image v0.24.0
+	golang.org/x/text v0.22.0
+)
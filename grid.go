@@ -0,0 +1,150 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// GridOptions configure how FromGrid creates a bitmap font from a sprite
+// sheet with the glyphs arranged in a grid of equally sized cells.
+type GridOptions struct {
+	// CellSize is the size of the grid cells in pixels. It is required.
+	CellSize image.Point
+	// Charset are the characters of the cells, in rows from left to right
+	// and top to bottom. It is required. Cells beyond the end of the charset
+	// are ignored.
+	Charset string
+	// Margin is the offset of the first cell from the top left corner of
+	// the sheet in pixels.
+	Margin image.Point
+	// Gap is the space between adjacent cells in pixels.
+	Gap image.Point
+	// Key is a background color that is made transparent, for sheets
+	// without an alpha channel. If it is nil the sheet is used as is.
+	Key color.Color
+	// Trim shrinks each glyph horizontally to its opaque pixels, turning
+	// a monospaced grid into a proportional font.
+	Trim bool
+	// LetterSpacing is the space in pixels added to the advance of
+	// trimmed glyphs. It is only used if Trim is set.
+	LetterSpacing int
+	// SpaceWidth is the advance in pixels of fully transparent cells like
+	// the space character if Trim is set. If it is 0, half the cell width
+	// is used.
+	SpaceWidth int
+	// Base is the distance in pixels from the top of a cell to the base
+	// line. If it is 0, the bottom of the cell is the base line.
+	Base int
+	// PageFile is the file name of the font's single page. If it is empty
+	// the page is named "page_0.png".
+	PageFile string
+}
+
+// FromGrid creates a bitmap font from a sprite sheet image with the glyphs
+// arranged in a grid of equally sized cells, or in a single row strip, as
+// many retro fonts are distributed. The font has a single page, the sheet.
+func FromGrid(sheet image.Image, opts *GridOptions) (*BitmapFont, error) {
+	if opts == nil || opts.CellSize.X <= 0 || opts.CellSize.Y <= 0 {
+		return nil, errors.New("bmfont: grid cell size is required")
+	}
+	if opts.Charset == "" {
+		return nil, errors.New("bmfont: grid charset is required")
+	}
+	cell := opts.CellSize
+	b := sheet.Bounds()
+	page := image.NewRGBA(image.Rectangle{Max: b.Size()})
+	draw.Draw(page, page.Bounds(), sheet, b.Min, draw.Src)
+	if opts.Key != nil {
+		removeKeyColor(page, opts.Key)
+	}
+	pitch := cell.Add(opts.Gap)
+	cols := (page.Rect.Dx() - opts.Margin.X + opts.Gap.X) / pitch.X
+	rows := (page.Rect.Dy() - opts.Margin.Y + opts.Gap.Y) / pitch.Y
+	if cols <= 0 || rows <= 0 {
+		return nil, fmt.Errorf("bmfont: sheet of size %dx%d has no cells of size %dx%d", b.Dx(), b.Dy(), cell.X, cell.Y)
+	}
+	base := opts.Base
+	if base <= 0 {
+		base = cell.Y
+	}
+	spaceWidth := opts.SpaceWidth
+	if spaceWidth <= 0 {
+		spaceWidth = cell.X / 2
+	}
+	pageFile := opts.PageFile
+	if pageFile == "" {
+		pageFile = "page_0.png"
+	}
+
+	desc := &Descriptor{
+		Info: Info{
+			Size:     cell.Y,
+			Unicode:  true,
+			StretchH: 100,
+			Spacing:  Spacing{Horizontal: opts.Gap.X, Vertical: opts.Gap.Y},
+		},
+		Common: Common{
+			LineHeight: cell.Y,
+			Base:       base,
+			ScaleW:     page.Rect.Dx(),
+			ScaleH:     page.Rect.Dy(),
+		},
+		Pages:   map[int]Page{0: {ID: 0, File: pageFile}},
+		Chars:   make(map[rune]Char),
+		Kerning: make(map[CharPair]Kerning),
+	}
+	i := 0
+	for _, r := range opts.Charset {
+		if i >= cols*rows {
+			return nil, fmt.Errorf("bmfont: charset has more characters than the %d cells of the sheet", cols*rows)
+		}
+		pos := opts.Margin.Add(image.Pt(i%cols*pitch.X, i/cols*pitch.Y))
+		i++
+		if _, dup := desc.Chars[r]; dup {
+			continue
+		}
+		ch := Char{
+			ID:       r,
+			X:        pos.X,
+			Y:        pos.Y,
+			Width:    cell.X,
+			Height:   cell.Y,
+			XAdvance: cell.X,
+			Channel:  All,
+		}
+		if opts.Trim {
+			opaque := opaqueBounds(page.SubImage(image.Rectangle{Min: pos, Max: pos.Add(cell)}).(*image.RGBA))
+			if opaque.Empty() {
+				ch.Width, ch.Height, ch.XAdvance = 0, 0, spaceWidth
+			} else {
+				ch.X = opaque.Min.X
+				ch.Width = opaque.Dx()
+				ch.XAdvance = ch.Width + opts.LetterSpacing
+			}
+		}
+		desc.Chars[r] = ch
+	}
+	return &BitmapFont{
+		Descriptor: desc,
+		PageSheets: map[int]image.Image{0: page},
+	}, nil
+}
+
+// removeKeyColor makes all pixels of the image with the key color fully
+// transparent.
+func removeKeyColor(img *image.RGBA, key color.Color) {
+	k := color.RGBAModel.Convert(key).(color.RGBA)
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		p := img.Pix[i : i+4 : i+4]
+		if p[0] == k.R && p[1] == k.G && p[2] == k.B {
+			p[0], p[1], p[2], p[3] = 0, 0, 0, 0
+		}
+	}
+}
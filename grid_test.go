@@ -0,0 +1,90 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+func TestFromGrid(t *testing.T) {
+	// A 2×2 grid of 4×4 cells with a margin and gap of 1 pixel on a magenta
+	// background. "A" fills its cell, "B" is 2 pixels wide with 1 pixel
+	// on its left, and the space is empty.
+	key := color.RGBA{R: 0xFF, B: 0xFF, A: 0xFF}
+	sheet := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(key), image.Point{}, draw.Src)
+	draw.Draw(sheet, image.Rect(1, 1, 5, 5), image.White, image.Point{}, draw.Src)
+	draw.Draw(sheet, image.Rect(7, 1, 9, 5), image.White, image.Point{}, draw.Src)
+	draw.Draw(sheet, image.Rect(6, 6, 10, 10), image.White, image.Point{}, draw.Src)
+
+	opts := &GridOptions{
+		CellSize: image.Pt(4, 4),
+		Charset:  "AB C",
+		Margin:   image.Pt(1, 1),
+		Gap:      image.Pt(1, 1),
+		Key:      key,
+		Base:     3,
+	}
+	f, err := FromGrid(sheet, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[rune]Char{
+		'A': {ID: 'A', X: 1, Y: 1, Width: 4, Height: 4, XAdvance: 4, Channel: All},
+		'B': {ID: 'B', X: 6, Y: 1, Width: 4, Height: 4, XAdvance: 4, Channel: All},
+		' ': {ID: ' ', X: 1, Y: 6, Width: 4, Height: 4, XAdvance: 4, Channel: All},
+		'C': {ID: 'C', X: 6, Y: 6, Width: 4, Height: 4, XAdvance: 4, Channel: All},
+	}
+	for r, ch := range want {
+		if got := f.Descriptor.Chars[r]; !reflect.DeepEqual(got, ch) {
+			t.Errorf("char %q: got %+v, want %+v", r, got, ch)
+		}
+	}
+	if c := f.Descriptor.Common; c.LineHeight != 4 || c.Base != 3 {
+		t.Errorf("got line height %d and base %d, want 4 and 3", c.LineHeight, c.Base)
+	}
+	page, _ := f.Page(0)
+	if _, _, _, a := page.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("key color pixel has alpha %#x, want it transparent", a)
+	}
+
+	opts.Trim, opts.LetterSpacing, opts.SpaceWidth = true, 1, 3
+	if f, err = FromGrid(sheet, opts); err != nil {
+		t.Fatal(err)
+	}
+	trimmed := map[rune]Char{
+		'A': {ID: 'A', X: 1, Y: 1, Width: 4, Height: 4, XAdvance: 5, Channel: All},
+		'B': {ID: 'B', X: 7, Y: 1, Width: 2, Height: 4, XAdvance: 3, Channel: All},
+		' ': {ID: ' ', X: 1, Y: 6, XAdvance: 3, Channel: All},
+	}
+	for r, ch := range trimmed {
+		if got := f.Descriptor.Chars[r]; !reflect.DeepEqual(got, ch) {
+			t.Errorf("trimmed char %q: got %+v, want %+v", r, got, ch)
+		}
+	}
+}
+
+func TestFromGridErrors(t *testing.T) {
+	sheet := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	tests := []struct {
+		name string
+		opts *GridOptions
+	}{
+		{"no options", nil},
+		{"no cell size", &GridOptions{Charset: "AB"}},
+		{"no charset", &GridOptions{CellSize: image.Pt(4, 4)}},
+		{"cells larger than sheet", &GridOptions{CellSize: image.Pt(16, 16), Charset: "A"}},
+		{"too many characters", &GridOptions{CellSize: image.Pt(4, 4), Charset: "ABC"}},
+	}
+	for _, tt := range tests {
+		if _, err := FromGrid(sheet, tt.opts); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// A Highlight is a box that is filled behind the text, e.g. for subtitles
+// and debug overlays, see Style.Highlight.
+type Highlight struct {
+	// Color is the fill color of the box. If it is nil a half transparent
+	// black is used.
+	Color color.Color
+	// Padding is the space in pixels between the bounding box of the text
+	// and the edges of the box, scaled like the glyphs.
+	Padding Padding
+	// PerLine fills a separate box behind each line of text, fitted to the
+	// bounding box of the line, instead of one box behind the whole text.
+	PerLine bool
+}
+
+func (s *Style) highlight() *Highlight {
+	if s == nil {
+		return nil
+	}
+	return s.Highlight
+}
+
+// highlightBox returns the box of the highlight of the style around text
+// with the given bounds, which may be empty if the style has no highlight.
+func (s *Style) highlightBox(text image.Rectangle) image.Rectangle {
+	h := s.highlight()
+	if h == nil || text.Empty() {
+		return image.Rectangle{}
+	}
+	k := s.scale()
+	pad := func(px int) int { return int(math.Round(float64(px) * k)) }
+	return image.Rect(
+		text.Min.X-pad(h.Padding.Left), text.Min.Y-pad(h.Padding.Up),
+		text.Max.X+pad(h.Padding.Right), text.Max.Y+pad(h.Padding.Down),
+	)
+}
+
+// highlightBoxes returns the boxes of the highlight of the style for the
+// layout as if it was drawn at position (0, 0): one around the bounds of the
+// whole text, including its shadow, like those reported by MeasureText, or
+// one for each line.
+func (l *layout) highlightBoxes(style *Style) []image.Rectangle {
+	if style.highlight() == nil {
+		return nil
+	}
+	lines := [][]line{l.lines}
+	if style.highlight().PerLine {
+		lines = lines[:0]
+		for _, ln := range l.lines {
+			lines = append(lines, []line{ln})
+		}
+	}
+	var boxes []image.Rectangle
+	for _, lns := range lines {
+		sub := *l
+		sub.lines = lns
+		b := sub.bounds()
+		b = b.Union(style.shadowBounds(b))
+		if box := style.highlightBox(b); !box.Empty() {
+			boxes = append(boxes, box)
+		}
+	}
+	return boxes
+}
+
+// drawHighlight fills the highlight boxes of the style for the layout drawn
+// at the given origin. Overlapping boxes are filled only once, so that they
+// don't add up for translucent colors.
+func (l *layout) drawHighlight(dst draw.Image, origin fixed.Point26_6, style *Style) {
+	boxes := l.highlightBoxes(style)
+	if len(boxes) == 0 {
+		return
+	}
+	dst = style.clip(dst)
+	pos := image.Pt(origin.X.Round(), origin.Y.Round())
+	var bounds image.Rectangle
+	for i := range boxes {
+		boxes[i] = boxes[i].Add(pos).Intersect(dst.Bounds())
+		bounds = bounds.Union(boxes[i])
+	}
+	if bounds.Empty() {
+		return
+	}
+	mask := image.NewAlpha(bounds)
+	for _, box := range boxes {
+		draw.Draw(mask, box, image.Opaque, image.Point{}, draw.Src)
+	}
+	c := style.highlight().Color
+	if c == nil {
+		c = color.NRGBA{A: 0x80}
+	}
+	draw.DrawMask(dst, bounds, image.NewUniform(style.fade(c)), image.Point{}, mask, bounds.Min, draw.Over)
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestHighlightBounds(t *testing.T) {
+	f := opaqueFont("AB")
+	pad := Padding{Up: 1, Right: 1, Down: 1, Left: 1}
+	tests := []struct {
+		opts []DrawOption
+		want image.Rectangle
+	}{
+		{[]DrawOption{WithHighlight(nil, pad, false)}, image.Rect(-1, -7, 10, 9)},
+		{[]DrawOption{WithHighlight(nil, pad, true)}, image.Rect(-1, -7, 10, 9)},
+		// The padding is scaled like the glyphs.
+		{[]DrawOption{WithHighlight(nil, pad, false), WithScale(2)}, image.Rect(-2, -14, 20, 18)},
+		// The box includes the shadow.
+		{[]DrawOption{WithHighlight(nil, Padding{}, false), WithShadow(image.Pt(2, 2), nil, 0)}, image.Rect(0, -6, 11, 10)},
+	}
+	for _, tt := range tests {
+		if got := f.MeasureTextOpts("AB\nA", tt.opts...); got != tt.want {
+			t.Errorf("got bounds %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestDrawHighlight(t *testing.T) {
+	f := opaqueFont("AB")
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	pad := Padding{Up: 2, Right: 2, Down: 2, Left: 2}
+	tests := []struct {
+		perLine bool
+		// right of the second line, below the first line, and where the
+		// boxes of the lines overlap
+		want [3]color.RGBA
+	}{
+		{false, [3]color.RGBA{blue, blue, blue}},
+		{true, [3]color.RGBA{{}, blue, blue}},
+	}
+	for _, tt := range tests {
+		dst := image.NewRGBA(image.Rect(0, 0, 20, 24))
+		f.DrawTextOpts(dst, image.Pt(4, 10), "AB\nA", WithHighlight(blue, pad, tt.perLine))
+		got := [3]color.RGBA{dst.RGBAAt(12, 16), dst.RGBAAt(10, 11), dst.RGBAAt(4, 11)}
+		if got != tt.want {
+			t.Errorf("per line %v: got %v, want %v", tt.perLine, got, tt.want)
+		}
+		if c := dst.RGBAAt(5, 6); c != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+			t.Errorf("per line %v: got %v, want the glyph drawn over the highlight", tt.perLine, c)
+		}
+	}
+
+	// Translucent boxes that overlap are filled only once.
+	dst := image.NewRGBA(image.Rect(0, 0, 20, 24))
+	f.DrawTextOpts(dst, image.Pt(4, 10), "AB\nA", WithHighlight(nil, pad, true))
+	if got := dst.RGBAAt(4, 11); got != (color.RGBA{A: 0x80}) {
+		t.Errorf("got %v where the boxes overlap, want a half transparent black", got)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// httpAttempts is the number of attempts HTTPSheets makes to fetch a page
+// sheet image, and httpRetryDelay is the delay before the first retry,
+// which doubles with each further retry.
+const (
+	httpAttempts   = 3
+	httpRetryDelay = 200 * time.Millisecond
+)
+
+// HTTPSheets returns a SheetReaderFunc for Read that fetches the page sheet
+// images via HTTP GET requests with the given client, e.g. from a CDN. The
+// file names of the pages are resolved relative to the base URL, which
+// denotes a directory. File names that are absolute URLs or paths, or that
+// resolve to a location outside of the base URL, are rejected with an error,
+// so that a descriptor can't direct requests to other hosts. If client is
+// nil, http.DefaultClient is used. The requests, including the delays before
+// retries, are cancelled with the context.
+//
+// Requests that fail with a network error or with a server error or "too
+// many requests" status are retried up to two times with increasing delays.
+// Responses with another status than 200 OK and responses with a Content-Type
+// that is neither an image type nor generic binary data, i.e.
+// application/octet-stream or binary/octet-stream, e.g. an HTML error page,
+// are rejected with an error.
+func HTTPSheets(ctx context.Context, baseURL string, client *http.Client) SheetReaderFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return func(filename string) (io.ReadCloser, error) {
+		base, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		ref, err := url.Parse(filepath.ToSlash(filename))
+		if err != nil {
+			return nil, err
+		}
+		u, err := resolveSheetURL(base, ref)
+		if err != nil {
+			return nil, fmt.Errorf("bmfont: sheet %q: %w", filename, err)
+		}
+		return fetchSheet(ctx, client, u.String())
+	}
+}
+
+// resolveSheetURL resolves the reference to a page sheet image relative to
+// the base URL. The reference must be a relative path that stays within the
+// directory of the base URL.
+func resolveSheetURL(base, ref *url.URL) (*url.URL, error) {
+	if ref.IsAbs() || ref.Host != "" || ref.Opaque != "" || strings.HasPrefix(ref.Path, "/") {
+		return nil, errors.New("not a relative path")
+	}
+	u := base.ResolveReference(ref)
+	if u.Scheme != base.Scheme || u.Host != base.Host || !strings.HasPrefix(u.Path, base.Path) {
+		return nil, fmt.Errorf("outside of %s", base)
+	}
+	return u, nil
+}
+
+// fetchSheet fetches the page sheet image at the URL, with retries.
+func fetchSheet(ctx context.Context, client *http.Client, u string) (io.ReadCloser, error) {
+	delay := httpRetryDelay
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		retry := err != nil && ctx.Err() == nil
+		if err == nil {
+			if resp.StatusCode == http.StatusOK {
+				if err := checkSheetContentType(u, resp.Header.Get("Content-Type")); err != nil {
+					resp.Body.Close()
+					return nil, err
+				}
+				return resp.Body, nil
+			}
+			resp.Body.Close()
+			retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+			err = fmt.Errorf("bmfont: fetching sheet %s: %s", u, resp.Status)
+		}
+		if !retry || attempt == httpAttempts {
+			return nil, err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// checkSheetContentType checks that the content type of a fetched page
+// sheet image is an image type or generic binary data, as served e.g. by
+// Amazon S3 with binary/octet-stream. A missing content type is accepted.
+func checkSheetContentType(u, contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("bmfont: fetching sheet %s: invalid Content-Type %q", u, contentType)
+	}
+	if strings.HasPrefix(mediaType, "image/") || mediaType == "application/octet-stream" || mediaType == "binary/octet-stream" {
+		return nil
+	}
+	return fmt.Errorf("bmfont: fetching sheet %s: unexpected Content-Type %q", u, mediaType)
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPSheetsRejectsOutsideBase(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+	sheets := HTTPSheets(context.Background(), srv.URL+"/fonts/", srv.Client())
+	for _, filename := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"//example.com/sheet.png",
+		"/etc/passwd",
+		"../secret.png",
+		"a/../../secret.png",
+		"mailto:sheet.png",
+	} {
+		if rc, err := sheets(filename); err == nil {
+			rc.Close()
+			t.Errorf("sheet %q: expected error", filename)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("got %d requests, want 0", n)
+	}
+}
+
+func TestHTTPSheetsContentType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fonts/s3.png":
+			w.Header().Set("Content-Type", "binary/octet-stream")
+		case "/fonts/error.png":
+			w.Header().Set("Content-Type", "text/html")
+		default:
+			w.Header().Set("Content-Type", "image/png")
+		}
+		io.WriteString(w, "data")
+	}))
+	defer srv.Close()
+	sheets := HTTPSheets(context.Background(), srv.URL+"/fonts", srv.Client())
+	for filename, wantErr := range map[string]bool{
+		"sheet.png":     false,
+		"pages/0.png":   false,
+		"s3.png":        false,
+		"error.png":     true,
+		"a/../s3.png":   false,
+		"sheet 0.png":   false,
+		"./sheet_0.png": false,
+	} {
+		rc, err := sheets(filename)
+		if (err != nil) != wantErr {
+			t.Errorf("sheet %q: got error %v, want error: %v", filename, err, wantErr)
+		}
+		if err == nil {
+			rc.Close()
+		}
+	}
+}
+
+func TestHTTPSheetsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	_, err := HTTPSheets(ctx, srv.URL, srv.Client())("sheet.png")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
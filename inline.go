@@ -0,0 +1,109 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"strings"
+
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/cases"
+)
+
+// An InlineImage is an image that is embedded in text like a glyph, e.g. an
+// icon in an item name or a button prompt, see Style.Images. It is scaled
+// like the glyphs, but not tinted with the color of the text.
+type InlineImage struct {
+	Image image.Image
+	// Descent is the number of pixels of the image below the base line of
+	// the text. If it is 0 the bottom of the image is on the base line.
+	Descent int
+	// Advance is the horizontal advance of the image in pixels. If it is 0
+	// the width of the image is used.
+	Advance int
+}
+
+// objectReplacement is the rune of the glyphs of inline images, U+FFFC
+// OBJECT REPLACEMENT CHARACTER.
+const objectReplacement = '\uFFFC'
+
+// inlineImagePrefix starts the placeholder of an inline image in the text,
+// which is followed by the name of the image and a closing brace.
+const inlineImagePrefix = "{icon:"
+
+func (s *Style) images() map[string]*InlineImage {
+	if s == nil {
+		return nil
+	}
+	return s.Images
+}
+
+// inlineImage returns the inline image of the style whose placeholder is at
+// the start of the text, and the length of the placeholder.
+func (s *Style) inlineImage(text string) (img *InlineImage, size int, ok bool) {
+	name, ok := strings.CutPrefix(text, inlineImagePrefix)
+	if !ok {
+		return nil, 0, false
+	}
+	n := strings.IndexByte(name, '}')
+	if n < 0 {
+		return nil, 0, false
+	}
+	img = s.images()[name[:n]]
+	if img == nil || img.Image == nil {
+		return nil, 0, false
+	}
+	return img, len(inlineImagePrefix) + n + 1, true
+}
+
+// imageGlyph returns the glyph for an inline image at the byte offset of its
+// placeholder in the text and at the pen position x, scaled by the factor s.
+// Its character refers to the bounds of the image instead of a page sheet.
+func (f *BitmapFont) imageGlyph(img *InlineImage, offset int, x fixed.Int26_6, s float64) glyph {
+	b := img.Image.Bounds()
+	advance := img.Advance
+	if advance == 0 {
+		advance = b.Dx()
+	}
+	return glyph{
+		r:      objectReplacement,
+		offset: offset,
+		char: Char{
+			ID:       objectReplacement,
+			X:        b.Min.X,
+			Y:        b.Min.Y,
+			Width:    b.Dx(),
+			Height:   b.Dy(),
+			YOffset:  f.Descriptor.Common.Base + img.Descent - b.Dy(),
+			XAdvance: advance,
+		},
+		dot:     fixed.Point26_6{X: x},
+		advance: scaled(advance, s),
+		scale:   s,
+		font:    f,
+		image:   img,
+	}
+}
+
+// transformOutsideImages applies the case transformation to the text except
+// for the placeholders of inline images, whose names are kept.
+func transformOutsideImages(c cases.Caser, text string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, inlineImagePrefix)
+		if i < 0 {
+			break
+		}
+		n := strings.IndexByte(text[i:], '}')
+		if n < 0 {
+			break
+		}
+		b.WriteString(c.String(text[:i]))
+		b.WriteString(text[i : i+n+1])
+		text = text[i+n+1:]
+	}
+	b.WriteString(c.String(text))
+	return b.String()
+}
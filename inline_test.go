@@ -0,0 +1,79 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// testIcon returns a 3×3 inline image filled with the given color that
+// extends 1 pixel below the base line.
+func testIcon(c color.Color) *InlineImage {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 3))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return &InlineImage{Image: img, Descent: 1}
+}
+
+func TestInlineImageQuads(t *testing.T) {
+	f := testFont("AB?", 1)
+	coin := testIcon(color.White)
+	style := &Style{Images: map[string]*InlineImage{"coin": coin}}
+	quads := f.Quads("A{icon:coin}B", style)
+	want := []Quad{
+		{Rune: 'A', Src: image.Rect(0, 0, 4, 6), Dst: image.Rect(0, -6, 4, 0)},
+		{Rune: objectReplacement, Src: image.Rect(0, 0, 3, 3), Dst: image.Rect(5, -2, 8, 1), Image: coin.Image},
+		{Rune: 'B', Src: image.Rect(4, 0, 8, 6), Dst: image.Rect(8, -6, 12, 0)},
+	}
+	if !slices.Equal(quads, want) {
+		t.Errorf("got quads %+v, want %+v", quads, want)
+	}
+
+	// The advance overrides the width of the image.
+	coin.Advance = 5
+	if got := f.Quads("{icon:coin}A", style)[1].Dst.Min.X; got != 5 {
+		t.Errorf("got A at x %d after the image, want 5", got)
+	}
+	// Unknown placeholders are drawn as text.
+	if got := len(f.Quads("{icon:gem}", style)); got != len("{icon:gem}") {
+		t.Errorf("got %d quads for an unknown placeholder, want a glyph per character", got)
+	}
+}
+
+func TestInlineImageLayout(t *testing.T) {
+	f := testFont("AB ", 1)
+	style := &Style{Images: map[string]*InlineImage{"coin": testIcon(color.White)}, MaxWidth: 10}
+	l := f.layout("AB {icon:coin}", style, nil)
+	if got, want := layoutRunes(l), []string{"AB", "\uFFFC"}; !slices.Equal(got, want) {
+		t.Errorf("got lines %q, want %q", got, want)
+	}
+	// The names of the placeholders are not case transformed.
+	style.Case = UpperCase
+	style.MaxWidth = 0
+	if got := layoutRunes(f.layout("a{icon:coin}", style, nil))[0]; got != "A\uFFFC" {
+		t.Errorf("got glyphs %q, want %q", got, "A\uFFFC")
+	}
+}
+
+func TestDrawInlineImage(t *testing.T) {
+	f := opaqueFont("A")
+	green := color.RGBA{G: 0xff, A: 0xff}
+	red := color.RGBA{R: 0xff, A: 0xff}
+	style := &Style{Color: red, Images: map[string]*InlineImage{"gem": testIcon(green)}}
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	f.DrawTextFixed(dst, fixed.P(0, 6), "A{icon:gem}", style)
+	if got := dst.RGBAAt(1, 1); got != red {
+		t.Errorf("got glyph color %v, want %v", got, red)
+	}
+	// The image is not tinted.
+	if got := dst.RGBAAt(6, 6); got != green {
+		t.Errorf("got image color %v, want %v", got, green)
+	}
+}
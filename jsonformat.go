@@ -0,0 +1,511 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReadDescriptorJSON parses font descriptor data in the JSON format commonly
+// used by web pipelines, as produced by converters like bmfont2json and by
+// msdf-bmfont-xml, from a reader. The top-level object has the members
+// "info", "common", "pages" (a list of file names), "chars" and "kernings",
+// whose members are named like the attributes of the text format. Flags may
+// be given as numbers or as booleans. Unknown members of the info, common,
+// char and kerning objects are kept in the Extra fields: strings with their
+// values and other JSON values as their JSON text.
+//
+// ReadDescriptor and the other readers of the text format detect JSON input
+// automatically, so this function is only needed to reject text input.
+func ReadDescriptorJSON(r io.Reader) (*Descriptor, error) {
+	return parseDescriptorJSON(newTextReader(r), nil)
+}
+
+// isJSON reports whether the buffered input starts with a JSON object,
+// after optional white space.
+func isJSON(br *bufio.Reader) bool {
+	b, _ := br.Peek(512)
+	b = bytes.TrimLeft(b, " \t\r\n")
+	return len(b) > 0 && b[0] == '{'
+}
+
+type jsonDescriptor struct {
+	Info          jsonInfo        `json:"info"`
+	Common        jsonCommon      `json:"common"`
+	Pages         []string        `json:"pages"`
+	Chars         []jsonChar      `json:"chars"`
+	Kernings      []jsonKerning   `json:"kernings"`
+	DistanceField json.RawMessage `json:"distanceField,omitempty"`
+	KernClasses   []jsonKernClass `json:"kernclasses,omitempty"`
+	ClassKernings []jsonKerning   `json:"classkernings,omitempty"`
+}
+
+type jsonInfo struct {
+	Face     string      `json:"face"`
+	Size     int         `json:"size"`
+	Bold     jsonFlag    `json:"bold"`
+	Italic   jsonFlag    `json:"italic"`
+	Charset  jsonCharset `json:"charset"`
+	Unicode  jsonFlag    `json:"unicode"`
+	StretchH int         `json:"stretchH"`
+	Smooth   jsonFlag    `json:"smooth"`
+	AA       int         `json:"aa"`
+	Padding  []int       `json:"padding"`
+	Spacing  []int       `json:"spacing"`
+	Outline  *int        `json:"outline"`
+
+	Languages []string `json:"languages,omitempty"`
+	Scripts   []string `json:"scripts,omitempty"`
+	Usage     string   `json:"usage,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+
+	Extra map[string]string `json:"-"`
+}
+
+type jsonCommon struct {
+	LineHeight *int      `json:"lineHeight"`
+	Base       *int      `json:"base"`
+	ScaleW     int       `json:"scaleW"`
+	ScaleH     int       `json:"scaleH"`
+	Pages      int       `json:"pages"`
+	Packed     *jsonFlag `json:"packed"`
+	AlphaChnl  *int      `json:"alphaChnl"`
+	RedChnl    *int      `json:"redChnl"`
+	GreenChnl  *int      `json:"greenChnl"`
+	BlueChnl   *int      `json:"blueChnl"`
+
+	Extra map[string]string `json:"-"`
+}
+
+type jsonChar struct {
+	ID       *int   `json:"id"`
+	Index    int    `json:"index,omitempty"`
+	Char     string `json:"char"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	XOffset  int    `json:"xoffset"`
+	YOffset  int    `json:"yoffset"`
+	XAdvance int    `json:"xadvance"`
+	Page     int    `json:"page"`
+	Chnl     *int   `json:"chnl"`
+
+	Extra map[string]string `json:"-"`
+}
+
+type jsonKerning struct {
+	First  int `json:"first"`
+	Second int `json:"second"`
+	Amount int `json:"amount"`
+
+	Extra map[string]string `json:"-"`
+}
+
+type jsonKernClass struct {
+	ID    int    `json:"id"`
+	Side  string `json:"side"`
+	Chars string `json:"chars"`
+}
+
+func (ji *jsonInfo) UnmarshalJSON(data []byte) error {
+	type plain jsonInfo
+	return unmarshalExtra(data, (*plain)(ji), &ji.Extra)
+}
+
+func (ji jsonInfo) MarshalJSON() ([]byte, error) {
+	type plain jsonInfo
+	return marshalExtra(plain(ji), ji.Extra)
+}
+
+func (jc *jsonCommon) UnmarshalJSON(data []byte) error {
+	type plain jsonCommon
+	return unmarshalExtra(data, (*plain)(jc), &jc.Extra)
+}
+
+func (jc jsonCommon) MarshalJSON() ([]byte, error) {
+	type plain jsonCommon
+	return marshalExtra(plain(jc), jc.Extra)
+}
+
+func (jch *jsonChar) UnmarshalJSON(data []byte) error {
+	type plain jsonChar
+	return unmarshalExtra(data, (*plain)(jch), &jch.Extra)
+}
+
+func (jch jsonChar) MarshalJSON() ([]byte, error) {
+	type plain jsonChar
+	return marshalExtra(plain(jch), jch.Extra)
+}
+
+func (jk *jsonKerning) UnmarshalJSON(data []byte) error {
+	type plain jsonKerning
+	return unmarshalExtra(data, (*plain)(jk), &jk.Extra)
+}
+
+func (jk jsonKerning) MarshalJSON() ([]byte, error) {
+	type plain jsonKerning
+	return marshalExtra(plain(jk), jk.Extra)
+}
+
+// unmarshalExtra decodes a JSON object into the struct pointed to by v and
+// collects the members that have no field in the struct in *extra, with
+// the values of strings and the JSON text of other values.
+func unmarshalExtra(data []byte, v any, extra *map[string]string) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	known := jsonMemberNames(reflect.TypeOf(v).Elem())
+	for name, raw := range members {
+		if known[strings.ToLower(name)] {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw)
+		}
+		if *extra == nil {
+			*extra = make(map[string]string)
+		}
+		(*extra)[name] = value
+	}
+	return nil
+}
+
+// marshalExtra encodes the struct v as a JSON object followed by the extra
+// members sorted by their names. Integer values are written as numbers,
+// all others as strings.
+func marshalExtra(v any, extra map[string]string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	data = data[:len(data)-1] // strip the closing brace
+	for _, name := range names {
+		if len(data) > 1 {
+			data = append(data, ',')
+		}
+		data = strconv.AppendQuote(data, name)
+		data = append(data, ':')
+		value := extra[name]
+		if _, err := strconv.Atoi(value); err == nil {
+			data = append(data, value...)
+		} else {
+			b, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, b...)
+		}
+	}
+	return append(data, '}'), nil
+}
+
+// jsonMemberNames returns the lower-case JSON member names of the fields of
+// a struct type, which encoding/json matches case-insensitively.
+func jsonMemberNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[strings.ToLower(name)] = true
+		}
+	}
+	return names
+}
+
+// hasExtra reports whether any of the objects of the JSON descriptor has
+// unknown members.
+func (jd *jsonDescriptor) hasExtra() bool {
+	if len(jd.Info.Extra) > 0 || len(jd.Common.Extra) > 0 {
+		return true
+	}
+	for _, jch := range jd.Chars {
+		if len(jch.Extra) > 0 {
+			return true
+		}
+	}
+	for _, k := range jd.Kernings {
+		if len(k.Extra) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// A jsonFlag is a flag given as a number or as a boolean.
+type jsonFlag int
+
+func (f *jsonFlag) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*f = 0
+		if b {
+			*f = 1
+		}
+		return nil
+	}
+	return json.Unmarshal(data, (*int)(f))
+}
+
+// A jsonCharset is the charset of the info object, which msdf-bmfont-xml
+// replaces with the list of characters of the font. Such lists are ignored.
+type jsonCharset string
+
+func (c *jsonCharset) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		*c = ""
+		return nil
+	}
+	return json.Unmarshal(data, (*string)(c))
+}
+
+func parseDescriptorJSON(r io.Reader, opts *ParseOptions) (*Descriptor, error) {
+	dec := json.NewDecoder(r)
+	if opts.disallowUnknown() {
+		dec.DisallowUnknownFields()
+	}
+	var jd jsonDescriptor
+	if err := dec.Decode(&jd); err != nil {
+		return nil, fmt.Errorf("bmfont: invalid JSON descriptor: %w", err)
+	}
+	if opts.disallowUnknown() && jd.hasExtra() {
+		// The decoder doesn't check the objects with their own decoding.
+		return nil, errors.New("bmfont: JSON descriptor has members not defined by the format")
+	}
+	desc, err := jd.descriptor()
+	if err != nil {
+		return nil, err
+	}
+	opts.remap(desc)
+	return desc, nil
+}
+
+func (jd *jsonDescriptor) descriptor() (*Descriptor, error) {
+	if jd.Common.LineHeight == nil || jd.Common.Base == nil {
+		return nil, errors.New("bmfont: JSON descriptor is missing required common members lineHeight and base")
+	}
+	ji, jc := &jd.Info, &jd.Common
+	desc := &Descriptor{
+		Info: Info{
+			Face:     ji.Face,
+			Size:     ji.Size,
+			Bold:     ji.Bold != 0,
+			Italic:   ji.Italic != 0,
+			Charset:  string(ji.Charset),
+			Unicode:  ji.Unicode != 0,
+			StretchH: ji.StretchH,
+			Smooth:   ji.Smooth != 0,
+			AA:       ji.AA,
+			Padding:  paddingFrom(append(ji.Padding, 0, 0, 0, 0)),
+			Spacing:  spacingFrom(append(ji.Spacing, 0, 0)),
+			Outline:  deref(ji.Outline),
+
+			Languages: ji.Languages,
+			Scripts:   ji.Scripts,
+			Usage:     ji.Usage,
+			Notes:     ji.Notes,
+
+			Extra: ji.Extra,
+		},
+		Common: Common{
+			LineHeight:   *jc.LineHeight,
+			Base:         *jc.Base,
+			ScaleW:       jc.ScaleW,
+			ScaleH:       jc.ScaleH,
+			Packed:       deref(jc.Packed) != 0,
+			AlphaChannel: ChannelInfo(deref(jc.AlphaChnl)),
+			RedChannel:   ChannelInfo(deref(jc.RedChnl)),
+			GreenChannel: ChannelInfo(deref(jc.GreenChnl)),
+			BlueChannel:  ChannelInfo(deref(jc.BlueChnl)),
+			Extra:        jc.Extra,
+		},
+		Pages:   make(map[int]Page, len(jd.Pages)),
+		Chars:   make(map[rune]Char, len(jd.Chars)),
+		Kerning: make(map[CharPair]Kerning, len(jd.Kernings)),
+	}
+	if ji.Outline != nil {
+		desc.Features |= FeatureOutline
+	}
+	if jc.Packed != nil || jc.AlphaChnl != nil || jc.RedChnl != nil || jc.GreenChnl != nil || jc.BlueChnl != nil {
+		desc.Features |= FeatureChannelInfo
+	}
+	if len(jd.DistanceField) > 0 {
+		desc.Features |= FeatureDistanceField
+	}
+	for id, file := range jd.Pages {
+		desc.Pages[id] = Page{ID: id, File: file}
+	}
+	for i, jch := range jd.Chars {
+		if jch.ID == nil {
+			return nil, fmt.Errorf("bmfont: JSON descriptor char %d is missing required member id", i)
+		}
+		ch := Char{
+			ID:       rune(*jch.ID),
+			X:        jch.X,
+			Y:        jch.Y,
+			Width:    jch.Width,
+			Height:   jch.Height,
+			XOffset:  jch.XOffset,
+			YOffset:  jch.YOffset,
+			XAdvance: jch.XAdvance,
+			Page:     jch.Page,
+			Channel:  Channel(deref(jch.Chnl)),
+			Letter:   jch.Char,
+			Extra:    jch.Extra,
+		}
+		if jch.Chnl != nil {
+			desc.Features |= FeatureCharChannels
+		}
+		if _, ok := desc.Chars[ch.ID]; ok {
+			desc.addDuplicate(Finding{Char: ch.ID}, "char %s: duplicate ID (char %d)", runeName(ch.ID), i)
+		}
+		desc.Chars[ch.ID] = ch
+	}
+	for i, k := range jd.Kernings {
+		pair := CharPair{First: rune(k.First), Second: rune(k.Second)}
+		if _, ok := desc.Kerning[pair]; ok {
+			desc.addDuplicate(Finding{Pair: pair}, "kerning %s %s: duplicate pair (kerning %d)",
+				runeName(pair.First), runeName(pair.Second), i)
+		}
+		desc.Kerning[pair] = Kerning{Amount: k.Amount, Extra: k.Extra}
+	}
+	for _, kc := range jd.KernClasses {
+		t := tag{name: "kernclass", attrs: map[string]string{
+			"id": strconv.Itoa(kc.ID), "side": kc.Side, "chars": kc.Chars,
+		}}
+		if err := desc.addKerningClass(&t); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range jd.ClassKernings {
+		if desc.ClassKerning == nil {
+			desc.ClassKerning = make(map[ClassPair]Kerning)
+		}
+		desc.ClassKerning[ClassPair{First: k.First, Second: k.Second}] = Kerning{Amount: k.Amount}
+	}
+	return desc, nil
+}
+
+// WriteJSON writes the descriptor in the JSON format read by
+// ReadDescriptorJSON to w, e.g. for web front-ends like PixiJS and Phaser.
+// The characters and kerning pairs are written sorted by their IDs.
+// Extensions like class-based kerning and localization metadata are written
+// as additional members, and so are the unknown attributes kept in the Extra
+// fields, except for those of the pages, which are written as a list of file
+// names.
+func (d *Descriptor) WriteJSON(w io.Writer) error {
+	i, c := d.Info, d.Common
+	jd := jsonDescriptor{
+		Info: jsonInfo{
+			Face:     i.Face,
+			Size:     i.Size,
+			Bold:     jsonFlag(boolInt(i.Bold)),
+			Italic:   jsonFlag(boolInt(i.Italic)),
+			Charset:  jsonCharset(i.Charset),
+			Unicode:  jsonFlag(boolInt(i.Unicode)),
+			StretchH: i.StretchH,
+			Smooth:   jsonFlag(boolInt(i.Smooth)),
+			AA:       i.AA,
+			Padding:  []int{i.Padding.Up, i.Padding.Right, i.Padding.Down, i.Padding.Left},
+			Spacing:  []int{i.Spacing.Horizontal, i.Spacing.Vertical},
+			Outline:  &i.Outline,
+
+			Languages: i.Languages,
+			Scripts:   i.Scripts,
+			Usage:     i.Usage,
+			Notes:     i.Notes,
+
+			Extra: i.Extra,
+		},
+		Common: jsonCommon{
+			LineHeight: &c.LineHeight,
+			Base:       &c.Base,
+			ScaleW:     c.ScaleW,
+			ScaleH:     c.ScaleH,
+			Pages:      len(d.Pages),
+			Packed:     ptr(jsonFlag(boolInt(c.Packed))),
+			AlphaChnl:  ptr(int(c.AlphaChannel)),
+			RedChnl:    ptr(int(c.RedChannel)),
+			GreenChnl:  ptr(int(c.GreenChannel)),
+			BlueChnl:   ptr(int(c.BlueChannel)),
+			Extra:      c.Extra,
+		},
+		Pages:    make([]string, 0, len(d.Pages)),
+		Chars:    make([]jsonChar, 0, len(d.Chars)),
+		Kernings: make([]jsonKerning, 0, len(d.Kerning)),
+	}
+	for _, id := range sortedKeys(d.Pages) {
+		jd.Pages = append(jd.Pages, d.Pages[id].File)
+	}
+	for _, id := range sortedKeys(d.Chars) {
+		ch := d.Chars[id]
+		jd.Chars = append(jd.Chars, jsonChar{
+			ID:       ptr(int(ch.ID)),
+			Char:     string(ch.ID),
+			X:        ch.X,
+			Y:        ch.Y,
+			Width:    ch.Width,
+			Height:   ch.Height,
+			XOffset:  ch.XOffset,
+			YOffset:  ch.YOffset,
+			XAdvance: ch.XAdvance,
+			Page:     ch.Page,
+			Chnl:     ptr(int(ch.Channel)),
+			Extra:    ch.Extra,
+		})
+	}
+	for _, pair := range d.kerningPairs() {
+		jd.Kernings = append(jd.Kernings, jsonKerning{
+			First:  int(pair.First),
+			Second: int(pair.Second),
+			Amount: d.Kerning[pair].Amount,
+			Extra:  d.Kerning[pair].Extra,
+		})
+	}
+	for _, kc := range d.kerningClassList() {
+		jd.KernClasses = append(jd.KernClasses, jsonKernClass{ID: kc.id, Side: kc.side, Chars: kc.chars})
+	}
+	for _, pair := range d.classKerningPairs() {
+		jd.ClassKernings = append(jd.ClassKernings, jsonKerning{
+			First:  pair.First,
+			Second: pair.Second,
+			Amount: d.ClassKerning[pair].Amount,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jd)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
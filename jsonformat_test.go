@@ -0,0 +1,179 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	input := strings.Replace(testDescriptor, "outline=0", `outline=0 distanceRange=4 tool="gen 2"`, 1)
+	input = strings.Replace(input, "chnl=15\n", "chnl=15 glyph=7\n", 1)
+	input = strings.Replace(input, "amount=-1", "amount=-1 source=\"gpos\"", 1)
+	want, err := ReadDescriptor(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := want.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadDescriptorJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Info.Face != want.Info.Face || got.Info.Padding != want.Info.Padding || got.Common.LineHeight != want.Common.LineHeight {
+		t.Errorf("got info %+v and common %+v, want %+v and %+v", got.Info, got.Common, want.Info, want.Common)
+	}
+	if !maps.Equal(got.Info.Extra, want.Info.Extra) {
+		t.Errorf("got info extra %v, want %v", got.Info.Extra, want.Info.Extra)
+	}
+	for id, ch := range want.Chars {
+		g := got.Chars[id]
+		if g.X != ch.X || g.Width != ch.Width || g.XAdvance != ch.XAdvance || g.Channel != ch.Channel || !maps.Equal(g.Extra, ch.Extra) {
+			t.Errorf("char %U: got %+v, want %+v", id, g, ch)
+		}
+	}
+	pair := CharPair{First: 'A', Second: 'B'}
+	if g, w := got.Kerning[pair], want.Kerning[pair]; g.Amount != w.Amount || !maps.Equal(g.Extra, w.Extra) {
+		t.Errorf("got kerning %+v, want %+v", g, w)
+	}
+}
+
+func TestJSONDisallowUnknown(t *testing.T) {
+	input := `{"common": {"lineHeight": 8, "base": 6, "distanceRange": 4}, "chars": [], "kernings": []}`
+	opts := &ParseOptions{DisallowUnknown: true}
+	if _, err := opts.ReadDescriptor(strings.NewReader(input)); err == nil {
+		t.Error("unknown member was accepted")
+	}
+	d, err := ReadDescriptor(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Common.Extra["distanceRange"]; got != "4" {
+		t.Errorf("got distanceRange %q, want %q", got, "4")
+	}
+}
+
+func TestReadDescriptorJSON(t *testing.T) {
+	// The schema of msdf-bmfont-xml and other converters.
+	input := `
+	{
+		"pages": ["font_0.png", "font_1.png"],
+		"chars": [
+			{"id": 65, "index": 36, "char": "A", "width": 4, "height": 6, "xoffset": 0, "yoffset": 1, "xadvance": 5, "chnl": 15, "x": 0, "y": 0, "page": 0},
+			{"id": 66, "index": 37, "char": "B", "width": 4, "height": 6, "xoffset": 0, "yoffset": 1, "xadvance": 5, "chnl": 15, "x": 0, "y": 0, "page": 1}
+		],
+		"info": {
+			"face": "Roboto", "size": 8, "bold": 0, "italic": true,
+			"charset": ["A", "B"], "unicode": 1, "stretchH": 100, "smooth": 1, "aa": 1,
+			"padding": [1, 2, 3, 4], "spacing": [1, 1]
+		},
+		"common": {"lineHeight": 10, "base": 8, "scaleW": 64, "scaleH": 32, "pages": 2, "packed": 0, "alphaChnl": 0, "redChnl": 0, "greenChnl": 0, "blueChnl": 0},
+		"kernings": [{"first": 65, "second": 66, "amount": -1}]
+	}`
+	for name, read := range map[string]func(io.Reader) (*Descriptor, error){
+		"ReadDescriptorJSON": ReadDescriptorJSON,
+		"ReadDescriptor":     ReadDescriptor,
+	} {
+		d, err := read(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		i := d.Info
+		if i.Face != "Roboto" || i.Bold || !i.Italic || i.Charset != "" || !i.Unicode || i.Padding != (Padding{Up: 1, Right: 2, Down: 3, Left: 4}) {
+			t.Errorf("%s: got info %+v", name, i)
+		}
+		if c := d.Common; c.LineHeight != 10 || c.Base != 8 || c.ScaleW != 64 || c.ScaleH != 32 {
+			t.Errorf("%s: got common %+v", name, c)
+		}
+		if d.Pages[1].File != "font_1.png" || len(d.Pages) != 2 {
+			t.Errorf("%s: got pages %v", name, d.Pages)
+		}
+		if ch := d.Chars['B']; ch.Page != 1 || ch.YOffset != 1 || ch.XAdvance != 5 || ch.Letter != "B" || ch.Channel != All {
+			t.Errorf("%s: got char B %+v", name, ch)
+		}
+		if k := d.Kerning[CharPair{First: 'A', Second: 'B'}]; k.Amount != -1 {
+			t.Errorf("%s: got kerning amount %d, want -1", name, k.Amount)
+		}
+	}
+}
+
+func TestReadDescriptorJSONErrors(t *testing.T) {
+	tests := []struct {
+		name, input string
+	}{
+		{"text format", testDescriptor},
+		{"missing line height", `{"common": {"base": 6}, "chars": []}`},
+		{"missing char id", `{"common": {"lineHeight": 8, "base": 6}, "chars": [{"x": 1}]}`},
+		{"malformed", `{"common": {"lineHeight": "8"}}`},
+	}
+	for _, tt := range tests {
+		if _, err := ReadDescriptorJSON(strings.NewReader(tt.input)); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	input := testDescriptor + `kernclass id=1 side="first" chars="A"
+kernclass id=2 side="second" chars="B"
+classkerning first=1 second=2 amount=-2
+`
+	d, err := ReadDescriptor(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Info.Languages = []string{"en"}
+	var buf bytes.Buffer
+	if err := d.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The output follows the community schema read by PixiJS and Phaser.
+	var doc struct {
+		Pages []string `json:"pages"`
+		Chars []struct {
+			ID   int    `json:"id"`
+			Char string `json:"char"`
+		} `json:"chars"`
+		Info struct {
+			Padding []int `json:"padding"`
+		} `json:"info"`
+		Kernings []struct {
+			First, Second, Amount int
+		} `json:"kernings"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(doc.Pages, []string{"sheet.png"}) || len(doc.Info.Padding) != 4 {
+		t.Errorf("got pages %q and padding %v", doc.Pages, doc.Info.Padding)
+	}
+	if len(doc.Chars) != 2 || doc.Chars[0].ID != 'A' || doc.Chars[0].Char != "A" || doc.Chars[1].ID != 'B' {
+		t.Errorf("got chars %+v, want A and B sorted by ID", doc.Chars)
+	}
+	if len(doc.Kernings) != 1 || doc.Kernings[0].First != 'A' || doc.Kernings[0].Amount != -1 {
+		t.Errorf("got kernings %+v", doc.Kernings)
+	}
+
+	// The extensions survive a round trip.
+	got, err := ReadDescriptorJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Info.Languages, []string{"en"}) {
+		t.Errorf("got languages %q, want [en]", got.Info.Languages)
+	}
+	if got.KerningClasses['A'].First != 1 || got.KerningClasses['B'].Second != 2 || got.ClassKerning[ClassPair{First: 1, Second: 2}].Amount != -2 {
+		t.Errorf("got kerning classes %v and class kerning %v", got.KerningClasses, got.ClassKerning)
+	}
+}
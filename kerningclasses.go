@@ -0,0 +1,138 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// CharClasses are the kerning classes of a character, used if it is the
+// first and if it is the second character of a pair. The classes of the two
+// sides are independent, because characters that share kerning values on
+// their left side often differ on their right side. Class ID 0 means that
+// the character belongs to no class on this side.
+type CharClasses struct {
+	First, Second int
+}
+
+// A ClassPair is a pair of kerning class IDs. It is used as the key for the
+// class-based kerning map.
+type ClassPair struct {
+	First, Second int
+}
+
+// kerning returns the kerning amount for the character pair, either from
+// the kerning pairs or, if the pair has no entry, from the kerning classes
+// of the characters.
+func (d *Descriptor) kerning(first, second rune) (amount fixed.Int26_6, ok bool) {
+	if k, ok := d.Kerning[CharPair{First: first, Second: second}]; ok {
+		return k.exactAmount(), true
+	}
+	if len(d.ClassKerning) == 0 {
+		return 0, false
+	}
+	a, b := d.KerningClasses[first].First, d.KerningClasses[second].Second
+	if a == 0 || b == 0 {
+		return 0, false
+	}
+	k, ok := d.ClassKerning[ClassPair{First: a, Second: b}]
+	return k.exactAmount(), ok
+}
+
+// addKerningClass adds the characters of a kernclass tag to the kerning
+// classes of the descriptor.
+func (d *Descriptor) addKerningClass(t *tag) error {
+	id := t.intAttr("id")
+	side := t.stringAttr("side")
+	if side != "first" && side != "second" {
+		return newError(t.pos, fmt.Sprintf("kernclass tag has invalid side %q, must be first or second", side))
+	}
+	if d.KerningClasses == nil {
+		d.KerningClasses = make(map[rune]CharClasses)
+	}
+	for _, r := range t.stringAttr("chars") {
+		classes := d.KerningClasses[r]
+		if side == "first" {
+			classes.First = id
+		} else {
+			classes.Second = id
+		}
+		d.KerningClasses[r] = classes
+	}
+	return nil
+}
+
+// writeKerningClasses writes the kerning classes and the class-based
+// kerning in the text format extension:
+//
+//	kernclass id=1 side="first" chars="AÀÁÂ"
+//	classkerning first=1 second=2 amount=-1
+func (d *Descriptor) writeKerningClasses(w io.Writer) {
+	for _, c := range d.kerningClassList() {
+		fmt.Fprintf(w, "kernclass id=%d side=%s chars=%s\n", c.id, quote(c.side), quote(c.chars))
+	}
+	for _, pair := range d.classKerningPairs() {
+		fmt.Fprintf(w, "classkerning first=%-3d second=%-3d amount=%d\n",
+			pair.First, pair.Second, d.ClassKerning[pair].Amount)
+	}
+}
+
+// A kerningClass is a kerning class with its member characters, as written
+// by the descriptor writers.
+type kerningClass struct {
+	side  string
+	id    int
+	chars string
+}
+
+// kerningClassList returns the kerning classes of the descriptor sorted by
+// side and ID.
+func (d *Descriptor) kerningClassList() []kerningClass {
+	type class struct {
+		side string
+		id   int
+	}
+	members := make(map[class][]rune)
+	for _, r := range sortedKeys(d.KerningClasses) {
+		c := d.KerningClasses[r]
+		if c.First != 0 {
+			members[class{"first", c.First}] = append(members[class{"first", c.First}], r)
+		}
+		if c.Second != 0 {
+			members[class{"second", c.Second}] = append(members[class{"second", c.Second}], r)
+		}
+	}
+	classes := make([]kerningClass, 0, len(members))
+	for c, chars := range members {
+		classes = append(classes, kerningClass{side: c.side, id: c.id, chars: string(chars)})
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if classes[i].side != classes[j].side {
+			return classes[i].side == "first"
+		}
+		return classes[i].id < classes[j].id
+	})
+	return classes
+}
+
+// classKerningPairs returns the class pairs of the class-based kerning
+// sorted by their IDs.
+func (d *Descriptor) classKerningPairs() []ClassPair {
+	pairs := make([]ClassPair, 0, len(d.ClassKerning))
+	for pair := range d.ClassKerning {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].First != pairs[j].First {
+			return pairs[i].First < pairs[j].First
+		}
+		return pairs[i].Second < pairs[j].Second
+	})
+	return pairs
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// A LabelAtlas is an image with pre-rendered text labels, e.g. for static
+// user interface labels in engines that prefer textures over text rendered at
+// runtime.
+type LabelAtlas struct {
+	// Image contains the rendered labels.
+	Image *image.RGBA
+	// Labels holds the texts and positions of the labels within the image.
+	Labels []Label
+}
+
+// A Label is a text rendered into a label atlas.
+type Label struct {
+	Text string
+	// Bounds is the rectangle of the rendered label within the atlas image.
+	Bounds image.Rectangle
+}
+
+// BakeLabels renders each of the given texts with the given style like
+// RenderToImage and packs the resulting label images into a new atlas image
+// of the given width. The height of the atlas is as large as needed.
+// Texts that occur more than once are only rendered once.
+func (f *BitmapFont) BakeLabels(texts []string, style *Style, width int) (*LabelAtlas, error) {
+	var labels []Label
+	var images []*image.RGBA
+	var sizes []image.Point
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+		img := f.RenderToImage(text, style)
+		if img.Bounds().Dx() > width {
+			return nil, fmt.Errorf("bmfont: label %q is wider than the atlas width %d", text, width)
+		}
+		labels = append(labels, Label{Text: text})
+		images = append(images, img)
+		sizes = append(sizes, img.Bounds().Size())
+	}
+	placed, used, err := packRects(sizes, image.Pt(width, 0), 1)
+	if err != nil {
+		return nil, err
+	}
+	atlas := image.NewRGBA(image.Rect(0, 0, width, used[0].Y))
+	for i, p := range placed {
+		labels[i].Bounds = image.Rectangle{Min: p.pos, Max: p.pos.Add(sizes[i])}
+		draw.Draw(atlas, labels[i].Bounds, images[i], image.Point{}, draw.Src)
+	}
+	return &LabelAtlas{Image: atlas, Labels: labels}, nil
+}
+
+// WriteManifest writes a JSON manifest of the label positions within the
+// atlas to w. The imageFile parameter is the file name under which the atlas
+// image is stored, and it is recorded in the manifest.
+func (a *LabelAtlas) WriteManifest(w io.Writer, imageFile string) error {
+	type manifestLabel struct {
+		Text   string `json:"text"`
+		X      int    `json:"x"`
+		Y      int    `json:"y"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	}
+	type manifest struct {
+		Image  string          `json:"image"`
+		Width  int             `json:"width"`
+		Height int             `json:"height"`
+		Labels []manifestLabel `json:"labels"`
+	}
+	m := manifest{
+		Image:  imageFile,
+		Width:  a.Image.Bounds().Dx(),
+		Height: a.Image.Bounds().Dy(),
+		Labels: make([]manifestLabel, len(a.Labels)),
+	}
+	for i, l := range a.Labels {
+		m.Labels[i] = manifestLabel{
+			Text:   l.Text,
+			X:      l.Bounds.Min.X,
+			Y:      l.Bounds.Min.Y,
+			Width:  l.Bounds.Dx(),
+			Height: l.Bounds.Dy(),
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestBakeLabels(t *testing.T) {
+	f := opaqueFont("AB")
+	atlas, err := f.BakeLabels([]string{"AB", "A", "AB", "BBB"}, nil, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atlas.Labels) != 3 {
+		t.Fatalf("got %d labels, want 3 without the duplicate", len(atlas.Labels))
+	}
+	for i, l := range atlas.Labels {
+		if !l.Bounds.In(atlas.Image.Bounds()) {
+			t.Errorf("label %q at %v is outside of the atlas %v", l.Text, l.Bounds, atlas.Image.Bounds())
+		}
+		if got, want := l.Bounds.Size(), f.RenderToImage(l.Text, nil).Bounds().Size(); got != want {
+			t.Errorf("label %q: got size %v, want %v", l.Text, got, want)
+		}
+		for _, other := range atlas.Labels[i+1:] {
+			if l.Bounds.Overlaps(other.Bounds) {
+				t.Errorf("labels %q and %q overlap", l.Text, other.Text)
+			}
+		}
+	}
+	if _, err := f.BakeLabels([]string{"ABABABAB"}, nil, 8); err == nil {
+		t.Error("BakeLabels accepted a label wider than the atlas")
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	f := opaqueFont("AB")
+	atlas, err := f.BakeLabels([]string{"AB"}, nil, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := atlas.WriteManifest(&buf, "labels.png"); err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Image  string
+		Labels []struct {
+			Text          string
+			Width, Height int
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Image != "labels.png" || len(m.Labels) != 1 || m.Labels[0].Text != "AB" ||
+		m.Labels[0].Width != atlas.Labels[0].Bounds.Dx() {
+		t.Errorf("got manifest %+v", m)
+	}
+}
@@ -0,0 +1,620 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
+)
+
+// A layout is a text arranged in lines of positioned glyphs. All positions
+// are in destination pixels relative to the start position of the text, which
+// is on the base line of the first line.
+type layout struct {
+	lines []line
+	// font is the font or the variant of the font the glyphs are taken from.
+	font  *BitmapFont
+	scale float64
+	// shear is the horizontal shift of the glyphs per pixel above the base
+	// line, for slanted text.
+	shear    float64
+	rounding GlyphRounding
+	// glyphColor and outlineColor are the colors the glyphs and their
+	// outlines are drawn with, if outlineColor is not nil.
+	glyphColor, outlineColor color.Color
+	// colored reports whether the glyphs are drawn with their own colors
+	// instead of the color of the style, e.g. for markup.
+	colored bool
+}
+
+type line struct {
+	// start and end are the byte offsets of the line's text.
+	start, end int
+	glyphs     []glyph
+	// width is the advance width of the line.
+	width fixed.Int26_6
+	// last reports whether the line is the last line of a paragraph.
+	last bool
+	// y is the base line of the line.
+	y fixed.Int26_6
+}
+
+type glyph struct {
+	r rune
+	// offset is the byte offset of the rune in the text.
+	offset int
+	char   Char
+	// dot is the pen position on the base line where the glyph is drawn.
+	dot fixed.Point26_6
+	// advance is the horizontal advance of the glyph including kerning.
+	advance fixed.Int26_6
+	// scale is the factor the glyph is scaled with.
+	scale float64
+	// font is the font or the variant of the font the glyph is taken from.
+	font *BitmapFont
+	// image is the inline image the glyph draws instead of a character of
+	// the font, if it is not nil.
+	image *InlineImage
+	// color is the color the glyph is drawn with in colored layouts: the
+	// color it is tinted with, or the color inside its outline for outlined
+	// text. If it is nil the colors of the page sheet or the glyph color of
+	// the layout are used.
+	color color.Color
+}
+
+func (f *BitmapFont) layout(text string, style *Style, stats *fontStats) *layout {
+	text = style.transform(text)
+	v, s := f.variant(style)
+	l := newLayout(v, s, style)
+	lineHeight := style.lineAdvance(v.Descriptor.Common.LineHeight, s)
+	maxWidth := fixed.I(style.maxWidth())
+	var y fixed.Int26_6
+	for start := 0; start <= len(text); {
+		end := len(text)
+		for i, r := range text[start:] {
+			if r == '\n' {
+				end = start + i
+				break
+			}
+		}
+		glyphs := v.shape(f, text, start, end, style, s, 0, stats)
+		lines := v.breakParagraph(glyphs, start, end, maxWidth, style, s)
+		for _, ln := range lines {
+			ln.y = style.baseline(y)
+			for i := range ln.glyphs {
+				ln.glyphs[i].dot.Y = ln.y
+			}
+			l.lines = append(l.lines, ln)
+			y += lineHeight
+		}
+		start = end + 1
+	}
+	l.limitLines(style, maxWidth)
+	l.align(style.align(), maxWidth, style.justifyCharacters())
+	if len(style.images()) > 0 {
+		// Inline images are not tinted, so the glyphs are drawn with the
+		// color of the style themselves.
+		l.colored = true
+		c := style.color()
+		l.eachGlyph(func(_ int, g *glyph) {
+			if g.image == nil {
+				g.color = c
+			}
+		})
+	}
+	return l
+}
+
+// newLayout returns an empty layout of glyphs taken from the given font or
+// variant of a font, scaled with the given factor, and drawn as specified by
+// the style.
+func newLayout(font *BitmapFont, scale float64, style *Style) *layout {
+	l := &layout{font: font, scale: scale, shear: style.shear(), rounding: style.glyphRounding()}
+	if style != nil && style.OutlineColor != nil {
+		l.glyphColor, l.outlineColor = style.fade(style.Color), style.fade(style.OutlineColor)
+	}
+	return l
+}
+
+// shape converts the text between the byte offsets start and end, which must
+// not contain a newline, into a sequence of glyphs. The pen positions of the
+// glyphs are relative to the start of the line, where the pen of the first
+// glyph is at x. The ligatures and substitutions are those of the tables
+// font, which is the font that f is a variant of, or f itself.
+func (f *BitmapFont) shape(tables *BitmapFont, text string, start, end int, style *Style, s float64, x fixed.Int26_6, stats *fontStats) []glyph {
+	smallCaps := style.smallCaps()
+	kerning := style.kerning()
+	// Tracking is scaled with the full scale of the style, like the line
+	// height, not with the scale left for the glyphs of a prescaled variant.
+	tracking := style.tracking() * style.scale()
+	images := len(style.images()) > 0
+	var glyphs []glyph
+	dot := x
+	prev := rune(-1)
+	para := text[start:end]
+	for i := 0; i < len(para); {
+		if images && para[i] == '{' {
+			if img, n, ok := style.inlineImage(para[i:]); ok {
+				g := f.imageGlyph(img, start+i, dot, s)
+				g.color = style.fade(nil)
+				glyphs = append(glyphs, g)
+				dot += g.advance
+				i += n
+				prev = -1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(para[i:])
+		if lig, n, ok := tables.ligature(f, para[i:]); ok {
+			r, size = lig, n
+		} else if len(tables.Substitutions) > 0 {
+			r = tables.substitute(f, para, i, size, r)
+		}
+		gs, k := s, 1.0
+		if smallCaps > 0 {
+			if upper, ok := f.smallCap(r); ok {
+				r, gs, k = upper, s*smallCaps, smallCaps
+			}
+		}
+		offset := start + i
+		i += size
+		if r == '\t' {
+			sp := f.Descriptor.Chars[' ']
+			stop := style.nextTabStop(dot, scaledExact(sp.exactAdvance(), s))
+			glyphs = append(glyphs, glyph{
+				r:       r,
+				offset:  offset,
+				char:    Char{ID: r},
+				dot:     fixed.Point26_6{X: dot},
+				advance: stop - dot,
+				scale:   s,
+				font:    f,
+			})
+			dot = stop
+			prev = -1
+			continue
+		}
+		ch, found, ok := f.char(r)
+		if !found && stats != nil {
+			stats.countMissing(r, ok)
+		}
+		if !ok {
+			continue
+		}
+		if prev >= 0 && kerning {
+			if amount, ok := f.Descriptor.kerning(prev, r); ok {
+				k := scaledExact(amount, gs)
+				glyphs[len(glyphs)-1].advance += k
+				dot += k
+			}
+		}
+		advance := scaledExact(ch.exactAdvance(), gs) + toFixed(tracking*k)
+		glyphs = append(glyphs, glyph{
+			r:       r,
+			offset:  offset,
+			char:    ch,
+			dot:     fixed.Point26_6{X: dot},
+			advance: advance,
+			scale:   gs,
+			font:    f,
+		})
+		dot += advance
+		prev = r
+	}
+	return glyphs
+}
+
+// wrapLine breaks a sequence of glyphs into lines at word boundaries, so
+// that the lines fit into the given maximum width. Lines are broken at
+// spaces or after hyphens and dashes, and words that are wider than the
+// maximum width are broken between characters. If maxWidth is 0 the
+// glyphs are not wrapped. The start and end byte offsets are those of the
+// text the glyphs were shaped from.
+func wrapLine(glyphs []glyph, start, end int, maxWidth fixed.Int26_6) []line {
+	if maxWidth <= 0 || len(glyphs) == 0 {
+		return []line{newLine(glyphs, start, end)}
+	}
+	var lines []line
+	first := 0
+	for first < len(glyphs) {
+		x0 := glyphs[first].dot.X
+		brk := len(glyphs)
+		for i := first; i < len(glyphs); i++ {
+			if glyphs[i].dot.X+glyphs[i].advance-x0 <= maxWidth || isSpace(glyphs[i].r) {
+				continue
+			}
+			brk = i
+			for j := i; j > first; j-- {
+				if isSpace(glyphs[j].r) || breaksAfter(glyphs[j-1].r) {
+					brk = j
+					break
+				}
+			}
+			if brk == first {
+				brk = first + 1
+			}
+			break
+		}
+		last := brk
+		for last > first && isSpace(glyphs[last-1].r) {
+			last--
+		}
+		next := brk
+		for next < len(glyphs) && isSpace(glyphs[next].r) {
+			next++
+		}
+		lnStart, lnEnd := glyphs[first].offset, end
+		if next < len(glyphs) {
+			lnEnd = glyphs[next].offset
+		}
+		if first == 0 {
+			lnStart = start
+		}
+		lines = append(lines, newLine(glyphs[first:last], lnStart, lnEnd))
+		first = next
+	}
+	return lines
+}
+
+func newLine(glyphs []glyph, start, end int) line {
+	ln := line{start: start, end: end}
+	if len(glyphs) == 0 {
+		return ln
+	}
+	x0 := glyphs[0].dot.X
+	ln.glyphs = make([]glyph, len(glyphs))
+	for i, g := range glyphs {
+		g.dot.X -= x0
+		ln.glyphs[i] = g
+	}
+	lastGlyph := ln.glyphs[len(ln.glyphs)-1]
+	ln.width = lastGlyph.dot.X + lastGlyph.advance
+	return ln
+}
+
+// align shifts the glyphs of the lines horizontally according to the given
+// alignment, either within the maximum width or, if it is 0, within the
+// width of the widest line. Justified lines are spread at their spaces, or
+// between all characters if chars is true.
+func (l *layout) align(a Alignment, maxWidth fixed.Int26_6, chars bool) {
+	if a == AlignLeft {
+		return
+	}
+	width := maxWidth
+	if width <= 0 {
+		for _, ln := range l.lines {
+			width = max(width, ln.width)
+		}
+	}
+	for i := range l.lines {
+		ln := &l.lines[i]
+		if a == AlignJustify {
+			if !ln.last {
+				ln.justify(width, chars)
+			}
+			continue
+		}
+		dx := width - ln.width
+		if a == AlignCenter {
+			dx /= 2
+		}
+		for j := range ln.glyphs {
+			ln.glyphs[j].dot.X += dx
+		}
+	}
+}
+
+// justify distributes the space between the width of the line and the given
+// width between the words of the line, or between all of its characters if
+// chars is true. Lines without gaps are left unchanged.
+func (ln *line) justify(width fixed.Int26_6, chars bool) {
+	extra := width - ln.width
+	if extra <= 0 || len(ln.glyphs) < 2 {
+		return
+	}
+	gaps := 0
+	for _, g := range ln.glyphs[1:] {
+		if chars || isSpace(g.r) {
+			gaps++
+		}
+	}
+	if gaps == 0 {
+		return
+	}
+	k := 0
+	for i := 1; i < len(ln.glyphs); i++ {
+		if chars || isSpace(ln.glyphs[i].r) {
+			k++
+		}
+		ln.glyphs[i].dot.X += extra * fixed.Int26_6(k) / fixed.Int26_6(gaps)
+	}
+	ln.width = width
+}
+
+// draw draws the glyphs of the layout with the given drawer. The start
+// position pos is on the base line of the first line.
+func (l *layout) draw(dst drawer, pos image.Point, stats *fontStats) {
+	l.drawFixed(dst, fixed.P(pos.X, pos.Y), stats)
+}
+
+// drawFixed draws the glyphs of the layout like draw, but at a fractional
+// start position.
+func (l *layout) drawFixed(dst drawer, origin fixed.Point26_6, stats *fontStats) {
+	for _, ln := range l.lines {
+		for _, g := range ln.glyphs {
+			src := l.glyphSource(g, stats)
+			sr := g.char.Bounds()
+			if l.shear != 0 || l.rounding == GlyphFractional {
+				m := l.glyphTransform(origin, g)
+				dst.DrawTransformed(m, transformedBounds(m, sr), src, sr)
+			} else {
+				dst.Draw(l.glyphRect(origin, g), src, sr)
+			}
+		}
+	}
+}
+
+// glyphSource returns the source of the glyph's bitmap, with the colors of
+// the layout applied. The glyph is counted as drawn in the stats, if they
+// are not nil, when its image is requested.
+func (l *layout) glyphSource(g glyph, stats *fontStats) glyphSource {
+	return func() image.Image {
+		sheet := g.sheet()
+		if sheet == nil {
+			return nil
+		}
+		switch {
+		case l.outlineColor != nil && g.image == nil:
+			glyphColor := l.glyphColor
+			if g.color != nil {
+				glyphColor = g.color
+			}
+			sheet = newChannelImage(sheet, g.char, &g.font.Descriptor.Common, glyphColor, l.outlineColor)
+		case g.color != nil:
+			sheet = newTintedImage(sheet, g.color)
+		}
+		if stats != nil {
+			stats.glyphsDrawn.Add(1)
+		}
+		return sheet
+	}
+}
+
+// sheet returns the image with the glyph's bitmap, the page sheet of its
+// character or its inline image, or nil if it can't be loaded.
+func (g *glyph) sheet() image.Image {
+	if g.image != nil {
+		return g.image.Image
+	}
+	sheet, _ := g.font.Page(g.char.Page)
+	return sheet
+}
+
+// glyphRectFixed returns the exact destination rectangle of the glyph's
+// bitmap if the layout is drawn at the given origin.
+func (l *layout) glyphRectFixed(origin fixed.Point26_6, g glyph) fixed.Rectangle26_6 {
+	base := fixed.I(g.font.Descriptor.Common.Base)
+	dot := origin.Add(g.dot)
+	ch := g.char
+	offset := ch.exactOffset()
+	x0 := dot.X + scaledExact(offset.X, g.scale)
+	y0 := dot.Y + scaledExact(offset.Y-base, g.scale)
+	return fixed.Rectangle26_6{
+		Min: fixed.Point26_6{X: x0, Y: y0},
+		Max: fixed.Point26_6{X: x0 + scaled(ch.Width, g.scale), Y: y0 + scaled(ch.Height, g.scale)},
+	}
+}
+
+// glyphRect returns the destination rectangle of the glyph's bitmap if the
+// layout is drawn at the given origin, rounded to pixels.
+func (l *layout) glyphRect(origin fixed.Point26_6, g glyph) image.Rectangle {
+	r := l.glyphRectFixed(origin, g)
+	if l.rounding == GlyphFloor {
+		return image.Rect(r.Min.X.Floor(), r.Min.Y.Floor(), r.Max.X.Floor(), r.Max.Y.Floor())
+	}
+	return image.Rect(r.Min.X.Round(), r.Min.Y.Round(), r.Max.X.Round(), r.Max.Y.Round())
+}
+
+// glyphTransform returns the affine transformation from the glyph's bitmap
+// in the page sheet to the destination if the layout is drawn at the given
+// origin, including the shear of slanted text. With fractional rounding the
+// glyph is transformed to its exact position.
+func (l *layout) glyphTransform(origin fixed.Point26_6, g glyph) f64.Aff3 {
+	var x0, y0, x1, y1, baseline float64
+	if l.rounding == GlyphFractional {
+		r := l.glyphRectFixed(origin, g)
+		x0, y0 = fixedToFloat(r.Min.X), fixedToFloat(r.Min.Y)
+		x1, y1 = fixedToFloat(r.Max.X), fixedToFloat(r.Max.Y)
+		baseline = fixedToFloat(origin.Y + g.dot.Y)
+	} else {
+		r := l.glyphRect(origin, g)
+		x0, y0 = float64(r.Min.X), float64(r.Min.Y)
+		x1, y1 = float64(r.Max.X), float64(r.Max.Y)
+		baseline = float64((origin.Y + g.dot.Y).Round())
+	}
+	sr := g.char.Bounds()
+	kx := (x1 - x0) / float64(max(1, sr.Dx()))
+	ky := (y1 - y0) / float64(max(1, sr.Dy()))
+	tx := x0 - kx*float64(sr.Min.X)
+	ty := y0 - ky*float64(sr.Min.Y)
+	return f64.Aff3{
+		kx, -l.shear * ky, tx + l.shear*(baseline-ty),
+		0, ky, ty,
+	}
+}
+
+// transformedBounds returns the pixels covered by the rectangle r
+// transformed by m.
+func transformedBounds(m f64.Aff3, r image.Rectangle) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range [...]image.Point{r.Min, {r.Max.X, r.Min.Y}, {r.Min.X, r.Max.Y}, r.Max} {
+		x := m[0]*float64(p.X) + m[1]*float64(p.Y) + m[2]
+		y := m[3]*float64(p.X) + m[4]*float64(p.Y) + m[5]
+		minX, minY = min(minX, x), min(minY, y)
+		maxX, maxY = max(maxX, x), max(maxY, y)
+	}
+	return image.Rect(
+		int(math.Floor(minX)), int(math.Floor(minY)),
+		int(math.Ceil(maxX)), int(math.Ceil(maxY)),
+	)
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// bounds calculates the bounding box of the glyphs of the layout as if it
+// was drawn at position (0, 0).
+func (l *layout) bounds() image.Rectangle {
+	var m boundsMeasurer
+	l.draw(&m, image.Point{}, nil)
+	return m.bounds
+}
+
+func scaled(v int, s float64) fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(float64(v) * s * 64))
+}
+
+func isSpace(r rune) bool {
+	return unicode.IsSpace(r) && r != '\u00a0'
+}
+
+// breaksAfter reports whether a line may be broken after the rune, like
+// after the hyphen of a compound word.
+func breaksAfter(r rune) bool {
+	switch r {
+	case '-', '\u2010', '\u2013', '\u2014':
+		return true
+	}
+	return false
+}
+
+// clone returns a copy of the layout that can be modified independently.
+func (l *layout) clone() *layout {
+	c := *l
+	c.lines = make([]line, len(l.lines))
+	for i, ln := range l.lines {
+		ln.glyphs = append([]glyph(nil), ln.glyphs...)
+		c.lines[i] = ln
+	}
+	return &c
+}
+
+// glyphCount returns the number of glyphs in the layout.
+func (l *layout) glyphCount() int {
+	n := 0
+	for _, ln := range l.lines {
+		n += len(ln.glyphs)
+	}
+	return n
+}
+
+// truncate removes all glyphs from the layout except for the first n
+// glyphs. The positions of the remaining glyphs are not changed.
+func (l *layout) truncate(n int) {
+	for i := range l.lines {
+		ln := &l.lines[i]
+		k := min(n, len(ln.glyphs))
+		ln.glyphs = ln.glyphs[:k]
+		n -= k
+	}
+}
+
+// eachGlyph calls fn for each glyph of the layout with the index of the
+// glyph within the whole layout.
+func (l *layout) eachGlyph(fn func(i int, g *glyph)) {
+	n := 0
+	for i := range l.lines {
+		for j := range l.lines[i].glyphs {
+			fn(n, &l.lines[i].glyphs[j])
+			n++
+		}
+	}
+}
+
+// A Line is a line of a laid out text, as reported by MeasureLines.
+type Line struct {
+	// Text is the text of the line without the line break and without
+	// trailing spaces, after the case transformation of the style. The text
+	// of a truncated line ends before the ellipsis.
+	Text string
+	// Width is the advance width of the line in pixels.
+	Width int
+	// Bounds is the bounding box of the glyphs of the line as if the text
+	// was drawn at position (0, 0), like the bounds reported by MeasureText.
+	Bounds image.Rectangle
+}
+
+// MeasureLines lays out the text with the given style like RenderToImage,
+// including wrapping and alignment, and measures each resulting line.
+func (f *BitmapFont) MeasureLines(text string, style *Style) []Line {
+	l := f.layout(text, style, nil)
+	text = style.transform(text)
+	lines := make([]Line, len(l.lines))
+	for i, ln := range l.lines {
+		sub := *l
+		sub.lines = []line{ln}
+		b := sub.bounds()
+		lines[i] = Line{
+			Text:  strings.TrimRightFunc(text[ln.start:ln.end], isSpace),
+			Width: int(math.Ceil(float64(ln.width) / 64 / style.deviceScale())),
+			Bounds: image.Rect(
+				style.logical(b.Min.X), style.logical(b.Min.Y),
+				style.logical(b.Max.X), style.logical(b.Max.Y),
+			),
+		}
+	}
+	return lines
+}
+
+// A Quad is a glyph of a laid out text, as reported by Quads. It maps a
+// rectangle of a page sheet to a rectangle of the destination.
+type Quad struct {
+	// Rune is the character the glyph represents. It is the fallback
+	// character '?' for characters that are not included in the font.
+	Rune rune
+	// Page is the ID of the page sheet containing the glyph's bitmap. If the
+	// style selects a prescaled variant of the font, it is a page of the
+	// variant.
+	Page int
+	// Src is the rectangle of the glyph's bitmap within the page sheet.
+	Src image.Rectangle
+	// Dst is the rectangle the glyph's bitmap is drawn to as if the text
+	// was drawn at position (0, 0), in device pixels.
+	Dst image.Rectangle
+	// Image is the inline image the glyph is drawn from instead of a page
+	// sheet, if it is not nil, see Style.Images. Then Rune is U+FFFC, Page
+	// is 0 and Src is a rectangle of the image.
+	Image image.Image
+}
+
+// Quads lays out the text with the given style like RenderToImage and
+// returns the glyphs in drawing order, e.g. for drawing the text with a
+// different graphics library or engine.
+func (f *BitmapFont) Quads(text string, style *Style) []Quad {
+	l := f.layout(text, style, nil)
+	quads := make([]Quad, 0, l.glyphCount())
+	l.eachGlyph(func(_ int, g *glyph) {
+		q := Quad{
+			Rune: g.char.ID,
+			Page: g.char.Page,
+			Src:  g.char.Bounds(),
+			Dst:  l.glyphRect(fixed.Point26_6{}, *g),
+		}
+		if g.image != nil {
+			q.Image = g.image.Image
+		}
+		quads = append(quads, q)
+	})
+	return quads
+}
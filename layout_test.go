@@ -0,0 +1,324 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"math"
+	"slices"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// testFont returns a font with the given characters, all of which are
+// 4×6 pixel boxes with an advance of 5 pixels, scaled by the factor k.
+func testFont(chars string, k int) *BitmapFont {
+	d := &Descriptor{
+		Common: Common{LineHeight: 8 * k, Base: 6 * k, ScaleW: 64 * k, ScaleH: 8 * k},
+		Pages:  map[int]Page{0: {ID: 0, File: "sheet.png"}},
+		Chars:  make(map[rune]Char),
+	}
+	x := 0
+	for _, r := range chars {
+		d.Chars[r] = Char{ID: r, X: x, Width: 4 * k, Height: 6 * k, XAdvance: 5 * k, Channel: All}
+		x += 4 * k
+		if r == ' ' {
+			c := d.Chars[r]
+			c.Width, c.Height = 0, 0
+			d.Chars[r] = c
+		}
+	}
+	return &BitmapFont{
+		Descriptor: d,
+		PageSheets: map[int]image.Image{0: image.NewNRGBA(image.Rect(0, 0, x+1, 8*k))},
+	}
+}
+
+// layoutRunes returns the runes of the glyphs of the layout, line by line.
+func layoutRunes(l *layout) []string {
+	var lines []string
+	for _, ln := range l.lines {
+		var rs []rune
+		for _, g := range ln.glyphs {
+			rs = append(rs, g.r)
+		}
+		lines = append(lines, string(rs))
+	}
+	return lines
+}
+
+func TestLayoutVariantUsesFontTables(t *testing.T) {
+	const lig = '\uE000'
+	chars := "fiax" + string(lig)
+	f := testFont(chars, 1)
+	f.Ligatures = map[string]rune{"fi": lig}
+	f.Substitutions = []Substitution{{Rune: 'a', Final: true, Glyph: 'x'}}
+	f.Variants = map[float64]*BitmapFont{2: testFont(chars, 2)}
+
+	for _, scale := range []float64{1, 2} {
+		l := f.layout("fia", &Style{Scale: scale}, nil)
+		if got, want := layoutRunes(l)[0], string(lig)+"x"; got != want {
+			t.Errorf("scale %g: got glyphs %q, want %q", scale, got, want)
+		}
+	}
+}
+
+func TestMeasureLines(t *testing.T) {
+	f := testFont("AB ", 1)
+	lines := f.MeasureLines("AB AB\nA", &Style{MaxWidth: 12})
+	want := []Line{
+		{Text: "AB", Width: 10, Bounds: image.Rect(0, -6, 9, 0)},
+		{Text: "AB", Width: 10, Bounds: image.Rect(0, 2, 9, 8)},
+		{Text: "A", Width: 5, Bounds: image.Rect(0, 10, 4, 16)},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines %+v, want %d", len(lines), lines, len(want))
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d: got %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestQuads(t *testing.T) {
+	f := testFont("AB?", 1)
+	quads := f.Quads("ABx", &Style{Scale: 2})
+	want := []Quad{
+		{Rune: 'A', Src: image.Rect(0, 0, 4, 6), Dst: image.Rect(0, -12, 8, 0)},
+		{Rune: 'B', Src: image.Rect(4, 0, 8, 6), Dst: image.Rect(10, -12, 18, 0)},
+		{Rune: '?', Src: image.Rect(8, 0, 12, 6), Dst: image.Rect(20, -12, 28, 0)},
+	}
+	if len(quads) != len(want) {
+		t.Fatalf("got %d quads, want %d", len(quads), len(want))
+	}
+	for i := range want {
+		if quads[i] != want[i] {
+			t.Errorf("quad %d: got %+v, want %+v", i, quads[i], want[i])
+		}
+	}
+}
+
+// lineStarts returns the pen positions of the first glyphs of the lines of
+// the layout.
+func lineStarts(l *layout) []fixed.Int26_6 {
+	var xs []fixed.Int26_6
+	for _, ln := range l.lines {
+		xs = append(xs, ln.glyphs[0].dot.X)
+	}
+	return xs
+}
+
+func TestAlign(t *testing.T) {
+	f := testFont("AB", 1)
+	tests := []struct {
+		style *Style
+		want  []fixed.Int26_6
+	}{
+		{&Style{Align: AlignLeft}, []fixed.Int26_6{0, 0}},
+		{&Style{Align: AlignCenter}, []fixed.Int26_6{0, fixed.I(5) / 2}},
+		{&Style{Align: AlignRight}, []fixed.Int26_6{0, fixed.I(5)}},
+		{&Style{Align: AlignRight, MaxWidth: 20}, []fixed.Int26_6{fixed.I(10), fixed.I(15)}},
+	}
+	for _, tt := range tests {
+		if got := lineStarts(f.layout("AB\nA", tt.style, nil)); !slices.Equal(got, tt.want) {
+			t.Errorf("%+v: got line starts %v, want %v", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestDrawTextAligned(t *testing.T) {
+	f := opaqueFont("AB")
+	tests := []struct {
+		align Alignment
+		want  int // the left edge of the second line
+	}{
+		{AlignLeft, 20},
+		{AlignCenter, 20 - 5 + 3},
+		{AlignRight, 20 - 10 + 5},
+	}
+	for _, tt := range tests {
+		dst := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		f.DrawTextAligned(dst, image.Pt(20, 8), "AB\nA", tt.align)
+		if dst.RGBAAt(tt.want, 12).A == 0 || dst.RGBAAt(tt.want-1, 12).A != 0 {
+			t.Errorf("align %v: second line doesn't start at x %d", tt.align, tt.want)
+		}
+	}
+}
+
+func TestJustify(t *testing.T) {
+	f := testFont("AB ", 1)
+	tests := []struct {
+		style *Style
+		want  []fixed.Int26_6 // the pen positions of the first line
+	}{
+		{
+			&Style{Align: AlignJustify, MaxWidth: 30},
+			[]fixed.Int26_6{0, fixed.I(5), fixed.I(15), fixed.I(20), fixed.I(25)},
+		},
+		{
+			&Style{Align: AlignJustify, MaxWidth: 30, JustifyCharacters: true},
+			[]fixed.Int26_6{0, fixed.I(5) + 80, fixed.I(10) + 160, fixed.I(15) + 240, fixed.I(25)},
+		},
+	}
+	for _, tt := range tests {
+		l := f.layout("AB AB AB", tt.style, nil)
+		if len(l.lines) != 2 {
+			t.Fatalf("%+v: got lines %q, want 2", tt.style, layoutRunes(l))
+		}
+		var got []fixed.Int26_6
+		for _, g := range l.lines[0].glyphs {
+			got = append(got, g.dot.X)
+		}
+		if !slices.Equal(got, tt.want) || l.lines[0].width != fixed.I(30) {
+			t.Errorf("%+v: got pen positions %v and width %v, want %v and 30:00", tt.style, got, l.lines[0].width, tt.want)
+		}
+		// The last line of the paragraph is left aligned.
+		if x := l.lines[1].glyphs[1].dot.X; x != fixed.I(5) {
+			t.Errorf("%+v: got last line glyph at %v, want 5:00", tt.style, x)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	f := testFont("AB- ", 1)
+	tests := []struct {
+		text     string
+		maxWidth int
+		want     []string
+	}{
+		{"AB AB", 0, []string{"AB AB"}},
+		{"AB AB", 25, []string{"AB AB"}},
+		{"AB AB", 24, []string{"AB", "AB"}},
+		{"AB   AB ", 12, []string{"AB", "AB"}},
+		{"AB-AB", 15, []string{"AB-", "AB"}},
+		{"AAAAA", 12, []string{"AA", "AA", "A"}},
+		{"AB AB\nA", 12, []string{"AB", "AB", "A"}},
+	}
+	for _, tt := range tests {
+		l := f.layout(tt.text, &Style{MaxWidth: tt.maxWidth}, nil)
+		if got := layoutRunes(l); !slices.Equal(got, tt.want) {
+			t.Errorf("%q wrapped at %d: got lines %q, want %q", tt.text, tt.maxWidth, got, tt.want)
+		}
+		for i, ln := range l.lines {
+			if ln.width > fixed.I(tt.maxWidth) && tt.maxWidth > 0 {
+				t.Errorf("%q wrapped at %d: line %d has width %v", tt.text, tt.maxWidth, i, ln.width)
+			}
+		}
+	}
+	// The lines are drawn like the wrapped text.
+	if got, want := f.MeasureTextOpts("AB AB", WithMaxWidth(12)), f.MeasureText("AB\nAB"); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+}
+
+func TestTracking(t *testing.T) {
+	f := testFont("A", 1)
+	tests := []struct {
+		style *Style
+		want  fixed.Int26_6
+	}{
+		{nil, fixed.I(15)},
+		{&Style{Tracking: 1}, fixed.I(18)},
+		{&Style{Tracking: -1.5}, fixed.I(15) - 3*fixed.I(3)/2},
+		{&Style{Tracking: 1, Scale: 2}, fixed.I(36)},
+	}
+	for _, tt := range tests {
+		if got := f.layout("AAA", tt.style, nil).lines[0].width; got != tt.want {
+			t.Errorf("%+v: got line width %v, want %v", tt.style, got, tt.want)
+		}
+	}
+	// The tracking is scaled with the scale of the style, not with the
+	// scale left for a prescaled variant.
+	style := &Style{Tracking: 3, Scale: 2}
+	want := f.MeasureTextOpts("AA", WithScale(2), WithTracking(3))
+	f.Variants = map[float64]*BitmapFont{2: testFont("A", 2)}
+	if got := f.layout("AAA", style, nil).lines[0].width; got != fixed.I(48) {
+		t.Errorf("with 2x variant: got line width %v, want %v", got, fixed.I(48))
+	}
+	if got := f.MeasureTextOpts("AA", WithScale(2), WithTracking(3)); got != want {
+		t.Errorf("with 2x variant: got bounds %v, want %v", got, want)
+	}
+}
+
+func TestTabStops(t *testing.T) {
+	f := testFont("A ", 1)
+	tests := []struct {
+		style *Style
+		want  []fixed.Int26_6 // the pen positions of the A glyphs
+	}{
+		// Every 8 spaces by default.
+		{nil, []fixed.Int26_6{0, fixed.I(40), fixed.I(80), fixed.I(120)}},
+		{&Style{TabStops: []int{12, 30}}, []fixed.Int26_6{0, fixed.I(12), fixed.I(30), fixed.I(40)}},
+		{&Style{TabStops: []int{12, 30}, TabWidth: 16}, []fixed.Int26_6{0, fixed.I(12), fixed.I(30), fixed.I(48)}},
+		{&Style{TabWidth: 16, Scale: 2}, []fixed.Int26_6{0, fixed.I(32), fixed.I(64), fixed.I(96)}},
+	}
+	for _, tt := range tests {
+		l := f.layout("A\tA\tA\tA", tt.style, nil)
+		var got []fixed.Int26_6
+		for _, g := range l.lines[0].glyphs {
+			if g.r == 'A' {
+				got = append(got, g.dot.X)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%+v: got pen positions %v, want %v", tt.style, got, tt.want)
+		}
+	}
+	// The tab stops don't depend on the prescaled variant that is used.
+	scaledTests := []struct {
+		style *Style
+		want  []fixed.Int26_6
+	}{
+		{&Style{Scale: 2}, []fixed.Int26_6{0, fixed.I(80), fixed.I(160), fixed.I(240)}},
+		{&Style{TabStops: []int{12, 30}, Scale: 2}, []fixed.Int26_6{0, fixed.I(24), fixed.I(60), fixed.I(80)}},
+		{&Style{TabWidth: 16, Scale: 2}, []fixed.Int26_6{0, fixed.I(32), fixed.I(64), fixed.I(96)}},
+	}
+	f.Variants = map[float64]*BitmapFont{2: testFont("A ", 2)}
+	for _, tt := range scaledTests {
+		l := f.layout("A\tA\tA\tA", tt.style, nil)
+		if l.font != f.Variants[2] {
+			t.Fatalf("%+v: 2x variant not used", tt.style)
+		}
+		var got []fixed.Int26_6
+		for _, g := range l.lines[0].glyphs {
+			if g.r == 'A' {
+				got = append(got, g.dot.X)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%+v with 2x variant: got pen positions %v, want %v", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestSlant(t *testing.T) {
+	f := opaqueFont("A")
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	// The top of the 6 pixel high glyph is shifted by 3 pixels to the right.
+	f.DrawTextOpts(dst, image.Pt(2, 10), "A", WithSlant(math.Atan(0.5)))
+	tests := []struct {
+		p      image.Point
+		opaque bool
+	}{
+		{image.Pt(3, 9), true},
+		{image.Pt(7, 9), false},
+		{image.Pt(3, 4), false},
+		{image.Pt(7, 4), true},
+	}
+	for _, tt := range tests {
+		if opaque := dst.RGBAAt(tt.p.X, tt.p.Y).A == 0xff; opaque != tt.opaque {
+			t.Errorf("got opaque %v at %v, want %v", opaque, tt.p, tt.opaque)
+		}
+	}
+	if got, want := f.MeasureTextOpts("A", WithSlant(math.Atan(0.5))), image.Rect(0, -6, 7, 0); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+	// Negative angles shear the glyphs to the left.
+	if got, want := f.MeasureTextOpts("A", WithSlant(-math.Atan(0.5))), image.Rect(-3, -6, 4, 0); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+}
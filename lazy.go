@@ -0,0 +1,67 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"sync"
+)
+
+// A pageLoader loads the page sheet images of a font loaded with
+// Loader.LazyPages when they are first requested.
+type pageLoader struct {
+	mu     sync.Mutex
+	loader *Loader
+	desc   *Descriptor
+	sheets SheetReaderFunc
+	// loaded holds the page sheet images loaded so far. It is guarded by
+	// mu and not shared with the PageSheets map of the font, which remains
+	// empty.
+	loaded map[int]image.Image
+	// errs holds the errors of pages that failed to load, so that they
+	// aren't read again each time a glyph on them is drawn.
+	errs map[int]error
+}
+
+func (p *pageLoader) page(id int) (image.Image, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if sheet, ok := p.loaded[id]; ok {
+		return sheet, p.errs[id]
+	}
+	if err, ok := p.errs[id]; ok {
+		return nil, err
+	}
+	if _, ok := p.desc.Pages[id]; !ok {
+		return nil, fmt.Errorf("bmfont: missing page %d", id)
+	}
+	sheet, err := p.loader.readPage(id, p.desc, p.sheets)
+	if err != nil {
+		p.errs[id] = err
+		if !p.loader.Placeholders {
+			return nil, err
+		}
+		sheet = placeholderPage(p.desc.Common.Scale())
+	}
+	p.loaded[id] = sheet
+	return sheet, err
+}
+
+// Page returns the sheet image of the page with the given ID. For fonts
+// loaded with Loader.LazyPages the image is read and decoded when it is
+// first requested, and the error of a page that fails to load is returned
+// on each request, together with a placeholder image if Loader.Placeholders
+// is set.
+func (f *BitmapFont) Page(id int) (image.Image, error) {
+	if f.pages != nil {
+		return f.pages.page(id)
+	}
+	sheet, ok := f.PageSheets[id]
+	if !ok {
+		return nil, fmt.Errorf("bmfont: missing page %d", id)
+	}
+	return sheet, nil
+}
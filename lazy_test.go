@@ -0,0 +1,104 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLazyPagesConcurrent(t *testing.T) {
+	descriptor := `info face="Test" size=8
+common lineHeight=8 base=6 scaleW=8 scaleH=8 pages=2
+page id=0 file="sheet0.png"
+page id=1 file="sheet1.png"
+chars count=2
+char id=65 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0 chnl=15
+char id=66 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=1 chnl=15
+`
+	var sheet bytes.Buffer
+	if err := png.Encode(&sheet, image.NewNRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	l := Loader{LazyPages: true}
+	f, err := l.Read(strings.NewReader(descriptor), func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(sheet.Bytes())), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dst := image.NewNRGBA(image.Rect(0, 0, 32, 16))
+			f.DrawText(dst, image.Pt(0, 8), "ABAB")
+		}()
+	}
+	wg.Wait()
+	if len(f.PageSheets) != 0 {
+		t.Errorf("got %d page sheets, want none for lazy pages", len(f.PageSheets))
+	}
+	for id := 0; id < 2; id++ {
+		if _, err := f.Page(id); err != nil {
+			t.Errorf("page %d: %v", id, err)
+		}
+	}
+}
+
+func TestMeasureWithoutPages(t *testing.T) {
+	d := testFont("AB", 1).Descriptor
+	f := &BitmapFont{Descriptor: d}
+	want := image.Rect(0, -6, 9, 0)
+	if got := f.MeasureText("AB"); got != want {
+		t.Errorf("MeasureText: got %v, want %v", got, want)
+	}
+	if got := f.MeasureLines("AB", nil); len(got) != 1 || got[0].Bounds != want {
+		t.Errorf("MeasureLines: got %v, want bounds %v", got, want)
+	}
+	if got := f.MeasureTextOpts("AB", WithScale(2)); got != image.Rect(0, -12, 18, 0) {
+		t.Errorf("MeasureTextOpts: got %v, want %v", got, image.Rect(0, -12, 18, 0))
+	}
+}
+
+func TestMeasureLazyPages(t *testing.T) {
+	descriptor := `info face="Test" size=8
+common lineHeight=8 base=6 scaleW=8 scaleH=8 pages=1
+page id=0 file="sheet.png"
+chars count=2
+char id=65 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0 chnl=15
+char id=66 x=4 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0 chnl=15
+`
+	var sheet bytes.Buffer
+	if err := png.Encode(&sheet, image.NewNRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	reads := 0
+	l := Loader{LazyPages: true}
+	f, err := l.Read(strings.NewReader(descriptor), func(string) (io.ReadCloser, error) {
+		reads++
+		return io.NopCloser(bytes.NewReader(sheet.Bytes())), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.MeasureText("AB"), image.Rect(0, -6, 9, 0); got != want {
+		t.Errorf("MeasureText: got %v, want %v", got, want)
+	}
+	f.MeasureLines("AB\nBA", &Style{Slant: 0.5})
+	if reads != 0 {
+		t.Errorf("got %d page reads after measuring, want 0", reads)
+	}
+	f.DrawText(image.NewNRGBA(image.Rect(0, 0, 16, 8)), image.Pt(0, 6), "AB")
+	if reads != 1 {
+		t.Errorf("got %d page reads after drawing, want 1", reads)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import "strings"
+
+// ligature returns the ligature glyph for the longest sequence of the font's
+// ligature table that the text starts with, and the length of the sequence
+// in bytes. Ligatures whose glyph is not included in the font or variant v
+// the glyphs are taken from are ignored.
+func (f *BitmapFont) ligature(v *BitmapFont, text string) (r rune, n int, ok bool) {
+	if len(f.Ligatures) == 0 {
+		return 0, 0, false
+	}
+	for seq, lig := range f.Ligatures {
+		if len(seq) <= n || !strings.HasPrefix(text, seq) {
+			continue
+		}
+		if _, found := v.Descriptor.Chars[lig]; found {
+			r, n, ok = lig, len(seq), true
+		}
+	}
+	return r, n, ok
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import "testing"
+
+func TestLigatures(t *testing.T) {
+	const (
+		arrow    = '\uE000'
+		longLig  = '\uE001'
+		fi       = '\uE002'
+		notFound = '\uE003'
+	)
+	f := testFont("ab ->=fiz"+string([]rune{arrow, longLig, fi}), 1)
+	f.Ligatures = map[string]rune{
+		"->":  arrow,
+		"->=": longLig,
+		"fi":  fi,
+		"zz":  notFound,
+	}
+	tests := []struct {
+		text, want string
+	}{
+		{"a->b", "a" + string(arrow) + "b"},
+		{"->=", string(longLig)},
+		{"fifi", string([]rune{fi, fi})},
+		{"f i", "f i"},
+		{"zz", "zz"},
+	}
+	for _, tt := range tests {
+		if got := layoutRunes(f.layout(tt.text, nil, nil))[0]; got != tt.want {
+			t.Errorf("%q: got glyphs %q, want %q", tt.text, got, tt.want)
+		}
+	}
+	// The ligature glyph advances the pen once for the whole sequence.
+	if got, want := f.MeasureText("->").Dx(), f.MeasureText("f").Dx(); got != want {
+		t.Errorf("got width %d for a ligature, want %d", got, want)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// A Run is a piece of text with uniform attributes, as parsed from markup by
+// ParseMarkup.
+type Run struct {
+	Text string
+	// Color is the color the glyphs of the run are tinted with instead of
+	// the color of the style, if it is not nil.
+	Color color.Color
+	// Scale is the factor by which the glyphs of the run are scaled
+	// relative to the scale of the style. If it is 0 they are not scaled.
+	Scale float64
+	// Font is the name of the font of the run in the Fonts of the style.
+	// If it is empty the run is drawn with the font the markup is drawn
+	// with.
+	Font string
+}
+
+// ParseMarkup parses text with markup tags into runs of text with uniform
+// attributes. The tags are enclosed in square brackets and apply to the text
+// up to the matching closing tag or the end of the markup:
+//
+//	[color=#ff0000]...[/color]  tints the text with a color
+//	[scale=2]...[/scale]        scales the text relative to the enclosing text
+//	[font=bold]...[/font]       selects a font of the style by name
+//
+// Colors are given in the format #rgb, #rrggbb or #rrggbbaa, and font names
+// refer to the Fonts of the style the markup is drawn with. Tags can be
+// nested, and a literal opening bracket is written as "[[".
+// Adjacent text with the same attributes is merged into one run.
+func ParseMarkup(markup string) ([]Run, error) {
+	type state struct {
+		tag string
+		run Run
+	}
+	stack := []state{{run: Run{Scale: 1}}}
+	var runs []Run
+	var text strings.Builder
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		run := stack[len(stack)-1].run
+		run.Text = text.String()
+		text.Reset()
+		if n := len(runs); n > 0 && runs[n-1].Color == run.Color && runs[n-1].Scale == run.Scale && runs[n-1].Font == run.Font {
+			runs[n-1].Text += run.Text
+			return
+		}
+		runs = append(runs, run)
+	}
+	for i := 0; i < len(markup); {
+		if markup[i] != '[' {
+			text.WriteByte(markup[i])
+			i++
+			continue
+		}
+		if strings.HasPrefix(markup[i:], "[[") {
+			text.WriteByte('[')
+			i += 2
+			continue
+		}
+		n := strings.IndexByte(markup[i:], ']')
+		if n < 0 {
+			return nil, fmt.Errorf("bmfont: unterminated markup tag at offset %d", i)
+		}
+		tag := markup[i+1 : i+n]
+		flush()
+		if name, ok := strings.CutPrefix(tag, "/"); ok {
+			if len(stack) == 1 || stack[len(stack)-1].tag != name {
+				return nil, fmt.Errorf("bmfont: unexpected markup tag [%s] at offset %d", tag, i)
+			}
+			stack = stack[:len(stack)-1]
+			i += n + 1
+			continue
+		}
+		name, value, _ := strings.Cut(tag, "=")
+		run := stack[len(stack)-1].run
+		var err error
+		switch name {
+		case "color":
+			run.Color, err = parseHexColor(value)
+		case "scale":
+			var k float64
+			k, err = strconv.ParseFloat(value, 64)
+			if err == nil && k <= 0 {
+				err = errors.New("scale must be positive")
+			}
+			run.Scale *= k
+		case "font":
+			if value == "" {
+				err = errors.New("missing font name")
+			}
+			run.Font = value
+		default:
+			err = errors.New("unknown tag")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bmfont: invalid markup tag [%s] at offset %d: %w", tag, i, err)
+		}
+		stack = append(stack, state{tag: name, run: run})
+		i += n + 1
+	}
+	flush()
+	return runs, nil
+}
+
+// parseHexColor parses a color in the format #rgb, #rrggbb or #rrggbbaa.
+func parseHexColor(s string) (color.Color, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if !ok || len(hex) != 8 || err != nil {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// DrawMarkup draws the given text with markup, see ParseMarkup, with the
+// given style on the destination image like DrawText. The attributes of the
+// markup override those of the style for the text they apply to. The glyphs
+// of all runs are aligned on common base lines, and lines with larger glyphs
+// are spaced further apart. It returns an error if the markup is invalid or
+// refers to a font that is not in the Fonts of the style.
+func (f *BitmapFont) DrawMarkup(dst draw.Image, pos image.Point, markup string, style *Style) error {
+	l, err := f.layoutMarkup(markup, style, &f.stats)
+	if err != nil {
+		return err
+	}
+	f.drawLayout(dst, pos, l, style)
+	return nil
+}
+
+// MeasureMarkup calculates the bounding box for the given text with markup
+// as if it was drawn at position (0, 0) with DrawMarkup.
+func (f *BitmapFont) MeasureMarkup(markup string, style *Style) (image.Rectangle, error) {
+	l, err := f.layoutMarkup(markup, style, nil)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	return measureLayout(l, style), nil
+}
+
+func (f *BitmapFont) layoutMarkup(markup string, style *Style, stats *fontStats) (*layout, error) {
+	runs, err := ParseMarkup(markup)
+	if err != nil {
+		return nil, err
+	}
+	return f.layoutRuns(runs, style, stats)
+}
+
+// layoutRuns lays out the runs of text like layout, with the glyphs of each
+// run taken from its font and drawn with its color and scale.
+func (f *BitmapFont) layoutRuns(runs []Run, style *Style, stats *fontStats) (*layout, error) {
+	// A segment is the transformed text of a run within the whole text.
+	type segment struct {
+		start, end int
+		// base is the font of the run, and font is the variant of it the
+		// glyphs are taken from.
+		base, font *BitmapFont
+		scale      float64
+		style      *Style
+		color      color.Color
+	}
+	var base Style
+	if style != nil {
+		base = *style
+	}
+	var text strings.Builder
+	segments := make([]segment, 0, len(runs))
+	for _, run := range runs {
+		font := f
+		if run.Font != "" {
+			font = base.Fonts[run.Font]
+			if font == nil {
+				return nil, fmt.Errorf("bmfont: unknown font %q in markup", run.Font)
+			}
+		}
+		rs := base
+		if run.Scale > 0 {
+			rs.Scale = run.Scale
+			if base.Scale != 0 {
+				rs.Scale *= base.Scale
+			}
+		}
+		if run.Color != nil {
+			rs.Color = run.Color
+		}
+		c := rs.color()
+		if rs.OutlineColor != nil {
+			c = nil
+			if run.Color != nil {
+				c = rs.fade(run.Color)
+			}
+		}
+		v, s := font.variant(&rs)
+		t := rs.transform(run.Text)
+		segments = append(segments, segment{
+			start: text.Len(),
+			end:   text.Len() + len(t),
+			base:  font,
+			font:  v,
+			scale: s,
+			style: &rs,
+			color: c,
+		})
+		text.WriteString(t)
+	}
+
+	v, s := f.variant(style)
+	l := newLayout(v, s, style)
+	l.colored = true
+	defaultAdvance := style.lineAdvance(v.Descriptor.Common.LineHeight, s)
+	maxWidth := fixed.I(style.maxWidth())
+	plain := text.String()
+	var y, prevAdvance fixed.Int26_6
+	for start := 0; start <= len(plain); {
+		end := len(plain)
+		if i := strings.IndexByte(plain[start:], '\n'); i >= 0 {
+			end = start + i
+		}
+		var glyphs []glyph
+		var x fixed.Int26_6
+		for _, seg := range segments {
+			a, b := max(seg.start, start), min(seg.end, end)
+			if a >= b {
+				continue
+			}
+			gs := seg.font.shape(seg.base, plain, a, b, seg.style, seg.scale, x, stats)
+			for i := range gs {
+				if gs[i].image == nil {
+					gs[i].color = seg.color
+				}
+			}
+			if len(gs) > 0 {
+				x = gs[len(gs)-1].dot.X + gs[len(gs)-1].advance
+			}
+			glyphs = append(glyphs, gs...)
+		}
+		lines := v.breakParagraph(glyphs, start, end, maxWidth, style, s)
+		for _, ln := range lines {
+			advance := defaultAdvance
+			if len(ln.glyphs) > 0 {
+				advance = 0
+				for _, g := range ln.glyphs {
+					advance = max(advance, style.lineAdvance(g.font.Descriptor.Common.LineHeight, g.scale))
+				}
+			}
+			// Consecutive base lines are spaced by the larger of the line
+			// advances of both lines.
+			if len(l.lines) > 0 {
+				y += max(prevAdvance, advance)
+			}
+			ln.y = style.baseline(y)
+			for i := range ln.glyphs {
+				ln.glyphs[i].dot.Y = ln.y
+			}
+			l.lines = append(l.lines, ln)
+			prevAdvance = advance
+		}
+		start = end + 1
+	}
+	l.limitLines(style, maxWidth)
+	l.align(style.align(), maxWidth, style.justifyCharacters())
+	return l, nil
+}
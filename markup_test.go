@@ -0,0 +1,115 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkup(t *testing.T) {
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	tests := []struct {
+		markup string
+		want   []Run
+	}{
+		{"", nil},
+		{"plain", []Run{{Text: "plain", Scale: 1}}},
+		{
+			"a[color=#f00]b[/color]c",
+			[]Run{{Text: "a", Scale: 1}, {Text: "b", Color: red, Scale: 1}, {Text: "c", Scale: 1}},
+		},
+		{
+			"[scale=2]a[scale=1.5]b[/scale][/scale]",
+			[]Run{{Text: "a", Scale: 2}, {Text: "b", Scale: 3}},
+		},
+		{
+			"[font=bold]a[color=#ff0000]b[/color][/font]",
+			[]Run{{Text: "a", Scale: 1, Font: "bold"}, {Text: "b", Color: red, Scale: 1, Font: "bold"}},
+		},
+		{"a[[b]", []Run{{Text: "a[b]", Scale: 1}}},
+		// Adjacent runs with the same attributes are merged.
+		{"[color=#f00]a[/color][color=#ff0000]b", []Run{{Text: "ab", Color: red, Scale: 1}}},
+		{"[color=#11223344]x", []Run{{Text: "x", Color: color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0x44}, Scale: 1}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseMarkup(tt.markup)
+		if err != nil {
+			t.Errorf("%q: %v", tt.markup, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got runs %+v, want %+v", tt.markup, got, tt.want)
+		}
+	}
+}
+
+func TestParseMarkupErrors(t *testing.T) {
+	tests := []struct {
+		markup, want string
+	}{
+		{"a[color=#f00", "bmfont: unterminated markup tag at offset 1"},
+		{"a[/color]", "bmfont: unexpected markup tag [/color] at offset 1"},
+		{"[color=#f00]a[/scale]", "bmfont: unexpected markup tag [/scale] at offset 13"},
+		{"[color=red]", `bmfont: invalid markup tag [color=red] at offset 0: invalid color "red"`},
+		{"[color=#ff00]", `bmfont: invalid markup tag [color=#ff00] at offset 0: invalid color "#ff00"`},
+		{"[scale=0]", "bmfont: invalid markup tag [scale=0] at offset 0: scale must be positive"},
+		{"[font=]", "bmfont: invalid markup tag [font=] at offset 0: missing font name"},
+		{"[b]", "bmfont: invalid markup tag [b] at offset 0: unknown tag"},
+	}
+	for _, tt := range tests {
+		_, err := ParseMarkup(tt.markup)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%q: got error %v, want %q", tt.markup, err, tt.want)
+		}
+	}
+}
+
+func TestDrawMarkup(t *testing.T) {
+	f := opaqueFont("AB")
+	bold := opaqueFont("AB")
+	bold.Descriptor.Common.LineHeight = 12
+	style := &Style{Fonts: map[string]*BitmapFont{"bold": bold}}
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	if err := f.DrawMarkup(dst, image.Pt(0, 6), "A[color=#00f]B[/color]", style); err != nil {
+		t.Fatal(err)
+	}
+	if got := dst.RGBAAt(1, 1); got != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("got %v, want white", got)
+	}
+	if got := dst.RGBAAt(6, 1); got != (color.RGBA{B: 0xff, A: 0xff}) {
+		t.Errorf("got %v, want blue", got)
+	}
+
+	tests := []struct {
+		markup string
+		want   image.Rectangle
+	}{
+		{"AB", f.MeasureText("AB")},
+		{"A[scale=2]B", image.Rect(0, -12, 13, 0)},
+		// Lines with larger glyphs are spaced further apart.
+		{"A\n[scale=2]B", image.Rect(0, -6, 8, 16)},
+		{"A\n[font=bold]B", image.Rect(0, -6, 4, 12)},
+	}
+	for _, tt := range tests {
+		got, err := f.MeasureMarkup(tt.markup, style)
+		if err != nil {
+			t.Errorf("%q: %v", tt.markup, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got bounds %v, want %v", tt.markup, got, tt.want)
+		}
+	}
+
+	if _, err := f.MeasureMarkup("[font=italic]A", style); err == nil {
+		t.Error("unknown font: got no error")
+	}
+	if err := f.DrawMarkup(dst, image.Point{}, "[b]A", style); err == nil {
+		t.Error("invalid markup: got no error")
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"fmt"
+	"maps"
+)
+
+// MergeOptions configure MergeDescriptors.
+// A nil *MergeOptions is valid and selects the defaults.
+type MergeOptions struct {
+	// Conflict selects how characters and kerning pairs that are defined by
+	// both descriptors are resolved. By default the definitions of the first
+	// descriptor are kept.
+	Conflict MergeConflict
+}
+
+// MergeConflict is a policy for resolving characters and kerning pairs that
+// are defined by both merged descriptors.
+type MergeConflict int
+
+const (
+	KeepFirst     MergeConflict = iota // keep the definition of the first descriptor
+	KeepSecond                         // keep the definition of the second descriptor
+	ConflictError                      // fail with an error
+)
+
+// MergeDescriptors combines the characters of two descriptors, e.g. of a
+// Latin base font and a CJK extension exported separately, into a new
+// descriptor. The info and common attributes are taken from a. Both
+// descriptors must have the same page size and must either both or neither
+// be packed.
+//
+// The pages of b are renumbered to follow the pages of a: a page of b with
+// ID id gets the ID id+n, where n is one more than the highest page ID of a,
+// and the characters of b are updated accordingly. The page files of the
+// descriptors must be distinct. The kerning class IDs of b are offset in the
+// same way, so that the class-based kerning of both descriptors is kept.
+func MergeDescriptors(a, b *Descriptor, opts *MergeOptions) (*Descriptor, error) {
+	if opts == nil {
+		opts = &MergeOptions{}
+	}
+	if a.Common.Scale() != b.Common.Scale() {
+		return nil, fmt.Errorf("bmfont: can't merge descriptors with page sizes %dx%d and %dx%d",
+			a.Common.ScaleW, a.Common.ScaleH, b.Common.ScaleW, b.Common.ScaleH)
+	}
+	if a.Common.Packed != b.Common.Packed {
+		return nil, fmt.Errorf("bmfont: can't merge packed and unpacked descriptors")
+	}
+	d := &Descriptor{
+		Info:           a.Info,
+		Common:         a.Common,
+		Pages:          maps.Clone(a.Pages),
+		Chars:          maps.Clone(a.Chars),
+		Kerning:        maps.Clone(a.Kerning),
+		KerningClasses: maps.Clone(a.KerningClasses),
+		ClassKerning:   maps.Clone(a.ClassKerning),
+		Features:       a.Features | b.Features,
+	}
+	if d.Pages == nil {
+		d.Pages = make(map[int]Page)
+	}
+	if d.Chars == nil {
+		d.Chars = make(map[rune]Char)
+	}
+	if d.Kerning == nil {
+		d.Kerning = make(map[CharPair]Kerning)
+	}
+
+	pageOffset := 0
+	files := make(map[string]bool)
+	for id, page := range a.Pages {
+		pageOffset = max(pageOffset, id+1)
+		files[page.File] = true
+	}
+	for _, id := range sortedKeys(b.Pages) {
+		page := b.Pages[id]
+		if files[page.File] {
+			return nil, fmt.Errorf("bmfont: page file %q is used by both descriptors", page.File)
+		}
+		page.ID = id + pageOffset
+		d.Pages[page.ID] = page
+	}
+
+	classOffset := 0
+	for pair := range a.ClassKerning {
+		classOffset = max(classOffset, pair.First, pair.Second)
+	}
+	for _, classes := range a.KerningClasses {
+		classOffset = max(classOffset, classes.First, classes.Second)
+	}
+
+	for _, r := range sortedKeys(b.Chars) {
+		if _, ok := d.Chars[r]; ok {
+			if opts.Conflict == ConflictError {
+				return nil, fmt.Errorf("bmfont: character %U is defined by both descriptors", r)
+			}
+			if opts.Conflict == KeepFirst {
+				continue
+			}
+		}
+		ch := b.Chars[r]
+		ch.Page += pageOffset
+		d.Chars[r] = ch
+		if classes, ok := b.KerningClasses[r]; ok {
+			if d.KerningClasses == nil {
+				d.KerningClasses = make(map[rune]CharClasses)
+			}
+			d.KerningClasses[r] = CharClasses{
+				First:  offsetClass(classes.First, classOffset),
+				Second: offsetClass(classes.Second, classOffset),
+			}
+		} else {
+			delete(d.KerningClasses, r)
+		}
+	}
+	for _, pair := range b.kerningPairs() {
+		if _, ok := d.Kerning[pair]; ok {
+			if opts.Conflict == ConflictError {
+				return nil, fmt.Errorf("bmfont: kerning pair %U %U is defined by both descriptors", pair.First, pair.Second)
+			}
+			if opts.Conflict == KeepFirst {
+				continue
+			}
+		}
+		d.Kerning[pair] = b.Kerning[pair]
+	}
+	for pair, k := range b.ClassKerning {
+		if d.ClassKerning == nil {
+			d.ClassKerning = make(map[ClassPair]Kerning)
+		}
+		d.ClassKerning[ClassPair{First: pair.First + classOffset, Second: pair.Second + classOffset}] = k
+	}
+	return d, nil
+}
+
+// offsetClass offsets a kerning class ID, keeping the ID 0 for no class.
+func offsetClass(id, offset int) int {
+	if id == 0 {
+		return 0
+	}
+	return id + offset
+}
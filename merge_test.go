@@ -0,0 +1,108 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"strings"
+	"testing"
+)
+
+// mergeTestDescriptors returns a descriptor with the chars A and B on page 0
+// and a descriptor with the chars B and C on pages 0 and 1.
+func mergeTestDescriptors(t *testing.T) (a, b *Descriptor) {
+	t.Helper()
+	a, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = ReadDescriptor(strings.NewReader(`info face="Ext" size=8
+common lineHeight=8 base=6 scaleW=16 scaleH=8 pages=2
+page id=0 file="ext_0.png"
+page id=1 file="ext_1.png"
+char id=66 x=8 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=7 page=0
+char id=67 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=1
+kerning first=65 second=66 amount=-2
+kerning first=66 second=67 amount=-1
+kernclass id=1 side="first" chars="C"
+kernclass id=1 side="second" chars="B"
+classkerning first=1 second=1 amount=-3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+func TestMergeDescriptors(t *testing.T) {
+	a, b := mergeTestDescriptors(t)
+	d, err := MergeDescriptors(a, b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Info.Face != "Test" {
+		t.Errorf("got face %q, want the face of the first descriptor", d.Info.Face)
+	}
+	if len(d.Pages) != 3 || d.Pages[1].File != "ext_0.png" || d.Pages[2].File != "ext_1.png" || d.Pages[2].ID != 2 {
+		t.Errorf("got pages %v, want the pages of b renumbered to 1 and 2", d.Pages)
+	}
+	if ch := d.Chars['B']; ch.XAdvance != 5 || ch.Page != 0 {
+		t.Errorf("got char B %+v, want the char of the first descriptor", ch)
+	}
+	if ch := d.Chars['C']; ch.Page != 2 {
+		t.Errorf("got char C on page %d, want 2", ch.Page)
+	}
+	if k := d.Kerning[CharPair{First: 'A', Second: 'B'}]; k.Amount != -1 {
+		t.Errorf("got kerning A B %d, want -1 of the first descriptor", k.Amount)
+	}
+	if k := d.Kerning[CharPair{First: 'B', Second: 'C'}]; k.Amount != -1 {
+		t.Errorf("got kerning B C %d, want -1 of the second descriptor", k.Amount)
+	}
+	// B keeps the kerning classes of the first descriptor, which has none.
+	if got := d.KerningClasses['C']; got != (CharClasses{First: 1}) {
+		t.Errorf("got kerning classes %+v for C, want %+v", got, CharClasses{First: 1})
+	}
+	if got := d.KerningClasses['B']; got != (CharClasses{}) {
+		t.Errorf("got kerning classes %+v for B, want none", got)
+	}
+	if len(a.Pages) != 1 || len(a.Chars) != 2 {
+		t.Error("MergeDescriptors modified the first descriptor")
+	}
+}
+
+func TestMergeDescriptorsKeepSecond(t *testing.T) {
+	a, b := mergeTestDescriptors(t)
+	d, err := MergeDescriptors(a, b, &MergeOptions{Conflict: KeepSecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch := d.Chars['B']; ch.XAdvance != 7 || ch.Page != 1 {
+		t.Errorf("got char B %+v, want the char of the second descriptor on page 1", ch)
+	}
+	if k := d.Kerning[CharPair{First: 'A', Second: 'B'}]; k.Amount != -2 {
+		t.Errorf("got kerning A B %d, want -2 of the second descriptor", k.Amount)
+	}
+	if amount, _ := d.kerning('C', 'B'); amount.Round() != -3 {
+		t.Errorf("got class kerning C B %v, want -3", amount)
+	}
+}
+
+func TestMergeDescriptorsErrors(t *testing.T) {
+	a, b := mergeTestDescriptors(t)
+	if _, err := MergeDescriptors(a, b, &MergeOptions{Conflict: ConflictError}); err == nil {
+		t.Error("conflicting chars: got no error")
+	}
+	b.Pages[0] = Page{ID: 0, File: "sheet.png"}
+	if _, err := MergeDescriptors(a, b, nil); err == nil {
+		t.Error("shared page file: got no error")
+	}
+	b.Common.ScaleW = 32
+	if _, err := MergeDescriptors(a, b, nil); err == nil {
+		t.Error("different page sizes: got no error")
+	}
+	b.Common.ScaleW, b.Common.Packed = 16, true
+	if _, err := MergeDescriptors(a, b, nil); err == nil {
+		t.Error("packed and unpacked: got no error")
+	}
+}
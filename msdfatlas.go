@@ -0,0 +1,168 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// ReadMSDFAtlasJSON reads the JSON layout written by msdf-atlas-gen and
+// converts it to a font descriptor with a single page, whose sheet is the
+// atlas image written alongside the layout, with the given file name.
+//
+// The metrics of the layout are in em units and are converted to pixels
+// using the glyph size of the atlas: the common line height and base are
+// taken from the line height and ascender of the metrics, the characters
+// from the plane and atlas bounds of the glyphs, which are rounded outwards
+// to whole pixels, and the kerning pairs from the kerning advances. Both y
+// origins of the atlas, "bottom" and "top", are supported. Glyphs without a
+// Unicode code point are skipped. If the layout has multiple font variants,
+// only the first one is read.
+//
+// The characters use all channels of the atlas image, which hold the
+// distance field rather than glyph coverage, so the font is meant to be
+// drawn with a distance field shader, e.g. using the quads reported by
+// Quads. The type and the distance range of the atlas are kept in the Extra
+// attributes of the common tag as "fieldType" and "distanceRange".
+func ReadMSDFAtlasJSON(r io.Reader, pageFile string) (*Descriptor, error) {
+	var layout msdfLayout
+	if err := json.NewDecoder(r).Decode(&layout); err != nil {
+		return nil, fmt.Errorf("bmfont: invalid msdf-atlas-gen layout: %w", err)
+	}
+	if len(layout.Variants) > 0 {
+		layout.msdfVariant = layout.Variants[0]
+	}
+	atlas, metrics := layout.Atlas, layout.Metrics
+	if atlas.Size <= 0 || atlas.Width <= 0 || atlas.Height <= 0 {
+		return nil, errors.New("bmfont: msdf-atlas-gen layout is missing the atlas size")
+	}
+	emSize := metrics.EmSize
+	if emSize == 0 {
+		emSize = 1
+	}
+	px := atlas.Size / emSize
+	// yDown flips the vertical coordinates of the layout to point
+	// downwards, like those of the BMFont format.
+	yDown, atlasY := -1.0, func(y float64) float64 { return float64(atlas.Height) - y }
+	if atlas.YOrigin == "top" {
+		yDown, atlasY = 1, func(y float64) float64 { return y }
+	}
+	base := int(math.Round(-yDown * metrics.Ascender * px))
+
+	desc := &Descriptor{
+		Info: Info{
+			Face:     layout.Name,
+			Size:     int(math.Round(atlas.Size)),
+			Unicode:  true,
+			StretchH: 100,
+			Smooth:   true,
+			AA:       1,
+		},
+		Common: Common{
+			LineHeight: int(math.Round(metrics.LineHeight * px)),
+			Base:       base,
+			ScaleW:     atlas.Width,
+			ScaleH:     atlas.Height,
+			Extra: map[string]string{
+				"fieldType":     atlas.Type,
+				"distanceRange": strconv.FormatFloat(atlas.DistanceRange, 'g', -1, 64),
+			},
+		},
+		Pages:    map[int]Page{0: {ID: 0, File: pageFile}},
+		Chars:    make(map[rune]Char, len(layout.Glyphs)),
+		Kerning:  make(map[CharPair]Kerning, len(layout.Kerning)),
+		Features: FeatureDistanceField | FeatureCharChannels,
+	}
+	for _, g := range layout.Glyphs {
+		if g.Unicode == nil {
+			continue
+		}
+		ch := Char{ID: rune(*g.Unicode), Channel: All}
+		var offset fixed.Point26_6
+		if ab, pb := g.AtlasBounds, g.PlaneBounds; ab != nil && pb != nil {
+			top, bottom := atlasY(ab.Top), atlasY(ab.Bottom)
+			if top > bottom {
+				top, bottom = bottom, top
+			}
+			x0, y0 := math.Floor(ab.Left), math.Floor(top)
+			ch.X, ch.Y = int(x0), int(y0)
+			ch.Width = int(math.Ceil(ab.Right)) - ch.X
+			ch.Height = int(math.Ceil(bottom)) - ch.Y
+			planeTop := min(yDown*pb.Top, yDown*pb.Bottom)
+			offset = fixed.Point26_6{
+				X: toFixed(pb.Left*px - (ab.Left - x0)),
+				Y: toFixed(float64(base) + planeTop*px - (top - y0)),
+			}
+		}
+		ch.setExact(offset, toFixed(g.Advance*px))
+		desc.Chars[ch.ID] = ch
+	}
+	for _, k := range layout.Kerning {
+		pair := CharPair{First: rune(k.Unicode1), Second: rune(k.Unicode2)}
+		desc.Kerning[pair] = exactKerning(toFixed(k.Advance * px))
+	}
+	return desc, nil
+}
+
+// toFixed converts a value in pixels to a fixed-point value.
+func toFixed(v float64) fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(v * 64))
+}
+
+type msdfLayout struct {
+	Atlas msdfAtlas `json:"atlas"`
+	msdfVariant
+	Variants []msdfVariant `json:"variants"`
+}
+
+type msdfAtlas struct {
+	Type          string  `json:"type"`
+	DistanceRange float64 `json:"distanceRange"`
+	Size          float64 `json:"size"`
+	Width         int     `json:"width"`
+	Height        int     `json:"height"`
+	YOrigin       string  `json:"yOrigin"`
+}
+
+type msdfVariant struct {
+	Name    string        `json:"name"`
+	Metrics msdfMetrics   `json:"metrics"`
+	Glyphs  []msdfGlyph   `json:"glyphs"`
+	Kerning []msdfKerning `json:"kerning"`
+}
+
+type msdfMetrics struct {
+	EmSize     float64 `json:"emSize"`
+	LineHeight float64 `json:"lineHeight"`
+	Ascender   float64 `json:"ascender"`
+	Descender  float64 `json:"descender"`
+}
+
+type msdfGlyph struct {
+	Unicode     *int        `json:"unicode"`
+	Advance     float64     `json:"advance"`
+	PlaneBounds *msdfBounds `json:"planeBounds"`
+	AtlasBounds *msdfBounds `json:"atlasBounds"`
+}
+
+type msdfBounds struct {
+	Left   float64 `json:"left"`
+	Bottom float64 `json:"bottom"`
+	Right  float64 `json:"right"`
+	Top    float64 `json:"top"`
+}
+
+type msdfKerning struct {
+	Unicode1 int     `json:"unicode1"`
+	Unicode2 int     `json:"unicode2"`
+	Advance  float64 `json:"advance"`
+}
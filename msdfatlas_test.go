@@ -0,0 +1,84 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestReadMSDFAtlasJSON(t *testing.T) {
+	// Layouts of the same glyph with both y origins; the A is 16×24 pixels
+	// at the top left corner of the atlas and sits on the base line.
+	layouts := map[string]string{
+		"bottom": `{
+			"atlas": {"type": "msdf", "distanceRange": 4, "size": 32, "width": 64, "height": 32, "yOrigin": "bottom"},
+			"metrics": {"emSize": 1, "lineHeight": 1.25, "ascender": 0.75, "descender": -0.25},
+			"glyphs": [
+				{"unicode": 65, "advance": 0.515625,
+				 "planeBounds": {"left": 0, "bottom": 0, "right": 0.5, "top": 0.75},
+				 "atlasBounds": {"left": 0, "bottom": 8, "right": 16, "top": 32}},
+				{"unicode": 32, "advance": 0.25},
+				{"index": 3, "advance": 0.5}
+			],
+			"kerning": [{"unicode1": 65, "unicode2": 65, "advance": -0.0625}]
+		}`,
+		"top": `{
+			"atlas": {"type": "msdf", "distanceRange": 4, "size": 32, "width": 64, "height": 32, "yOrigin": "top"},
+			"variants": [{
+				"metrics": {"emSize": 1, "lineHeight": 1.25, "ascender": -0.75, "descender": 0.25},
+				"glyphs": [
+					{"unicode": 65, "advance": 0.515625,
+					 "planeBounds": {"left": 0, "bottom": 0, "right": 0.5, "top": -0.75},
+					 "atlasBounds": {"left": 0, "bottom": 24, "right": 16, "top": 0}},
+					{"unicode": 32, "advance": 0.25}
+				],
+				"kerning": [{"unicode1": 65, "unicode2": 65, "advance": -0.0625}]
+			}]
+		}`,
+	}
+	for origin, layout := range layouts {
+		d, err := ReadMSDFAtlasJSON(strings.NewReader(layout), "atlas.png")
+		if err != nil {
+			t.Fatalf("%s: %v", origin, err)
+		}
+		if c := d.Common; c.LineHeight != 40 || c.Base != 24 || c.ScaleW != 64 || c.ScaleH != 32 {
+			t.Errorf("%s: got common %+v", origin, c)
+		}
+		if d.Common.Extra["distanceRange"] != "4" || d.Common.Extra["fieldType"] != "msdf" {
+			t.Errorf("%s: got extra attributes %v", origin, d.Common.Extra)
+		}
+		if d.Pages[0].File != "atlas.png" || !d.Features.Has(FeatureDistanceField) {
+			t.Errorf("%s: got pages %v and features %v", origin, d.Pages, d.Features)
+		}
+		if len(d.Chars) != 2 {
+			t.Errorf("%s: got %d chars, want 2", origin, len(d.Chars))
+		}
+		ch := d.Chars['A']
+		if ch.X != 0 || ch.Y != 0 || ch.Width != 16 || ch.Height != 24 || ch.XOffset != 0 || ch.YOffset != 0 || ch.Channel != All {
+			t.Errorf("%s: got char A %+v", origin, ch)
+		}
+		if got, want := ch.exactAdvance(), fixed.I(16)+32; got != want {
+			t.Errorf("%s: got exact advance %v, want %v", origin, got, want)
+		}
+		if got := d.Kerning[CharPair{First: 'A', Second: 'A'}].Amount; got != -2 {
+			t.Errorf("%s: got kerning amount %d, want -2", origin, got)
+		}
+	}
+}
+
+func TestReadMSDFAtlasJSONErrors(t *testing.T) {
+	for _, input := range []string{
+		`{"atlas": {"size": 32}}`,
+		`{"atlas": "msdf"}`,
+		testDescriptor,
+	} {
+		if _, err := ReadMSDFAtlasJSON(strings.NewReader(input), "atlas.png"); err == nil {
+			t.Errorf("%q: got no error", input)
+		}
+	}
+}
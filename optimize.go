@@ -0,0 +1,186 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"maps"
+	"sort"
+)
+
+// OptimizeOptions configure the passes of Optimize.
+// A nil *OptimizeOptions is valid and selects the defaults.
+type OptimizeOptions struct {
+	// Trim removes the fully transparent borders around the glyph bitmaps
+	// and adjusts the character offsets accordingly.
+	Trim bool
+	// Dedupe stores glyph bitmaps with identical pixels only once, shared
+	// by all characters using them.
+	Dedupe bool
+	// MaxPageSize is the maximum size of the new page sheets. If it is zero
+	// the page size of the font is used as the maximum.
+	MaxPageSize image.Point
+	// Spacing is the space in pixels between the glyphs in the new page
+	// sheets. If it is 0 a spacing of 1 pixel is used.
+	Spacing int
+	// PageFile returns the file name for the page with the given ID. If it
+	// is nil the pages are named "page_0.png", "page_1.png" and so on.
+	PageFile func(id int) string
+}
+
+// Optimize creates a copy of the font with its glyphs repacked into as few
+// and as small page sheets as possible. The page size is the smallest power
+// of two size up to the maximum page size that holds all glyphs on a single
+// page, or else the maximum page size. Before the glyphs are repacked they
+// are optionally trimmed and deduplicated. Packed fonts, which store glyphs
+// in individual color channels, are not supported.
+func Optimize(f *BitmapFont, opts *OptimizeOptions) (*BitmapFont, error) {
+	if opts == nil {
+		opts = &OptimizeOptions{}
+	}
+	src := f.Descriptor
+	if src.Common.Packed {
+		return nil, errors.New("bmfont: can't optimize packed fonts")
+	}
+	maxSize := opts.MaxPageSize
+	if maxSize == (image.Point{}) {
+		maxSize = image.Pt(src.Common.ScaleW, src.Common.ScaleH)
+	}
+	spacing := opts.Spacing
+	if spacing <= 0 {
+		spacing = 1
+	}
+	pageFile := opts.PageFile
+	if pageFile == nil {
+		pageFile = func(id int) string {
+			return fmt.Sprintf("page_%d.png", id)
+		}
+	}
+
+	desc := &Descriptor{
+		Info:           src.Info,
+		Common:         src.Common,
+		Pages:          make(map[int]Page),
+		Chars:          make(map[rune]Char, len(src.Chars)),
+		Kerning:        make(map[CharPair]Kerning, len(src.Kerning)),
+		Features:       src.Features,
+		KerningClasses: maps.Clone(src.KerningClasses),
+		ClassKerning:   maps.Clone(src.ClassKerning),
+	}
+	for pair, k := range src.Kerning {
+		desc.Kerning[pair] = k
+	}
+	if opts.Trim {
+		desc.Info.Padding = Padding{}
+	}
+
+	// Extract the glyph bitmaps.
+	var bitmaps []*image.RGBA
+	bitmapOf := make(map[rune]int)
+	seen := make(map[string]int)
+	for _, r := range sortedKeys(src.Chars) {
+		ch := src.Chars[r]
+		sheet, err := f.Page(ch.Page)
+		if err != nil {
+			return nil, err
+		}
+		bitmap := image.NewRGBA(image.Rectangle{Max: ch.Size()})
+		draw.Draw(bitmap, bitmap.Bounds(), sheet, ch.Pos(), draw.Src)
+		if opts.Trim {
+			opaque := opaqueBounds(bitmap)
+			ch.XOffset += opaque.Min.X
+			ch.YOffset += opaque.Min.Y
+			ch.Width, ch.Height = opaque.Dx(), opaque.Dy()
+			bitmap = bitmap.SubImage(opaque).(*image.RGBA)
+			trimmed := image.NewRGBA(image.Rectangle{Max: opaque.Size()})
+			draw.Draw(trimmed, trimmed.Bounds(), bitmap, opaque.Min, draw.Src)
+			bitmap = trimmed
+		}
+		desc.Chars[r] = ch
+		if opts.Dedupe {
+			key := fmt.Sprintf("%dx%d:%s", ch.Width, ch.Height, bitmap.Pix)
+			if i, ok := seen[key]; ok {
+				bitmapOf[r] = i
+				continue
+			}
+			seen[key] = len(bitmaps)
+		}
+		bitmapOf[r] = len(bitmaps)
+		bitmaps = append(bitmaps, bitmap)
+	}
+
+	// Repack them into as few and small pages as possible.
+	sizes := make([]image.Point, len(bitmaps))
+	for i, b := range bitmaps {
+		sizes[i] = b.Bounds().Size()
+	}
+	pageSize, placed, used, err := packSmallest(sizes, maxSize, spacing)
+	if err != nil {
+		return nil, err
+	}
+	out := &BitmapFont{
+		Descriptor: desc,
+		PageSheets: make(map[int]image.Image),
+	}
+	desc.Common.ScaleW, desc.Common.ScaleH = pageSize.X, pageSize.Y
+	for id := range used {
+		desc.Pages[id] = Page{ID: id, File: pageFile(id)}
+		out.PageSheets[id] = image.NewRGBA(image.Rectangle{Max: pageSize})
+	}
+	for i, p := range placed {
+		sheet := out.PageSheets[p.page].(*image.RGBA)
+		draw.Draw(sheet, image.Rectangle{Min: p.pos, Max: p.pos.Add(sizes[i])}, bitmaps[i], image.Point{}, draw.Src)
+	}
+	for r, i := range bitmapOf {
+		ch := desc.Chars[r]
+		ch.X, ch.Y, ch.Page = placed[i].pos.X, placed[i].pos.Y, placed[i].page
+		desc.Chars[r] = ch
+	}
+	return out, nil
+}
+
+// packSmallest packs the rectangles into a single page of the smallest power
+// of two size up to the maximum size, or into multiple pages of the maximum
+// size if they don't fit into a single page.
+func packSmallest(sizes []image.Point, maxSize image.Point, spacing int) (image.Point, []packedRect, []image.Point, error) {
+	var candidates []image.Point
+	for w := 16; w <= maxSize.X; w *= 2 {
+		for h := 16; h <= maxSize.Y; h *= 2 {
+			candidates = append(candidates, image.Pt(w, h))
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].X*candidates[i].Y < candidates[j].X*candidates[j].Y
+	})
+	for _, size := range candidates {
+		placed, used, err := packRects(sizes, size, spacing)
+		if err == nil && len(used) == 1 {
+			return size, placed, used, nil
+		}
+	}
+	placed, used, err := packRects(sizes, maxSize, spacing)
+	if err != nil {
+		return image.Point{}, nil, nil, fmt.Errorf("bmfont: glyphs don't fit into pages of size %dx%d", maxSize.X, maxSize.Y)
+	}
+	return maxSize, placed, used, nil
+}
+
+// opaqueBounds returns the smallest rectangle containing all pixels of the
+// image that are not fully transparent.
+func opaqueBounds(img *image.RGBA) image.Rectangle {
+	var r image.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.Pix[img.PixOffset(x, y)+3] != 0 {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"testing"
+)
+
+// paddedFont returns a font with the chars A and B, whose 8×8 pixel
+// bitmaps have the same opaque 4×4 pixel box surrounded by a transparent
+// border of 2 pixels.
+func paddedFont() *BitmapFont {
+	sheet := image.NewNRGBA(image.Rect(0, 0, 32, 8))
+	draw.Draw(sheet, image.Rect(2, 2, 6, 6), image.White, image.Point{}, draw.Src)
+	draw.Draw(sheet, image.Rect(10, 2, 14, 6), image.White, image.Point{}, draw.Src)
+	return &BitmapFont{
+		Descriptor: &Descriptor{
+			Common: Common{LineHeight: 8, Base: 8, ScaleW: 64, ScaleH: 64},
+			Pages:  map[int]Page{0: {ID: 0, File: "sheet.png"}},
+			Chars: map[rune]Char{
+				'A': {ID: 'A', X: 0, Width: 8, Height: 8, XAdvance: 8, Channel: All},
+				'B': {ID: 'B', X: 8, Width: 8, Height: 8, XAdvance: 8, Channel: All},
+			},
+		},
+		PageSheets: map[int]image.Image{0: sheet},
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	f := paddedFont()
+	opt, err := Optimize(f, &OptimizeOptions{Trim: true, Dedupe: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := opt.Descriptor.Chars['A'], opt.Descriptor.Chars['B']
+	if a.Width != 4 || a.Height != 4 || a.XOffset != 2 || a.YOffset != 2 {
+		t.Errorf("got trimmed char %+v, want a 4x4 bitmap with offsets 2", a)
+	}
+	if a.Bounds() != b.Bounds() || a.Page != b.Page {
+		t.Errorf("got bitmaps %v and %v, want the identical bitmaps stored once", a.Bounds(), b.Bounds())
+	}
+	if got := opt.PageSheets[0].Bounds().Size(); got != image.Pt(16, 16) {
+		t.Errorf("got page size %v, want the smallest power of two size", got)
+	}
+	// The optimized font draws the same pixels.
+	want := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	f.DrawText(want, image.Pt(1, 9), "AB")
+	got := image.NewNRGBA(want.Bounds())
+	opt.DrawText(got, image.Pt(1, 9), "AB")
+	if !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("the optimized font draws different pixels")
+	}
+}
+
+func TestOptimizePacked(t *testing.T) {
+	f := paddedFont()
+	f.Descriptor.Common.Packed = true
+	if _, err := Optimize(f, nil); err == nil {
+		t.Error("Optimize accepted a packed font")
+	}
+}
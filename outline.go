@@ -0,0 +1,101 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+)
+
+// A channelImage is a page sheet image whose glyph and outline data, as
+// described by the channel information of the font, is drawn with separate
+// colors for the glyph and the outline.
+type channelImage struct {
+	sheet image.Image
+	// infos are the channel information of the red, green, blue and alpha
+	// channels. Channels that don't hold the character are Zero.
+	infos [4]ChannelInfo
+	// separateGlyph reports whether a channel holds the glyph alone, so
+	// that channels holding the glyph and the outline hold their union.
+	separateGlyph  bool
+	glyph, outline color.RGBA64
+}
+
+// newChannelImage returns the page sheet image for the character drawn with
+// the given glyph and outline colors. If the glyph color is nil, the glyph
+// is drawn in white.
+func newChannelImage(sheet image.Image, ch Char, c *Common, glyph, outline color.Color) *channelImage {
+	if glyph == nil {
+		glyph = color.White
+	}
+	img := &channelImage{
+		sheet:   sheet,
+		infos:   [4]ChannelInfo{c.RedChannel, c.GreenChannel, c.BlueChannel, c.AlphaChannel},
+		glyph:   color.RGBA64Model.Convert(glyph).(color.RGBA64),
+		outline: color.RGBA64Model.Convert(outline).(color.RGBA64),
+	}
+	if c.Packed {
+		for i, chnl := range [...]Channel{Red, Green, Blue, Alpha} {
+			if ch.Channel&chnl == 0 {
+				img.infos[i] = Zero
+			}
+		}
+	}
+	for _, info := range img.infos {
+		img.separateGlyph = img.separateGlyph || info == Glyph
+	}
+	return img
+}
+
+func (img *channelImage) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+func (img *channelImage) Bounds() image.Rectangle {
+	return img.sheet.Bounds()
+}
+
+func (img *channelImage) At(x, y int) color.Color {
+	return img.RGBA64At(x, y)
+}
+
+func (img *channelImage) RGBA64At(x, y int) color.RGBA64 {
+	// The channels are read unpremultiplied, as they are stored in the
+	// image file.
+	px := color.NRGBA64Model.Convert(img.sheet.At(x, y)).(color.NRGBA64)
+	values := [...]uint32{uint32(px.R), uint32(px.G), uint32(px.B), uint32(px.A)}
+	var g, o uint32
+	for i, info := range img.infos {
+		v := values[i]
+		switch info {
+		case Glyph:
+			g = max(g, v)
+		case Outline:
+			o = max(o, v)
+		case GlyphAndOutline:
+			if img.separateGlyph {
+				o = max(o, v)
+				break
+			}
+			// The outline is encoded in the lower and the glyph in the
+			// upper half of the values.
+			o = max(o, min(0xffff, 2*v))
+			if v > 0x8000 {
+				g = max(g, 2*v-0xffff)
+			}
+		}
+	}
+	// The glyph is drawn over the outline.
+	o = o * (0xffff - g) / 0xffff
+	mix := func(gc, oc uint16) uint16 {
+		return uint16(g*uint32(gc)/0xffff + o*uint32(oc)/0xffff)
+	}
+	return color.RGBA64{
+		R: mix(img.glyph.R, img.outline.R),
+		G: mix(img.glyph.G, img.outline.G),
+		B: mix(img.glyph.B, img.outline.B),
+		A: mix(img.glyph.A, img.outline.A),
+	}
+}
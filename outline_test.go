@@ -0,0 +1,87 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestChannelImage(t *testing.T) {
+	red, blue := color.RGBA{R: 0xff, A: 0xff}, color.RGBA{B: 0xff, A: 0xff}
+	tests := []struct {
+		name   string
+		common Common
+		ch     Char
+		pixels []color.NRGBA64
+		want   []color.RGBA64
+	}{
+		{
+			"glyph in the color channels, outline in alpha",
+			Common{AlphaChannel: Outline},
+			Char{Channel: All},
+			[]color.NRGBA64{{R: 0xffff, G: 0xffff, B: 0xffff, A: 0xffff}, {A: 0xffff}, {}},
+			[]color.RGBA64{{R: 0xffff, A: 0xffff}, {B: 0xffff, A: 0xffff}, {}},
+		},
+		{
+			"glyph and outline encoded in the same channels",
+			Common{RedChannel: GlyphAndOutline, GreenChannel: GlyphAndOutline, BlueChannel: GlyphAndOutline, AlphaChannel: One},
+			Char{Channel: All},
+			[]color.NRGBA64{{R: 0xffff, G: 0xffff, B: 0xffff, A: 0xffff}, {R: 0x8000, G: 0x8000, B: 0x8000, A: 0xffff}, {R: 0x4000, G: 0x4000, B: 0x4000, A: 0xffff}},
+			[]color.RGBA64{{R: 0xffff, A: 0xffff}, {B: 0xffff, A: 0xffff}, {B: 0x8000, A: 0x8000}},
+		},
+		{
+			"packed",
+			Common{Packed: true, RedChannel: GlyphAndOutline, GreenChannel: GlyphAndOutline, BlueChannel: GlyphAndOutline, AlphaChannel: GlyphAndOutline},
+			Char{Channel: Green},
+			[]color.NRGBA64{{G: 0xffff}, {R: 0xffff, G: 0x8000, A: 0xffff}, {R: 0xffff, A: 0xffff}},
+			[]color.RGBA64{{R: 0xffff, A: 0xffff}, {B: 0xffff, A: 0xffff}, {}},
+		},
+	}
+	for _, tt := range tests {
+		sheet := image.NewNRGBA64(image.Rect(0, 0, len(tt.pixels), 1))
+		for x, c := range tt.pixels {
+			sheet.SetNRGBA64(x, 0, c)
+		}
+		img := newChannelImage(sheet, tt.ch, &tt.common, red, blue)
+		for x, want := range tt.want {
+			if got := img.RGBA64At(x, 0); got != want {
+				t.Errorf("%s: pixel %d: got %v, want %v", tt.name, x, got, want)
+			}
+		}
+	}
+}
+
+func TestDrawOutlineColor(t *testing.T) {
+	f := testFont("A", 1)
+	f.Descriptor.Common.AlphaChannel = Outline
+	// The glyph is a 2×4 box within its 4×6 outline.
+	sheet := f.PageSheets[0].(*image.NRGBA)
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.NRGBA{A: 0xff}
+			if x > 0 && x < 3 && y > 0 && y < 5 {
+				c = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			}
+			sheet.SetNRGBA(x, y, c)
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	yellow, black := color.RGBA{R: 0xff, G: 0xff, A: 0xff}, color.RGBA{A: 0xff}
+	f.DrawTextOpts(dst, image.Pt(0, 6), "A", WithColor(yellow), WithOutlineColor(black))
+	if got := dst.RGBAAt(1, 2); got != yellow {
+		t.Errorf("got glyph color %v, want %v", got, yellow)
+	}
+	if got := dst.RGBAAt(0, 2); got != black {
+		t.Errorf("got outline color %v, want %v", got, black)
+	}
+	// Without an outline color the page sheet is drawn as it is.
+	dst = image.NewRGBA(image.Rect(0, 0, 8, 8))
+	f.DrawText(dst, image.Pt(0, 6), "A")
+	if got := dst.RGBAAt(1, 2); got != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Errorf("got glyph color %v, want white", got)
+	}
+}
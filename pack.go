@@ -0,0 +1,58 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"image"
+	"sort"
+)
+
+// A packedRect is the placement of a rectangle packed by packRects.
+type packedRect struct {
+	page int
+	pos  image.Point
+}
+
+// packRects packs rectangles of the given sizes into pages of the given
+// size, with the given spacing between the rectangles. It uses a shelf
+// algorithm, placing the rectangles sorted by height in rows. If the page
+// height is 0, all rectangles are packed into a single page of unlimited
+// height. It returns the placements in the order of the sizes and the
+// sizes of the used area of each page.
+func packRects(sizes []image.Point, pageSize image.Point, spacing int) ([]packedRect, []image.Point, error) {
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sizes[order[i]].Y > sizes[order[j]].Y
+	})
+	placed := make([]packedRect, len(sizes))
+	used := []image.Point{{}}
+	page, x, y, shelfHeight := 0, 0, 0, 0
+	for _, i := range order {
+		size := sizes[i]
+		if size.X > pageSize.X || (pageSize.Y > 0 && size.Y > pageSize.Y) {
+			return nil, nil, errors.New("rectangle does not fit into page")
+		}
+		if x > 0 && x+size.X > pageSize.X {
+			x = 0
+			y += shelfHeight + spacing
+			shelfHeight = 0
+		}
+		if pageSize.Y > 0 && y+size.Y > pageSize.Y {
+			page++
+			used = append(used, image.Point{})
+			x, y, shelfHeight = 0, 0, 0
+		}
+		placed[i] = packedRect{page: page, pos: image.Pt(x, y)}
+		used[page].X = max(used[page].X, x+size.X)
+		used[page].Y = max(used[page].Y, y+size.Y)
+		x += size.X + spacing
+		shelfHeight = max(shelfHeight, size.Y)
+	}
+	return placed, used, nil
+}
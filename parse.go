@@ -7,23 +7,99 @@ package bmfont
 import (
 	"errors"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
 	"text/scanner"
 )
 
-func parseDescriptor(filename string, r io.Reader) (*Descriptor, error) {
-	var p tagsParser
+func parseDescriptor(filename string, r io.Reader, opts *ParseOptions) (*Descriptor, error) {
+	br := newTextReader(r)
+	if isJSON(br) {
+		return parseDescriptorJSON(br, opts)
+	}
+	tags, err := parseTags(filename, br, opts)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := descriptorFromTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	opts.remap(desc)
+	return desc, nil
+}
+
+// parseDescriptors parses a sequence of concatenated descriptors. Each info
+// tag except for the first one starts a new descriptor.
+func parseDescriptors(filename string, r io.Reader, opts *ParseOptions) ([]*Descriptor, error) {
+	br := newTextReader(r)
+	if isJSON(br) {
+		desc, err := parseDescriptorJSON(br, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []*Descriptor{desc}, nil
+	}
+	tags, err := parseTags(filename, br, opts)
+	if err != nil {
+		return nil, err
+	}
+	var descs []*Descriptor
+	for len(tags) > 0 {
+		n := 1
+		for n < len(tags) && tags[n].name != "info" {
+			n++
+		}
+		desc, err := descriptorFromTags(tags[:n])
+		if err != nil {
+			return nil, err
+		}
+		opts.remap(desc)
+		descs = append(descs, desc)
+		tags = tags[n:]
+	}
+	return descs, nil
+}
+
+func parseTags(filename string, r io.Reader, opts *ParseOptions) ([]tag, error) {
+	if opts.tolerant() {
+		r = newTolerantReader(r)
+	}
+	p := tagsParser{strict: opts.strict()}
 	tags, err := p.parse(filename, r)
+	if opts.tolerant() {
+		applyTolerantDefaults(tags)
+	}
+	if p.strict {
+		// Report the syntax errors together with the errors of the values.
+		err = append(p.errors, checkStrict(tags)...).Err()
+	}
 	if err != nil {
 		return nil, err
 	}
+	if opts.disallowUnknown() {
+		if err := checkUnknown(tags); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+func descriptorFromTags(tags []tag) (*Descriptor, error) {
 	font := Descriptor{
-		Pages:   make(map[int]Page),
-		Chars:   make(map[rune]Char),
-		Kerning: make(map[CharPair]Kerning),
+		Pages:    make(map[int]Page),
+		Chars:    make(map[rune]Char),
+		Kerning:  make(map[CharPair]Kerning),
+		Features: detectFeatures(tags),
 	}
-	for _, tag := range tags {
+	var errs errorList
+	var charsSized, kerningSized bool
+	for i, tag := range tags {
+		if err := tag.checkRequired(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
 		switch tag.name {
 		case "info":
 			font.Info = Info{
@@ -39,6 +115,13 @@ func parseDescriptor(filename string, r io.Reader) (*Descriptor, error) {
 				Padding:  paddingFrom(tag.intListAttr("padding", 4)),
 				Spacing:  spacingFrom(tag.intListAttr("spacing", 2)),
 				Outline:  tag.intAttr("outline"),
+
+				Languages: tag.stringListAttr("languages"),
+				Scripts:   tag.stringListAttr("scripts"),
+				Usage:     tag.stringAttr("usage"),
+				Notes:     tag.stringAttr("notes"),
+
+				Extra: tag.extraAttrs(),
 			}
 		case "common":
 			font.Common = Common{
@@ -51,15 +134,33 @@ func parseDescriptor(filename string, r io.Reader) (*Descriptor, error) {
 				RedChannel:   ChannelInfo(tag.intAttr("redChnl")),
 				GreenChannel: ChannelInfo(tag.intAttr("greenChnl")),
 				BlueChannel:  ChannelInfo(tag.intAttr("blueChnl")),
+				Extra:        tag.extraAttrs(),
 			}
 		case "page":
 			id := tag.intAttr("id")
+			if _, ok := font.Pages[id]; ok {
+				font.addDuplicate(Finding{Page: id}, "page %d: duplicate ID (line %d)", id, tag.pos.Line)
+			}
 			font.Pages[id] = Page{
-				ID:   id,
-				File: tag.stringAttr("file"),
+				ID:    id,
+				File:  tag.stringAttr("file"),
+				Extra: tag.extraAttrs(),
+			}
+		case "chars":
+			if !charsSized && len(font.Chars) == 0 {
+				font.Chars = make(map[rune]Char, sizeHint(tag.intAttr("count"), len(tags)-i-1))
+				charsSized = true
+			}
+		case "kernings":
+			if !kerningSized && len(font.Kerning) == 0 {
+				font.Kerning = make(map[CharPair]Kerning, sizeHint(tag.intAttr("count"), len(tags)-i-1))
+				kerningSized = true
 			}
 		case "char":
 			id := rune(tag.intAttr("id"))
+			if _, ok := font.Chars[id]; ok {
+				font.addDuplicate(Finding{Char: id}, "char %s: duplicate ID (line %d)", runeName(id), tag.pos.Line)
+			}
 			font.Chars[id] = Char{
 				ID:       id,
 				X:        tag.intAttr("x"),
@@ -69,20 +170,99 @@ func parseDescriptor(filename string, r io.Reader) (*Descriptor, error) {
 				XOffset:  tag.intAttr("xoffset"),
 				YOffset:  tag.intAttr("yoffset"),
 				XAdvance: tag.intAttr("xadvance"),
+				Page:     tag.intAttr("page"),
+				Channel:  Channel(tag.intAttr("chnl")),
+				Letter:   tag.stringAttr("letter"),
+				Extra:    tag.extraAttrs(),
 			}
 		case "kerning":
 			pair := CharPair{
 				First:  rune(tag.intAttr("first")),
 				Second: rune(tag.intAttr("second")),
 			}
+			if _, ok := font.Kerning[pair]; ok {
+				font.addDuplicate(Finding{Pair: pair}, "kerning %s %s: duplicate pair (line %d)",
+					runeName(pair.First), runeName(pair.Second), tag.pos.Line)
+			}
 			font.Kerning[pair] = Kerning{
 				Amount: tag.intAttr("amount"),
+				Extra:  tag.extraAttrs(),
+			}
+		case "kernclass":
+			if err := font.addKerningClass(&tag); err != nil {
+				errs = append(errs, err)
+			}
+		case "classkerning":
+			if font.ClassKerning == nil {
+				font.ClassKerning = make(map[ClassPair]Kerning)
+			}
+			pair := ClassPair{
+				First:  tag.intAttr("first"),
+				Second: tag.intAttr("second"),
+			}
+			font.ClassKerning[pair] = Kerning{
+				Amount: tag.intAttr("amount"),
 			}
 		}
 	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
 	return &font, nil
 }
 
+// sizeHint returns the capacity for a map with the number of entries
+// declared by a count attribute. The declared number may be wrong, so it is
+// limited by the number of remaining tags, which can't declare more entries
+// than that, so that the capacity stays proportional to the input size.
+func sizeHint(count, remaining int) int {
+	return min(max(count, 0), remaining, 1<<16)
+}
+
+// requiredAttrs are the attributes that must be present for each tag,
+// because without them the tag's data is meaningless.
+var requiredAttrs = map[string][]string{
+	"common":       {"lineHeight", "base"},
+	"page":         {"id", "file"},
+	"char":         {"id", "x", "y", "width", "height", "xadvance"},
+	"kerning":      {"first", "second", "amount"},
+	"kernclass":    {"id", "side", "chars"},
+	"classkerning": {"first", "second", "amount"},
+}
+
+// checkRequired reports the first required attribute missing in the tag.
+func (t *tag) checkRequired() error {
+	for _, name := range requiredAttrs[t.name] {
+		if _, ok := t.attrs[name]; !ok {
+			return newError(t.pos, t.name+" tag is missing required attribute "+strconv.Quote(name))
+		}
+	}
+	return nil
+}
+
+// extensionAttrs are the attributes of extensions of the BMFont format that
+// the parser reads in addition to those defined by the specification.
+var extensionAttrs = map[string][]string{
+	"info": {"languages", "scripts", "usage", "notes"},
+	"char": {"letter"},
+}
+
+// extraAttrs returns the attributes of the tag that are unknown to the
+// parser, or nil if there are none.
+func (t *tag) extraAttrs() map[string]string {
+	var extra map[string]string
+	for name, value := range t.attrs {
+		if slices.Contains(knownAttrs[t.name], name) || slices.Contains(extensionAttrs[t.name], name) {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]string)
+		}
+		extra[name] = value
+	}
+	return extra
+}
+
 func paddingFrom(values []int) Padding {
 	return Padding{
 		Up:    values[0],
@@ -100,6 +280,8 @@ func spacingFrom(values []int) Spacing {
 }
 
 type tagsParser struct {
+	// strict enables the additional errors of ParseOptions.Strict.
+	strict  bool
 	errors  errorList
 	scanner scanner.Scanner
 	pos     scanner.Position
@@ -114,15 +296,31 @@ func (p *tagsParser) next() {
 }
 
 func (p *tagsParser) parse(filename string, r io.Reader) ([]tag, error) {
+	var tags []tag
+	err := p.parseFunc(filename, r, func(t tag) error {
+		tags = append(tags, t)
+		return nil
+	})
+	return tags, err
+}
+
+// parseFunc parses the tags and calls fn for each of them as soon as it is
+// read. If fn returns an error, parsing stops and the error is returned.
+// Otherwise the syntax errors are returned after all tags were parsed.
+func (p *tagsParser) parseFunc(filename string, r io.Reader, fn func(tag) error) error {
 	p.scanner.Init(r)
 	p.scanner.Filename = filename
 	p.scanner.Whitespace ^= 1 << '\n'
 	p.scanner.Error = func(s *scanner.Scanner, msg string) {}
 	p.next()
 
-	var tags []tag
 	for p.tok != scanner.EOF {
-		tagName := p.lit
+		if p.tok == '\n' {
+			// empty line
+			p.next()
+			continue
+		}
+		tagName, tagPos := p.lit, p.pos
 		p.expect(scanner.Ident, "tag name")
 		attrs := make(map[string]string)
 		for p.tok != '\n' && p.tok != scanner.EOF {
@@ -135,6 +333,9 @@ func (p *tagsParser) parse(filename string, r io.Reader) ([]tag, error) {
 			case scanner.String:
 				value, err = strconv.Unquote(p.lit)
 				if err != nil {
+					if p.strict {
+						p.error(newError(p.pos, "invalid quoted string "+p.lit))
+					}
 					// end this line, rest is garbage
 					p.tok = '\n'
 					continue
@@ -152,13 +353,17 @@ func (p *tagsParser) parse(filename string, r io.Reader) ([]tag, error) {
 			}
 			attrs[attrName] = value
 		}
-		tags = append(tags, tag{
+		err := fn(tag{
 			name:  tagName,
+			pos:   tagPos,
 			attrs: attrs,
 		})
+		if err != nil {
+			return err
+		}
 		p.next()
 	}
-	return tags, p.errors.Err()
+	return p.errors.Err()
 }
 
 func (p *tagsParser) parseIntList() string {
@@ -190,7 +395,9 @@ func newError(pos scanner.Position, msg string) error {
 }
 
 type tag struct {
-	name  string
+	name string
+	// pos is the position of the tag name in the input.
+	pos   scanner.Position
 	attrs map[string]string
 }
 
@@ -203,6 +410,18 @@ func (t *tag) stringAttr(name string) string {
 	return t.attrs[name]
 }
 
+// stringListAttr returns the comma-separated values of a string attribute,
+// or nil if the attribute is missing or empty.
+func (t *tag) stringListAttr(name string) []string {
+	var values []string
+	for _, v := range strings.Split(t.stringAttr(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func (t *tag) boolAttr(name string) bool {
 	return t.intAttr(name) != 0
 }
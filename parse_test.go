@@ -0,0 +1,167 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testDescriptor is a minimal descriptor in text format with two chars and
+// one kerning pair.
+const testDescriptor = `info face="Test" size=8 bold=0 italic=0 charset="" unicode=1 stretchH=100 smooth=1 aa=1 padding=0,0,0,0 spacing=1,1 outline=0
+common lineHeight=8 base=6 scaleW=16 scaleH=8 pages=1 packed=0 alphaChnl=0 redChnl=4 greenChnl=4 blueChnl=4
+page id=0 file="sheet.png"
+chars count=2
+char id=65 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0 chnl=15
+char id=66 x=4 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0 chnl=15
+kernings count=1
+kerning first=65 second=66 amount=-1
+`
+
+func TestStrictCounts(t *testing.T) {
+	opts := &ParseOptions{Strict: true}
+	if _, err := opts.ReadDescriptor(strings.NewReader(testDescriptor)); err != nil {
+		t.Fatalf("matching counts: %v", err)
+	}
+	truncated := strings.Replace(testDescriptor, "chars count=2", "chars count=3", 1)
+	_, err := opts.ReadDescriptor(strings.NewReader(truncated))
+	if err == nil || !strings.Contains(err.Error(), "declares 3 chars, but there are 2") {
+		t.Errorf("got error %v, want a chars count mismatch", err)
+	}
+}
+
+func TestSizeHint(t *testing.T) {
+	tests := []struct {
+		count, remaining, want int
+	}{
+		{count: 2, remaining: 10, want: 2},
+		{count: -1, remaining: 10, want: 0},
+		{count: 65536, remaining: 3, want: 3},
+		{count: 1 << 30, remaining: 1 << 30, want: 1 << 16},
+	}
+	for _, tt := range tests {
+		if got := sizeHint(tt.count, tt.remaining); got != tt.want {
+			t.Errorf("sizeHint(%d, %d) = %d, want %d", tt.count, tt.remaining, got, tt.want)
+		}
+	}
+}
+
+func TestMissingRequiredAttributes(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     string
+	}{
+		{"xadvance=5 page=0 chnl=15\nkernings", "page=0 chnl=15\nkernings", `6:1: char tag is missing required attribute "xadvance"`},
+		{"common lineHeight=8 ", "common ", `2:1: common tag is missing required attribute "lineHeight"`},
+		{`page id=0 file="sheet.png"`, "page id=0", `3:1: page tag is missing required attribute "file"`},
+		{"amount=-1", "", `8:1: kerning tag is missing required attribute "amount"`},
+	}
+	for _, tt := range tests {
+		_, err := ReadDescriptor(strings.NewReader(strings.Replace(testDescriptor, tt.old, tt.new, 1)))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("got error %v, want %q", err, tt.want)
+		}
+	}
+}
+
+func TestReadDescriptors(t *testing.T) {
+	second := strings.Replace(testDescriptor, `face="Test"`, `face="Second"`, 1)
+	second = strings.Replace(second, "lineHeight=8", "lineHeight=10", 1)
+	descs, err := ReadDescriptors(strings.NewReader(testDescriptor + second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(descs) != 2 {
+		t.Fatalf("got %d descriptors, want 2", len(descs))
+	}
+	for i, want := range []struct {
+		face       string
+		lineHeight int
+	}{{"Test", 8}, {"Second", 10}} {
+		d := descs[i]
+		if d.Info.Face != want.face || d.Common.LineHeight != want.lineHeight || len(d.Chars) != 2 || len(d.Kerning) != 1 {
+			t.Errorf("descriptor %d: got face %q, line height %d, %d chars and %d kerning pairs, want %q, %d, 2 and 1",
+				i, d.Info.Face, d.Common.LineHeight, len(d.Chars), len(d.Kerning), want.face, want.lineHeight)
+		}
+	}
+
+	single, err := ReadDescriptors(strings.NewReader(testDescriptor))
+	if err != nil || len(single) != 1 {
+		t.Errorf("single descriptor: got %d descriptors and error %v, want 1", len(single), err)
+	}
+}
+
+func TestStrictReportsAllErrors(t *testing.T) {
+	broken := strings.Replace(testDescriptor, "padding=0,0,0,0", "padding=0,0", 1)
+	broken = strings.Replace(broken, "char id=65 x=0", `char id=65 x="zero"`, 1)
+	broken = strings.Replace(broken, " yoffset=0 xadvance=5 page=0 chnl=15\nkernings", " xadvance=5 page=0 chnl=15\nkernings", 1)
+	if _, err := ReadDescriptor(strings.NewReader(broken)); err != nil {
+		t.Fatalf("broken values are ignored by default: %v", err)
+	}
+	opts := &ParseOptions{Strict: true}
+	_, err := opts.ReadDescriptor(strings.NewReader(broken))
+	list, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("got error %v, want a list of errors", err)
+	}
+	var got []string
+	for _, e := range list.Unwrap() {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		`bmfont:1:1: info attribute "padding" is not a list of 4 integers: "0,0"`,
+		`bmfont:5:1: char attribute "x" is not an integer: "zero"`,
+		`bmfont:6:1: char tag is missing attribute "yoffset"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got errors\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLetterAttribute(t *testing.T) {
+	input := strings.Replace(testDescriptor, "chars count=2", "chars count=4", 1)
+	input = strings.Replace(input, "kernings count=1", `char id=32 x=8 y=0 width=0 height=0 xoffset=0 yoffset=0 xadvance=5 page=0 chnl=15 letter="space"
+char id=34 x=8 y=0 width=2 height=2 xoffset=0 yoffset=0 xadvance=3 page=0 chnl=15 letter="""
+kernings count=1`, 1)
+	input = strings.Replace(input, "chnl=15\n", "chnl=15 letter=\"A\"\n", 1)
+	d, err := ReadDescriptor(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r, want := range map[rune]string{'A': "A", 'B': "", ' ': "space", '"': `"`} {
+		if got := d.Chars[r].Letter; got != want {
+			t.Errorf("got letter %q for %s, want %q", got, runeName(r), want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), ` letter="""`) {
+		t.Errorf("quotation mark letter was not written as \"\"\":\n%s", buf.String())
+	}
+	got, err := ReadDescriptor(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Chars['"'].Letter != `"` || got.Chars['A'].Letter != "A" {
+		t.Errorf("got letters %q and %q after a round trip", got.Chars['"'].Letter, got.Chars['A'].Letter)
+	}
+
+	// Strict mode cross-checks the letter with the ID.
+	opts := &ParseOptions{Strict: true}
+	if _, err := opts.ReadDescriptor(strings.NewReader(input)); err != nil {
+		t.Errorf("matching letters: %v", err)
+	}
+	mismatch := strings.Replace(input, `letter="A"`, `letter="C"`, 1)
+	_, err = opts.ReadDescriptor(strings.NewReader(mismatch))
+	if err == nil || !strings.Contains(err.Error(), `char letter "C" does not match id 65`) {
+		t.Errorf("got error %v, want a letter mismatch", err)
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseOptions configure how font descriptors are parsed.
+// A nil *ParseOptions is valid and selects the defaults, which are used
+// by the package-level LoadDescriptor and ReadDescriptor functions.
+type ParseOptions struct {
+	// DisallowUnknown rejects descriptors with tags or attributes that are
+	// not defined by the BMFont specification, e.g. for checking that the
+	// output of a third-party exporter is conformant. By default they are
+	// ignored.
+	DisallowUnknown bool
+	// Remap maps character IDs of the descriptor to the runes they
+	// represent, for fonts exported by tools with private or shifted
+	// encodings, e.g. icon fonts mapped into ASCII slots. The IDs of
+	// chars, kerning pairs and kerning classes are replaced at parse time.
+	// IDs that are not in the map are kept. If a remapped char collides
+	// with an unmapped one, the remapped char wins, and so do its kerning
+	// classes.
+	Remap map[rune]rune
+	// Strict rejects descriptors in text format with malformed or missing
+	// values, which are otherwise ignored or read as zero: syntax errors,
+	// invalid quoted strings, attribute values that are not valid integers
+	// or lists of integers, and tags lacking attributes defined by the
+	// specification, chars whose letter attribute does not match their ID,
+	// and chars and kernings count tags that don't match the number of
+	// char and kerning tags, which is usually a sign of a truncated file.
+	// All errors are collected and returned together, each
+	// with the position of the tag in the input. The returned error has an
+	// Unwrap() []error method for inspecting them individually.
+	Strict bool
+	// Tolerant accepts descriptors in text format with the deviations from
+	// the format found in the output of tools like Hiero, fontbm and
+	// Littera, which are otherwise rejected:
+	//
+	//   - unquoted string values, which may contain spaces, like
+	//     face=Open Sans size=32,
+	//   - missing values, like unicode= stretchH=100, which read as "",
+	//   - white space around the equal signs and after the commas of
+	//     integer lists, like padding = 1, 1, 1, 1,
+	//   - missing required attributes other than the IDs: the line height
+	//     defaults to the font size, the base to the line height, the glyph
+	//     rectangle of a char to zero, its advance to its width, and kerning
+	//     amounts to zero.
+	Tolerant bool
+}
+
+// LoadDescriptor loads the font descriptor data from a BMFont descriptor
+// file in text format like the package-level LoadDescriptor function, with
+// the parse options applied.
+func (o *ParseOptions) LoadDescriptor(path string) (d *Descriptor, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeChecked(f, &err)
+	return parseDescriptor(filepath.Base(path), f, o)
+}
+
+// ReadDescriptor parses font descriptor data in BMFont's text format from a
+// reader like the package-level ReadDescriptor function, with the parse
+// options applied.
+func (o *ParseOptions) ReadDescriptor(r io.Reader) (*Descriptor, error) {
+	return parseDescriptor("bmfont", r, o)
+}
+
+// ReadDescriptors parses a sequence of concatenated font descriptors like
+// the package-level ReadDescriptors function, with the parse options
+// applied.
+func (o *ParseOptions) ReadDescriptors(r io.Reader) ([]*Descriptor, error) {
+	return parseDescriptors("bmfont", r, o)
+}
+
+// remap applies the remap table to the descriptor.
+func (o *ParseOptions) remap(d *Descriptor) {
+	if o == nil || len(o.Remap) == 0 {
+		return
+	}
+	chars := make(map[rune]Char, len(d.Chars))
+	for id, ch := range d.Chars {
+		if _, ok := o.Remap[id]; !ok {
+			chars[id] = ch
+		}
+	}
+	// replaced are the IDs taken by remapped chars, which replace any
+	// unmapped chars and their kerning classes.
+	replaced := make(map[rune]bool)
+	for id, ch := range d.Chars {
+		if r, ok := o.Remap[id]; ok {
+			ch.ID = r
+			chars[r] = ch
+			replaced[r] = true
+		}
+	}
+	d.Chars = chars
+	kerning := make(map[CharPair]Kerning, len(d.Kerning))
+	for pair, k := range d.Kerning {
+		kerning[CharPair{First: o.remapRune(pair.First), Second: o.remapRune(pair.Second)}] = k
+	}
+	d.Kerning = kerning
+	if d.KerningClasses == nil {
+		return
+	}
+	classes := make(map[rune]CharClasses, len(d.KerningClasses))
+	for id, c := range d.KerningClasses {
+		if _, ok := o.Remap[id]; !ok && !replaced[id] {
+			classes[id] = c
+		}
+	}
+	for id, c := range d.KerningClasses {
+		if r, ok := o.Remap[id]; ok {
+			classes[r] = c
+		}
+	}
+	d.KerningClasses = classes
+}
+
+func (o *ParseOptions) remapRune(r rune) rune {
+	if m, ok := o.Remap[r]; ok {
+		return m
+	}
+	return r
+}
+
+func (o *ParseOptions) disallowUnknown() bool {
+	return o != nil && o.DisallowUnknown
+}
+
+func (o *ParseOptions) strict() bool {
+	return o != nil && o.Strict
+}
+
+func (o *ParseOptions) tolerant() bool {
+	return o != nil && o.Tolerant
+}
+
+// knownAttrs are the tags and their attributes defined by the BMFont
+// specification.
+var knownAttrs = map[string][]string{
+	"info":     {"face", "size", "bold", "italic", "charset", "unicode", "stretchH", "smooth", "aa", "padding", "spacing", "outline"},
+	"common":   {"lineHeight", "base", "scaleW", "scaleH", "pages", "packed", "alphaChnl", "redChnl", "greenChnl", "blueChnl"},
+	"page":     {"id", "file"},
+	"chars":    {"count"},
+	"char":     {"id", "x", "y", "width", "height", "xoffset", "yoffset", "xadvance", "page", "chnl"},
+	"kernings": {"count"},
+	"kerning":  {"first", "second", "amount"},
+}
+
+// checkUnknown returns an error listing the tags and attributes that are not
+// defined by the BMFont specification, each with the line of its first
+// occurrence, or nil if there are none.
+func checkUnknown(tags []tag) error {
+	var unknown []string
+	var first *tag
+	seen := make(map[string]bool)
+	report := func(t *tag, name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		if first == nil {
+			first = t
+		}
+		unknown = append(unknown, name+" (line "+strconv.Itoa(t.pos.Line)+")")
+	}
+	for i := range tags {
+		t := &tags[i]
+		known, ok := knownAttrs[t.name]
+		if !ok {
+			report(t, "tag "+t.name)
+			continue
+		}
+		names := make([]string, 0, len(t.attrs))
+		for name := range t.attrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !slices.Contains(known, name) {
+				report(t, "attribute "+t.name+"."+name)
+			}
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return newError(first.pos, "tags or attributes not defined by the BMFont specification: "+strings.Join(unknown, ", "))
+}
+
+// stringAttrs are the attributes with string values. The values of all
+// other attributes of the known tags are integers or lists of integers.
+var stringAttrs = map[string][]string{
+	"info":      {"face", "charset", "languages", "scripts", "usage", "notes"},
+	"char":      {"letter"},
+	"page":      {"file"},
+	"kernclass": {"side", "chars"},
+}
+
+// listAttrs are the attributes with lists of integers as values and the
+// lengths of the lists.
+var listAttrs = map[string]map[string]int{
+	"info": {"padding": 4, "spacing": 2},
+}
+
+// checkStrict returns the errors for the missing attributes and malformed
+// integer values of the tags in strict mode.
+func checkStrict(tags []tag) errorList {
+	var errs errorList
+	var counts tagCounts
+	for i := range tags {
+		t := &tags[i]
+		if t.name == "info" {
+			// The start of a concatenated descriptor.
+			errs = append(errs, counts.check()...)
+			counts = tagCounts{}
+		}
+		counts.add(t)
+		known, ok := knownAttrs[t.name]
+		if !ok {
+			known, ok = requiredAttrs[t.name]
+		}
+		if !ok {
+			continue
+		}
+		for _, name := range known {
+			if _, ok := t.attrs[name]; !ok {
+				errs = append(errs, newError(t.pos, t.name+" tag is missing attribute "+strconv.Quote(name)))
+			}
+		}
+		names := make([]string, 0, len(t.attrs))
+		for name := range t.attrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !slices.Contains(known, name) && !slices.Contains(extensionAttrs[t.name], name) ||
+				slices.Contains(stringAttrs[t.name], name) {
+				continue
+			}
+			if want := checkIntValue(t.attrs[name], listAttrs[t.name][name]); want != "" {
+				errs = append(errs, newError(t.pos, fmt.Sprintf("%s attribute %q is not %s: %q", t.name, name, want, t.attrs[name])))
+			}
+		}
+		if letter, ok := t.attrs["letter"]; ok && t.name == "char" {
+			if r, ok := letterRune(letter); ok && r != rune(t.intAttr("id")) {
+				errs = append(errs, newError(t.pos, fmt.Sprintf("char letter %q does not match id %d", letter, t.intAttr("id"))))
+			}
+		}
+	}
+	return append(errs, counts.check()...)
+}
+
+// tagCounts are the numbers of char and kerning tags of a descriptor and
+// the count tags declaring them.
+type tagCounts struct {
+	chars, kernings           int
+	charsCount, kerningsCount *tag
+}
+
+func (c *tagCounts) add(t *tag) {
+	switch t.name {
+	case "char":
+		c.chars++
+	case "kerning":
+		c.kernings++
+	case "chars":
+		c.charsCount = t
+	case "kernings":
+		c.kerningsCount = t
+	}
+}
+
+// check returns the errors for count tags that don't match the numbers of
+// tags.
+func (c *tagCounts) check() errorList {
+	var errs errorList
+	if t := c.charsCount; t != nil && t.intAttr("count") != c.chars {
+		errs = append(errs, newError(t.pos, fmt.Sprintf("chars tag declares %d chars, but there are %d", t.intAttr("count"), c.chars)))
+	}
+	if t := c.kerningsCount; t != nil && t.intAttr("count") != c.kernings {
+		errs = append(errs, newError(t.pos, fmt.Sprintf("kernings tag declares %d kerning pairs, but there are %d", t.intAttr("count"), c.kernings)))
+	}
+	return errs
+}
+
+// checkIntValue checks that the value is an integer, or a list of n
+// comma-separated integers if n is greater than 0. It returns a description
+// of the expected value if the value is invalid, or else "".
+func checkIntValue(value string, n int) string {
+	if n == 0 {
+		if _, err := strconv.Atoi(value); err != nil {
+			return "an integer"
+		}
+		return ""
+	}
+	parts := strings.Split(value, ",")
+	if len(parts) != n {
+		return fmt.Sprintf("a list of %d integers", n)
+	}
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return fmt.Sprintf("a list of %d integers", n)
+		}
+	}
+	return ""
+}
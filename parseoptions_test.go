@@ -0,0 +1,77 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRemap(t *testing.T) {
+	input := testDescriptor + `kernclass id=1 side="first" chars="A"
+kernclass id=2 side="second" chars="C"
+classkerning first=1 second=2 amount=-2
+`
+	// A is moved to the private use area, B takes the place of C.
+	opts := &ParseOptions{Remap: map[rune]rune{'A': '\uE000', 'B': 'C'}}
+	d, err := opts.ReadDescriptor(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d.Chars['A']; ok {
+		t.Error("char A is still present after remapping")
+	}
+	if ch, ok := d.Chars['\uE000']; !ok || ch.ID != '\uE000' || ch.X != 0 {
+		t.Errorf("got remapped char %+v, ok %v, want the char of A with ID U+E000", ch, ok)
+	}
+	if ch := d.Chars['C']; ch.X != 4 {
+		t.Errorf("got char C at x %d, want the remapped char of B at x 4", ch.X)
+	}
+	if _, ok := d.Kerning[CharPair{First: '\uE000', Second: 'C'}]; !ok {
+		t.Error("kerning pair was not remapped")
+	}
+	if got, want := d.KerningClasses['\uE000'], (CharClasses{First: 1}); got != want {
+		t.Errorf("got kerning classes %+v for U+E000, want %+v", got, want)
+	}
+	// C is replaced by the remapped B, which has no kerning classes.
+	if got := d.KerningClasses['C']; got != (CharClasses{}) {
+		t.Errorf("got kerning classes %+v for C, want none", got)
+	}
+}
+
+func TestRemapCollision(t *testing.T) {
+	// A takes the place of the unmapped B.
+	opts := &ParseOptions{Remap: map[rune]rune{'A': 'B', 'Z': 'Y'}}
+	d, err := opts.ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Chars) != 1 {
+		t.Fatalf("got %d chars, want 1", len(d.Chars))
+	}
+	if ch := d.Chars['B']; ch.ID != 'B' || ch.X != 0 {
+		t.Errorf("got char %+v, want the remapped char of A with ID B", ch)
+	}
+	if k, ok := d.Kerning[CharPair{First: 'B', Second: 'B'}]; !ok || k.Amount != -1 || len(d.Kerning) != 1 {
+		t.Errorf("got kerning %v, want the remapped pair B B", d.Kerning)
+	}
+}
+
+func TestDisallowUnknown(t *testing.T) {
+	opts := &ParseOptions{DisallowUnknown: true}
+	if _, err := opts.ReadDescriptor(strings.NewReader(testDescriptor)); err != nil {
+		t.Fatalf("conformant descriptor: %v", err)
+	}
+	extended := strings.Replace(testDescriptor, "page=0 chnl=15\n", "page=0 chnl=15 glow=1\n", 1) +
+		"glyphset name=\"x\"\n"
+	if _, err := ReadDescriptor(strings.NewReader(extended)); err != nil {
+		t.Fatalf("unknown tags and attributes are ignored by default: %v", err)
+	}
+	_, err := opts.ReadDescriptor(strings.NewReader(extended))
+	want := "5:1: tags or attributes not defined by the BMFont specification: attribute char.glow (line 5), tag glyphset (line 9)"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// checkPixelFormat checks that the page sheet image has the channels the
+// descriptor's channel information expects, and that channels declared as
+// constant zero or one have these values.
+func checkPixelFormat(sheet image.Image, c *Common) error {
+	model := sheet.ColorModel()
+	hasAlpha := true
+	switch model {
+	case color.GrayModel, color.Gray16Model, color.YCbCrModel, color.CMYKModel:
+		hasAlpha = false
+	}
+	hasColor := true
+	switch model {
+	case color.GrayModel, color.Gray16Model, color.AlphaModel, color.Alpha16Model:
+		hasColor = false
+	}
+	if c.Packed && !hasColor {
+		return errors.New("sheet of packed font has no separate color channels")
+	}
+	if c.AlphaChannel.holdsData() && !hasAlpha {
+		return errors.New("sheet has no alpha channel, but the descriptor declares glyph data in it")
+	}
+
+	channels := []struct {
+		name string
+		info ChannelInfo
+	}{
+		{"red", c.RedChannel},
+		{"green", c.GreenChannel},
+		{"blue", c.BlueChannel},
+		{"alpha", c.AlphaChannel},
+	}
+	var constant bool
+	for _, ch := range channels {
+		constant = constant || ch.info == Zero || ch.info == One
+	}
+	if !constant {
+		return nil
+	}
+	b := sheet.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// The channels are compared unpremultiplied, as they are stored
+			// in the image file.
+			px := color.NRGBA64Model.Convert(sheet.At(x, y)).(color.NRGBA64)
+			values := [...]uint16{px.R, px.G, px.B, px.A}
+			for i, ch := range channels {
+				v := values[i]
+				if px.A == 0 && i < 3 {
+					// The color of fully transparent pixels is undefined.
+					continue
+				}
+				if ch.info == Zero && v != 0 {
+					return fmt.Errorf("%s channel is declared as zero, but pixel (%d,%d) has value %d",
+						ch.name, x, y, v>>8)
+				}
+				if ch.info == One && v != 0xffff {
+					return fmt.Errorf("%s channel is declared as one, but pixel (%d,%d) has value %d",
+						ch.name, x, y, v>>8)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// holdsData reports whether a channel with this information holds glyph or
+// outline data.
+func (ci ChannelInfo) holdsData() bool {
+	return ci == Glyph || ci == Outline || ci == GlyphAndOutline
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCheckPixelFormat(t *testing.T) {
+	rect := image.Rect(0, 0, 2, 2)
+	white := image.NewNRGBA(rect)
+	for i := range white.Pix {
+		white.Pix[i] = 0xFF
+	}
+	gray := image.NewGray(rect)
+	halfRed := image.NewNRGBA(rect)
+	halfRed.SetNRGBA(1, 0, color.NRGBA{R: 0x80, G: 0xFF, B: 0xFF, A: 0xFF})
+	transparent := image.NewNRGBA(rect)
+
+	tests := []struct {
+		name   string
+		sheet  image.Image
+		common Common
+		want   string
+	}{
+		{"white glyphs", white, Common{AlphaChannel: Glyph, RedChannel: One, GreenChannel: One, BlueChannel: One}, ""},
+		{"transparent pixels", transparent, Common{AlphaChannel: Glyph, RedChannel: One, GreenChannel: One, BlueChannel: One}, ""},
+		{"packed grayscale", gray, Common{Packed: true}, "sheet of packed font has no separate color channels"},
+		{"alpha in grayscale", gray, Common{AlphaChannel: GlyphAndOutline}, "sheet has no alpha channel"},
+		{"red not one", halfRed, Common{RedChannel: One}, "red channel is declared as one, but pixel (1,0) has value 128"},
+		{"green not zero", white, Common{GreenChannel: Zero}, "green channel is declared as zero, but pixel (0,0) has value 255"},
+	}
+	for _, tt := range tests {
+		err := checkPixelFormat(tt.sheet, &tt.common)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: got error %v, want none", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLoaderVerifyPixelFormat(t *testing.T) {
+	// The test descriptor declares the color channels as one, which the
+	// transparent sheet satisfies, and glyph data in the alpha channel.
+	l := Loader{VerifyPixelFormat: true}
+	if _, err := l.Read(strings.NewReader(testDescriptor), pngSheets(t, image.Pt(16, 8))); err != nil {
+		t.Errorf("matching pixel format: %v", err)
+	}
+	packed := strings.Replace(testDescriptor, "packed=0", "packed=1", 1)
+	if _, err := l.Read(strings.NewReader(packed), grayPNGSheets(t, image.Pt(16, 8))); err == nil {
+		t.Error("packed font with grayscale sheet: got no error")
+	}
+}
+
+// grayPNGSheets returns a SheetReaderFunc that provides a black grayscale
+// PNG image of the given size for every page.
+func grayPNGSheets(t *testing.T, size image.Point) SheetReaderFunc {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rectangle{Max: size})); err != nil {
+		t.Fatal(err)
+	}
+	return func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}
+}
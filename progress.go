@@ -0,0 +1,98 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"io"
+	"sync"
+)
+
+// LoadProgress reports the progress of loading a font with a Loader.
+type LoadProgress struct {
+	// PagesDecoded is the number of page sheet images decoded so far.
+	PagesDecoded int
+	// PagesTotal is the number of page sheet images to decode. It is 0
+	// until the descriptor has been parsed.
+	PagesTotal int
+	// BytesRead is the number of bytes read so far from the descriptor and
+	// the page sheet images.
+	BytesRead int64
+}
+
+// A progressTracker counts the bytes read and the pages decoded, and
+// reports the progress to a callback function. A nil *progressTracker
+// tracks nothing. Its methods may be called concurrently, but the
+// callback function is never called concurrently.
+type progressTracker struct {
+	mu sync.Mutex
+	fn func(LoadProgress)
+	p  LoadProgress
+}
+
+func newProgressTracker(fn func(LoadProgress)) *progressTracker {
+	if fn == nil {
+		return nil
+	}
+	return &progressTracker{fn: fn}
+}
+
+func (t *progressTracker) reader(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &countingReader{r: r, t: t}
+}
+
+func (t *progressTracker) sheets(sheets SheetReaderFunc) SheetReaderFunc {
+	if t == nil {
+		return sheets
+	}
+	return func(filename string) (io.ReadCloser, error) {
+		rc, err := sheets(filename)
+		if err != nil {
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{t.reader(rc), rc}, nil
+	}
+}
+
+func (t *progressTracker) start(pages int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.p.PagesTotal = pages
+	t.fn(t.p)
+}
+
+func (t *progressTracker) pageDone() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.p.PagesDecoded++
+	t.fn(t.p)
+}
+
+type countingReader struct {
+	r io.Reader
+	t *progressTracker
+}
+
+func (c *countingReader) Read(p []byte) (n int, err error) {
+	n, err = c.r.Read(p)
+	if n > 0 {
+		c.t.mu.Lock()
+		c.t.p.BytesRead += int64(n)
+		c.t.fn(c.t.p)
+		c.t.mu.Unlock()
+	}
+	return n, err
+}
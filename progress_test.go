@@ -0,0 +1,48 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLoaderProgress(t *testing.T) {
+	var sheet bytes.Buffer
+	if err := png.Encode(&sheet, image.NewNRGBA(image.Rect(0, 0, 16, 8))); err != nil {
+		t.Fatal(err)
+	}
+	var reports []LoadProgress
+	l := Loader{Progress: func(p LoadProgress) {
+		reports = append(reports, p)
+	}}
+	_, err := l.Read(strings.NewReader(testDescriptor), func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(sheet.Bytes())), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) == 0 {
+		t.Fatal("got no progress reports")
+	}
+	for i := 1; i < len(reports); i++ {
+		prev, p := reports[i-1], reports[i]
+		if p.BytesRead < prev.BytesRead || p.PagesDecoded < prev.PagesDecoded {
+			t.Errorf("report %d: progress went back from %+v to %+v", i, prev, p)
+		}
+	}
+	want := LoadProgress{
+		PagesDecoded: 1,
+		PagesTotal:   1,
+		BytesRead:    int64(len(testDescriptor) + sheet.Len()),
+	}
+	if got := reports[len(reports)-1]; got != want {
+		t.Errorf("got final progress %+v, want %+v", got, want)
+	}
+}
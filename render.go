@@ -0,0 +1,92 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// RenderToImage draws the given text with the given style into a new image
+// that is just large enough to hold the bounding box of the text, plus the
+// padding of the style. The image is in device pixels of the style. If the
+// style has a background color, the image is filled with it before the text
+// is drawn.
+func (f *BitmapFont) RenderToImage(text string, style *Style) *image.RGBA {
+	if style == nil {
+		style = &Style{}
+	}
+	pad := style.padding()
+	bounds := f.measureText(text, style)
+	img := image.NewRGBA(image.Rect(0, 0,
+		pad.Left+bounds.Dx()+pad.Right,
+		pad.Up+bounds.Dy()+pad.Down,
+	))
+	if style.Background != nil {
+		draw.Draw(img, img.Bounds(), image.NewUniform(style.Background), image.Point{}, draw.Src)
+	}
+	f.drawText(img, image.Pt(pad.Left, pad.Up).Sub(bounds.Min), text, style)
+	return img
+}
+
+// EncodeText renders the given text with the given style like RenderToImage
+// and writes the image to w, encoded in the given format. The supported
+// formats are "png", "jpeg" (or "jpg") and "gif". Since JPEG has no
+// transparency, the style should have a background color for this format.
+func (f *BitmapFont) EncodeText(w io.Writer, format, text string, style *Style) error {
+	var encode func(io.Writer, image.Image) error
+	switch strings.ToLower(format) {
+	case "png":
+		encode = png.Encode
+	case "jpeg", "jpg":
+		encode = func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, img, nil)
+		}
+	case "gif":
+		encode = func(w io.Writer, img image.Image) error {
+			return gif.Encode(w, img, nil)
+		}
+	default:
+		return fmt.Errorf("bmfont: unsupported image format %q", format)
+	}
+	return encode(w, f.RenderToImage(text, style))
+}
+
+// clipImage returns an image that restricts drawing on the destination image
+// to the given rectangle.
+func clipImage(dst draw.Image, r image.Rectangle) draw.Image {
+	if s, ok := dst.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		if sub, ok := s.SubImage(r).(draw.Image); ok {
+			return sub
+		}
+	}
+	return clippedImage{Image: dst, clip: r.Intersect(dst.Bounds())}
+}
+
+// A clippedImage is a destination image whose bounds are restricted to a
+// clip rectangle.
+type clippedImage struct {
+	draw.Image
+	clip image.Rectangle
+}
+
+func (c clippedImage) Bounds() image.Rectangle {
+	return c.clip
+}
+
+func (c clippedImage) Set(x, y int, col color.Color) {
+	if (image.Point{X: x, Y: y}).In(c.clip) {
+		c.Image.Set(x, y, col)
+	}
+}
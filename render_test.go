@@ -0,0 +1,95 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+)
+
+// opaqueFont returns a font like testFont whose glyphs are opaque white
+// boxes.
+func opaqueFont(chars string) *BitmapFont {
+	f := testFont(chars, 1)
+	sheet := f.PageSheets[0].(*image.NRGBA)
+	draw.Draw(sheet, sheet.Bounds(), image.White, image.Point{}, draw.Src)
+	return f
+}
+
+func TestRenderToImage(t *testing.T) {
+	f := opaqueFont("AB")
+	bg := color.RGBA{R: 0, G: 0, B: 0xFF, A: 0xFF}
+	style := &Style{Background: bg, Padding: Padding{Up: 1, Right: 2, Down: 3, Left: 4}}
+	img := f.RenderToImage("AB", style)
+	text := f.MeasureText("AB")
+	if got, want := img.Bounds().Size(), image.Pt(4+text.Dx()+2, 1+text.Dy()+3); got != want {
+		t.Errorf("got image size %v, want %v", got, want)
+	}
+	if got := img.RGBAAt(0, 0); got != bg {
+		t.Errorf("got padding color %v, want background %v", got, bg)
+	}
+	if got := img.RGBAAt(4, 1); got != (color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}) {
+		t.Errorf("got color %v at the top left of the text, want white", got)
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	f := opaqueFont("AB")
+	var buf bytes.Buffer
+	if err := f.EncodeText(&buf, "PNG", "AB", nil); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Bounds(), f.RenderToImage("AB", nil).Bounds(); got != want {
+		t.Errorf("got image bounds %v, want %v", got, want)
+	}
+	for _, format := range []string{"jpg", "gif"} {
+		if err := f.EncodeText(&bytes.Buffer{}, format, "AB", &Style{Background: color.Black}); err != nil {
+			t.Errorf("format %s: %v", format, err)
+		}
+	}
+	if err := f.EncodeText(&bytes.Buffer{}, "bmp", "AB", nil); err == nil {
+		t.Error("EncodeText accepted an unsupported format")
+	}
+}
+
+// plainImage hides the SubImage method of an image.
+type plainImage struct {
+	draw.Image
+}
+
+func TestClip(t *testing.T) {
+	f := opaqueFont("AB")
+	clip := image.Rect(2, 0, 7, 4)
+	for _, wrap := range []bool{false, true} {
+		rgba := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		var dst draw.Image = rgba
+		if wrap {
+			dst = plainImage{rgba}
+		}
+		f.DrawTextOpts(dst, image.Pt(0, 6), "AB", WithClip(clip), WithShadow(image.Pt(1, 1), color.Black, 0))
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				p := image.Pt(x, y)
+				if a := rgba.RGBAAt(x, y).A; !p.In(clip) && a != 0 {
+					t.Errorf("wrapped %v: got alpha %d at %v outside the clip rectangle", wrap, a, p)
+				}
+			}
+		}
+		// A is cut at the left and B at the bottom edge.
+		for _, p := range []image.Point{{2, 0}, {3, 3}, {6, 3}} {
+			if a := rgba.RGBAAt(p.X, p.Y).A; a != 0xff {
+				t.Errorf("wrapped %v: got alpha %d at %v inside the clip rectangle, want 255", wrap, a, p)
+			}
+		}
+	}
+}
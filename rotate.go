@@ -0,0 +1,82 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// DrawTextRotated draws the given text with the given style on the
+// destination image like DrawText, but rotated counterclockwise by the given
+// angle in radians around the start position, which is on the base line of
+// the first line of text. For example, an angle of math.Pi/2 draws a label
+// for the vertical axis of a chart that reads from bottom to top. The
+// glyphs are transformed individually and resampled with bilinear
+// interpolation. The padding and the background color of the style are
+// ignored.
+func (f *BitmapFont) DrawTextRotated(dst draw.Image, pos image.Point, text string, angle float64, style *Style) {
+	l := f.layout(text, style, &f.stats).drawnGlyphs(style)
+	sin, cos := math.Sincos(angle)
+	d := newImageDrawer(dst, l, style)
+	l.draw(rotatedDrawer{
+		dst:        d.dst,
+		color:      d.color,
+		compositor: d.compositor,
+		pivot:      pos,
+		sin:        sin,
+		cos:        cos,
+	}, pos, &f.stats)
+}
+
+// A rotatedDrawer draws glyphs rotated around a pivot point.
+type rotatedDrawer struct {
+	dst draw.Image
+	// color is the color the glyphs are tinted with, if not nil.
+	color      color.Color
+	compositor draw.Drawer
+	pivot      image.Point
+	sin, cos   float64
+}
+
+func (d rotatedDrawer) Draw(dr image.Rectangle, src glyphSource, sr image.Rectangle) {
+	if sr.Empty() {
+		return
+	}
+	// The source rectangle is scaled to the destination rectangle.
+	kx := float64(dr.Dx()) / float64(sr.Dx())
+	ky := float64(dr.Dy()) / float64(sr.Dy())
+	m := f64.Aff3{
+		kx, 0, float64(dr.Min.X) - kx*float64(sr.Min.X),
+		0, ky, float64(dr.Min.Y) - ky*float64(sr.Min.Y),
+	}
+	d.DrawTransformed(m, dr, src, sr)
+}
+
+func (d rotatedDrawer) DrawTransformed(m f64.Aff3, bounds image.Rectangle, glyph glyphSource, sr image.Rectangle) {
+	src := glyph()
+	if src == nil {
+		return
+	}
+	if d.color != nil {
+		src = newTintedImage(src, d.color)
+	}
+	// The transformation is followed by the rotation around the pivot.
+	s, c := d.sin, d.cos
+	px, py := float64(d.pivot.X), float64(d.pivot.Y)
+	tx, ty := m[2]-px, m[5]-py
+	r := f64.Aff3{
+		c*m[0] + s*m[3], c*m[1] + s*m[4], c*tx + s*ty + px,
+		-s*m[0] + c*m[3], -s*m[1] + c*m[4], -s*tx + c*ty + py,
+	}
+	composite(d.dst, d.compositor, transformedBounds(r, sr), func(dst draw.Image, op draw.Op) {
+		xdraw.BiLinear.Transform(dst, r, src, sr, op, nil)
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestDrawTextRotated(t *testing.T) {
+	f := opaqueFont("A")
+	tests := []struct {
+		angle float64
+		want  image.Rectangle // the covered pixels
+	}{
+		{0, image.Rect(10, 4, 14, 10)},
+		// Rotated counterclockwise, the glyph extends to the left of the
+		// start position and reads from bottom to top.
+		{math.Pi / 2, image.Rect(4, 6, 10, 10)},
+		{math.Pi, image.Rect(6, 10, 10, 16)},
+	}
+	for _, tt := range tests {
+		dst := image.NewRGBA(image.Rect(0, 0, 20, 20))
+		f.DrawTextRotated(dst, image.Pt(10, 10), "A", tt.angle, &Style{Color: color.White})
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 20; x++ {
+				p := image.Pt(x, y)
+				// The edges are antialiased.
+				inside := p.In(tt.want.Inset(1))
+				outside := !p.In(tt.want.Inset(-1))
+				if a := dst.RGBAAt(x, y).A; inside && a != 0xff || outside && a != 0 {
+					t.Errorf("angle %g: got alpha %d at %v, want the glyph to cover %v", tt.angle, a, p, tt.want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// SafeLimits bound the resources used by ParseDescriptorSafe and ReadSafe.
+// The memory allocated for the descriptor grows linearly with its size, so
+// MaxDescriptorSize also bounds the memory for parsing it.
+// A nil *SafeLimits is valid and selects the defaults.
+type SafeLimits struct {
+	// MaxDescriptorSize is the maximum size of the descriptor in bytes.
+	// If it is 0 a maximum of 4 MiB is used.
+	MaxDescriptorSize int64
+	// MaxPages is the maximum number of page sheets that are read.
+	// If it is 0 a maximum of 64 is used.
+	MaxPages int
+	// MaxSheetSize is the maximum size of a page sheet image file in bytes.
+	// If it is 0 a maximum of 32 MiB is used.
+	MaxSheetSize int64
+	// MaxSheetPixels is the maximum number of pixels of a decoded page sheet
+	// image. If it is 0 a maximum of 4096×4096 pixels is used.
+	MaxSheetPixels int64
+	// MaxTotalPixels is the maximum number of pixels of all decoded page
+	// sheet images together. If it is 0 a maximum of 4096×4096 pixels is
+	// used, which is 64 MiB for images with 4 bytes per pixel.
+	MaxTotalPixels int64
+}
+
+func (l *SafeLimits) maxDescriptorSize() int64 {
+	if l == nil || l.MaxDescriptorSize <= 0 {
+		return 4 << 20
+	}
+	return l.MaxDescriptorSize
+}
+
+func (l *SafeLimits) maxPages() int {
+	if l == nil || l.MaxPages <= 0 {
+		return 64
+	}
+	return l.MaxPages
+}
+
+func (l *SafeLimits) maxSheetSize() int64 {
+	if l == nil || l.MaxSheetSize <= 0 {
+		return 32 << 20
+	}
+	return l.MaxSheetSize
+}
+
+func (l *SafeLimits) maxSheetPixels() int64 {
+	if l == nil || l.MaxSheetPixels <= 0 {
+		return 4096 * 4096
+	}
+	return l.MaxSheetPixels
+}
+
+func (l *SafeLimits) maxTotalPixels() int64 {
+	if l == nil || l.MaxTotalPixels <= 0 {
+		return 4096 * 4096
+	}
+	return l.MaxTotalPixels
+}
+
+// ParseDescriptorSafe reads a BMFont descriptor in text format like
+// ReadDescriptor, for untrusted input such as uploads. It does not panic on
+// any input, and the memory it allocates is proportional to the size of the
+// descriptor, which is bounded by the maximum descriptor size of the limits:
+// counts declared in the input are only trusted up to the number of tags
+// that follow them. Descriptors exceeding the limits are rejected with an
+// error.
+func ParseDescriptorSafe(r io.Reader, limits *SafeLimits) (d *Descriptor, err error) {
+	defer recoverError(&err)
+	lr := limitReader(r, "descriptor", limits.maxDescriptorSize())
+	d, err = parseDescriptor("bmfont", lr, nil)
+	if lr.err != nil {
+		// The scanner treats read errors as the end of the input.
+		return nil, lr.err
+	}
+	return d, err
+}
+
+// ReadSafe reads a bitmap font including all the referenced page sheet images
+// like Read, for untrusted input such as uploads. It does not panic on any
+// input, and the memory it uses is bounded by the limits: the sizes of the
+// descriptor and of the sheet image files are checked while they are read,
+// and the dimensions of the sheet images are checked against the limits for
+// each sheet and for all sheets together before they are decoded. In
+// addition, the bitmaps of all characters must lie within page sheets that
+// were read, so that drawing text with the font is safe as well.
+func ReadSafe(r io.Reader, sheets SheetReaderFunc, limits *SafeLimits) (f *BitmapFont, err error) {
+	defer recoverError(&err)
+	var l Loader
+	var pages, pixels atomic.Int64
+	lr := limitReader(r, "descriptor", limits.maxDescriptorSize())
+	f, err = l.read("bmfont", lr, func(filename string) (io.ReadCloser, error) {
+		if pages.Add(1) > int64(limits.maxPages()) {
+			return nil, fmt.Errorf("bmfont: font has more than %d pages", limits.maxPages())
+		}
+		return readSheetSafe(sheets, filename, limits, &pixels)
+	})
+	if lr.err != nil {
+		return nil, lr.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range sortedKeys(f.Descriptor.Chars) {
+		ch := f.Descriptor.Chars[r]
+		sheet := f.PageSheets[ch.Page]
+		if sheet == nil {
+			return nil, fmt.Errorf("bmfont: character %U references missing page %d", r, ch.Page)
+		}
+		if ch.Width < 0 || ch.Height < 0 || !ch.Bounds().In(sheet.Bounds()) {
+			return nil, fmt.Errorf("bmfont: character %U has bitmap %v outside of page %d", r, ch.Bounds(), ch.Page)
+		}
+	}
+	return f, nil
+}
+
+// readSheetSafe reads a page sheet image file into memory and checks its
+// size and the dimensions of the image within the limits. The pixels of the
+// image are added to the total number of pixels of all sheets.
+func readSheetSafe(sheets SheetReaderFunc, filename string, limits *SafeLimits, total *atomic.Int64) (rc io.ReadCloser, err error) {
+	r, err := sheets(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer closeChecked(r, &err)
+	data, err := io.ReadAll(limitReader(r, "sheet "+filename, limits.maxSheetSize()))
+	if err != nil {
+		return nil, err
+	}
+	config, err := decodeSheetConfig(filename, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 ||
+		int64(config.Width)*int64(config.Height) > limits.maxSheetPixels() {
+		return nil, fmt.Errorf("bmfont: sheet %s has size %dx%d, which exceeds %d pixels",
+			filename, config.Width, config.Height, limits.maxSheetPixels())
+	}
+	if total.Add(int64(config.Width)*int64(config.Height)) > limits.maxTotalPixels() {
+		return nil, fmt.Errorf("bmfont: page sheets exceed %d pixels in total", limits.maxTotalPixels())
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// limitReader returns a reader that fails with an error once more than n
+// bytes have been read from r.
+func limitReader(r io.Reader, what string, n int64) *sizeLimitedReader {
+	return &sizeLimitedReader{r: r, what: what, limit: n, n: n}
+}
+
+type sizeLimitedReader struct {
+	r     io.Reader
+	what  string
+	limit int64
+	// n is the number of bytes that may still be read.
+	n int64
+	// err is set once the limit is exceeded.
+	err error
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		l.err = fmt.Errorf("bmfont: %s exceeds the size limit of %d bytes", l.what, l.limit)
+		return int(l.n), l.err
+	}
+	l.n -= int64(n)
+	return n, err
+}
+
+// recoverError turns a panic into an error assigned to *err. It must be
+// called directly via defer.
+func recoverError(err *error) {
+	if v := recover(); v != nil {
+		*err = fmt.Errorf("bmfont: invalid input: %v", v)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fuzzLimits are small limits, so that fuzzing doesn't spend its time on
+// allocating large page sheets.
+var fuzzLimits = &SafeLimits{
+	MaxDescriptorSize: 64 << 10,
+	MaxPages:          4,
+	MaxSheetSize:      64 << 10,
+	MaxSheetPixels:    256 * 256,
+	MaxTotalPixels:    512 * 256,
+}
+
+func FuzzParseDescriptorSafe(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d, err := ParseDescriptorSafe(bytes.NewReader(data), fuzzLimits)
+		if err != nil {
+			return
+		}
+		if d == nil {
+			t.Fatal("ParseDescriptorSafe returned neither a descriptor nor an error")
+		}
+		var buf bytes.Buffer
+		if err := d.WriteText(&buf); err != nil {
+			t.Fatalf("writing parsed descriptor: %v", err)
+		}
+	})
+}
+
+func FuzzReadSafe(f *testing.F) {
+	f.Fuzz(func(t *testing.T, descriptor, sheet []byte) {
+		font, err := ReadSafe(bytes.NewReader(descriptor), func(string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(sheet)), nil
+		}, fuzzLimits)
+		if err != nil {
+			return
+		}
+		var text strings.Builder
+		for _, r := range sortedKeys(font.Descriptor.Chars) {
+			text.WriteRune(r)
+		}
+		// Drawing text with a font read by ReadSafe must not panic.
+		dst := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+		font.DrawText(dst, image.Pt(2, 2), text.String())
+		font.MeasureText(text.String())
+	})
+}
+
+func TestReadSafeRejectsCharOutsideSheet(t *testing.T) {
+	descriptor := `info face="Test" size=8
+common lineHeight=8 base=6 scaleW=4 scaleH=4 pages=1
+page id=0 file="sheet.png"
+chars count=1
+char id=65 x=2 y=0 width=4 height=4 xoffset=0 yoffset=0 xadvance=5 page=0 chnl=15
+`
+	var sheet bytes.Buffer
+	if err := png.Encode(&sheet, image.NewNRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ReadSafe(strings.NewReader(descriptor), func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(sheet.Bytes())), nil
+	}, nil)
+	if err == nil {
+		t.Fatal("ReadSafe accepted a character outside of its page sheet")
+	}
+}
+
+func TestReadSafeTotalPixels(t *testing.T) {
+	descriptor := `info face="Test" size=8
+common lineHeight=8 base=6 scaleW=16 scaleH=16 pages=2
+page id=0 file="sheet0.png"
+page id=1 file="sheet1.png"
+chars count=2
+char id=65 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0 chnl=15
+char id=66 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=1 chnl=15
+`
+	var sheet bytes.Buffer
+	if err := png.Encode(&sheet, image.NewNRGBA(image.Rect(0, 0, 16, 16))); err != nil {
+		t.Fatal(err)
+	}
+	sheets := func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(sheet.Bytes())), nil
+	}
+	if _, err := ReadSafe(strings.NewReader(descriptor), sheets, &SafeLimits{MaxTotalPixels: 2 * 16 * 16}); err != nil {
+		t.Fatalf("ReadSafe within the pixel budget: %v", err)
+	}
+	if _, err := ReadSafe(strings.NewReader(descriptor), sheets, &SafeLimits{MaxTotalPixels: 16*16 + 1}); err == nil {
+		t.Fatal("ReadSafe exceeded the pixel budget")
+	}
+}
+
+func TestParseDescriptorSafeCountAllocs(t *testing.T) {
+	// Each count tag declares far more entries than the input can hold.
+	input := strings.Repeat("chars count=65536\nkernings count=65536\n", 4096)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := ParseDescriptorSafe(strings.NewReader(input), nil); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+	// The allocations must stay proportional to the input size.
+	if got, limit := after.TotalAlloc-before.TotalAlloc, uint64(128*len(input)); got > limit {
+		t.Errorf("parsing %d bytes allocated %d bytes, want at most %d", len(input), got, limit)
+	}
+}
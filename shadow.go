@@ -0,0 +1,129 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// A Shadow is a drop shadow that is drawn behind the text, see Style.Shadow.
+type Shadow struct {
+	// Offset is the offset of the shadow from the text in pixels, scaled
+	// like the glyphs.
+	Offset image.Point
+	// Color is the color of the shadow. If it is nil a half transparent
+	// black is used.
+	Color color.Color
+	// Blur is the radius of the blur of the shadow in pixels, scaled like
+	// the glyphs. If it is 0 the shadow is a sharp silhouette of the text.
+	Blur int
+}
+
+func (s *Style) shadow() *Shadow {
+	if s == nil {
+		return nil
+	}
+	return s.Shadow
+}
+
+// shadowGeometry returns the scaled offset and blur radius of the shadow of
+// the style.
+func (s *Style) shadowGeometry() (offset image.Point, blur int) {
+	sh, k := s.shadow(), s.scale()
+	offset = image.Pt(int(math.Round(float64(sh.Offset.X)*k)), int(math.Round(float64(sh.Offset.Y)*k)))
+	return offset, int(math.Round(float64(sh.Blur) * k))
+}
+
+// shadowBounds returns the bounds of the shadow of text with the given
+// bounds, which may be empty if the style has no shadow.
+func (s *Style) shadowBounds(text image.Rectangle) image.Rectangle {
+	if s.shadow() == nil || text.Empty() {
+		return image.Rectangle{}
+	}
+	offset, blur := s.shadowGeometry()
+	return text.Add(offset).Inset(-blur)
+}
+
+// drawShadow draws the shadow of the style for the layout drawn at the given
+// origin: the silhouette of the glyphs is rendered into an alpha mask, which
+// is blurred and used to draw the shadow color.
+func (l *layout) drawShadow(dst draw.Image, origin fixed.Point26_6, style *Style) {
+	sh := style.shadow()
+	if sh == nil {
+		return
+	}
+	dst = style.clip(dst)
+	offset, blur := style.shadowGeometry()
+	origin = origin.Add(fixed.P(offset.X, offset.Y))
+	var m boundsMeasurer
+	l.drawFixed(&m, origin, nil)
+	bounds := m.bounds.Inset(-blur).Intersect(dst.Bounds())
+	if bounds.Empty() {
+		return
+	}
+	// The silhouette includes the outline, but not the transparency of the
+	// text, which is applied to the shadow color.
+	silhouette := l
+	if l.outlineColor != nil || l.colored {
+		silhouette = l.clone()
+		silhouette.eachGlyph(func(_ int, g *glyph) { g.color = nil })
+	}
+	if l.outlineColor != nil {
+		silhouette.glyphColor, silhouette.outlineColor = color.White, color.White
+	}
+	mask := image.NewAlpha(bounds)
+	silhouette.drawFixed(imageDrawer{dst: mask}, origin, nil)
+	// Three box blurs approximate a Gaussian blur, whose extent is the
+	// sum of their radii.
+	passes, radius := 3, blur/3
+	if radius == 0 {
+		passes, radius = 1, blur
+	}
+	for i := 0; radius > 0 && i < passes; i++ {
+		boxBlur(mask, radius)
+	}
+	c := sh.Color
+	if c == nil {
+		c = color.NRGBA{A: 0x80}
+	}
+	draw.DrawMask(dst, bounds, image.NewUniform(style.fade(c)), image.Point{}, mask, bounds.Min, draw.Over)
+}
+
+// boxBlur blurs the alpha mask with a box filter of the given radius,
+// horizontally and vertically.
+func boxBlur(mask *image.Alpha, radius int) {
+	b := mask.Rect
+	w, h := b.Dx(), b.Dy()
+	buf := make([]uint8, max(w, h))
+	blur := func(n int, at func(i int) *uint8) {
+		sum := 0
+		for i := -radius; i < n+radius; i++ {
+			if j := i + radius; j < n {
+				sum += int(*at(j))
+			}
+			if j := i - radius - 1; j >= 0 {
+				sum -= int(*at(j))
+			}
+			if i >= 0 && i < n {
+				buf[i] = uint8(sum / (2*radius + 1))
+			}
+		}
+		for i := 0; i < n; i++ {
+			*at(i) = buf[i]
+		}
+	}
+	for y := 0; y < h; y++ {
+		row := mask.Pix[y*mask.Stride:]
+		blur(w, func(i int) *uint8 { return &row[i] })
+	}
+	for x := 0; x < w; x++ {
+		blur(h, func(i int) *uint8 { return &mask.Pix[i*mask.Stride+x] })
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestShadow(t *testing.T) {
+	f := opaqueFont("A")
+	red := color.RGBA{R: 0xff, A: 0xff}
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	f.DrawTextOpts(dst, image.Pt(2, 8), "A", WithShadow(image.Pt(2, 2), red, 0))
+	tests := []struct {
+		p    image.Point
+		want color.RGBA
+	}{
+		{image.Pt(3, 3), white},
+		{image.Pt(5, 7), white}, // the glyph is drawn over the shadow
+		{image.Pt(7, 9), red},
+		{image.Pt(8, 9), color.RGBA{}},
+	}
+	for _, tt := range tests {
+		if got := dst.RGBAAt(tt.p.X, tt.p.Y); got != tt.want {
+			t.Errorf("got %v at %v, want %v", got, tt.p, tt.want)
+		}
+	}
+	if got, want := f.MeasureTextOpts("A", WithShadow(image.Pt(2, 2), red, 0)), image.Rect(0, -6, 6, 2); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+	// The blur and the offset are scaled like the glyphs.
+	if got, want := f.MeasureTextOpts("A", WithShadow(image.Pt(2, 2), red, 1), WithScale(2)), image.Rect(0, -12, 14, 6); got != want {
+		t.Errorf("got scaled bounds %v, want %v", got, want)
+	}
+}
+
+func TestShadowBlur(t *testing.T) {
+	f := opaqueFont("A")
+	dst := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	f.DrawTextOpts(dst, image.Pt(4, 10), "A", WithShadow(image.Pt(0, 0), nil, 2), WithColor(color.Transparent))
+	// The default shadow color is half transparent black.
+	if got := dst.RGBAAt(6, 7); got.A == 0 || got.A > 0x80 || got.R != 0 {
+		t.Errorf("got %v at the center of the shadow, want a half transparent black", got)
+	}
+	// The blur extends beyond the glyph.
+	if got := dst.RGBAAt(3, 7).A; got == 0 {
+		t.Error("blurred shadow doesn't extend beyond the glyph")
+	}
+	if got := dst.RGBAAt(1, 7).A; got != 0 {
+		t.Errorf("got alpha %d beyond the blur radius, want 0", got)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Stats holds rendering statistics of a bitmap font. They can be used to
+// monitor the text rendering cost and the coverage of the font for the drawn
+// texts, e.g. for localizations. There are no cache hit and miss counters,
+// because the glyphs are drawn directly from the page sheets without a
+// glyph cache.
+type Stats struct {
+	// GlyphsDrawn is the number of glyphs drawn by DrawText and related
+	// drawing methods.
+	GlyphsDrawn int64
+	// FallbackGlyphs is the number of times the fallback glyph ('?') was
+	// drawn in place of a character that is not included in the font.
+	FallbackGlyphs int64
+	// MissingRunes counts for each rune that is not included in the font how
+	// many times it was encountered while drawing.
+	MissingRunes map[rune]int64
+}
+
+// Stats returns a snapshot of the rendering statistics collected since the
+// font was loaded or since the last call of ResetStats.
+// It is safe to call Stats while other goroutines draw text with the font.
+func (f *BitmapFont) Stats() Stats {
+	f.stats.mu.Lock()
+	defer f.stats.mu.Unlock()
+	missing := make(map[rune]int64, len(f.stats.missing))
+	for r, n := range f.stats.missing {
+		missing[r] = n
+	}
+	return Stats{
+		GlyphsDrawn:    f.stats.glyphsDrawn.Load(),
+		FallbackGlyphs: f.stats.fallbackGlyphs.Load(),
+		MissingRunes:   missing,
+	}
+}
+
+// ResetStats resets all rendering statistics of the font to zero.
+func (f *BitmapFont) ResetStats() {
+	f.stats.mu.Lock()
+	defer f.stats.mu.Unlock()
+	f.stats.glyphsDrawn.Store(0)
+	f.stats.fallbackGlyphs.Store(0)
+	f.stats.missing = nil
+}
+
+type fontStats struct {
+	glyphsDrawn    atomic.Int64
+	fallbackGlyphs atomic.Int64
+
+	mu      sync.Mutex
+	missing map[rune]int64
+}
+
+func (s *fontStats) countMissing(r rune, fallback bool) {
+	if fallback {
+		s.fallbackGlyphs.Add(1)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.missing == nil {
+		s.missing = make(map[rune]int64)
+	}
+	s.missing[r]++
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	f := testFont("AB?", 1)
+	dst := image.NewNRGBA(image.Rect(0, 0, 64, 16))
+	f.DrawText(dst, image.Pt(0, 8), "ABxAy")
+	f.DrawText(dst, image.Pt(0, 8), "x")
+	s := f.Stats()
+	if s.GlyphsDrawn != 6 {
+		t.Errorf("got %d glyphs drawn, want 6", s.GlyphsDrawn)
+	}
+	if s.FallbackGlyphs != 3 {
+		t.Errorf("got %d fallback glyphs, want 3", s.FallbackGlyphs)
+	}
+	if s.MissingRunes['x'] != 2 || s.MissingRunes['y'] != 1 || len(s.MissingRunes) != 2 {
+		t.Errorf("got missing runes %v, want x twice and y once", s.MissingRunes)
+	}
+
+	f.ResetStats()
+	if s := f.Stats(); s.GlyphsDrawn != 0 || s.FallbackGlyphs != 0 || len(s.MissingRunes) != 0 {
+		t.Errorf("got %+v after ResetStats, want zero stats", s)
+	}
+}
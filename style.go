@@ -0,0 +1,440 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// A Style describes how a text is laid out and rendered, e.g. by
+// RenderToImage. A nil *Style is valid and is equivalent to the zero Style.
+type Style struct {
+	// Color is the color the glyphs are tinted with. The colors of the page
+	// sheets are multiplied with it, so for the usual white glyphs the text
+	// is drawn in this color. If it is nil the glyphs are drawn with the
+	// colors of the page sheets.
+	Color color.Color
+	// Scale is the factor by which the glyphs and all metrics are scaled.
+	// If it is 0 the text is drawn unscaled.
+	Scale float64
+	// MaxWidth is the maximum width of a line in pixels. If it is greater
+	// than 0, lines that are wider are wrapped at word boundaries, i.e. at
+	// spaces and after hyphens and dashes, or truncated.
+	MaxWidth int
+	// Truncate truncates lines that are wider than MaxWidth instead of
+	// wrapping them, and ends them with the Ellipsis. The advance width of
+	// the truncated line including the ellipsis doesn't exceed MaxWidth.
+	Truncate bool
+	// MaxLines is the maximum number of lines of the text, if it is greater
+	// than 0. The lines after it are omitted as specified by Overflow.
+	// DrawColumns ignores it.
+	MaxLines int
+	// Overflow is the policy for text with more than MaxLines lines.
+	Overflow Overflow
+	// Ellipsis is the text that truncated lines end with. If it is empty
+	// "…" is used, or "..." if the font doesn't include U+2026.
+	Ellipsis string
+	// Align is the horizontal alignment of the lines of text, within
+	// MaxWidth if it is set, or else within the width of the widest line.
+	Align Alignment
+	// Background is the color the image is filled with before the text is
+	// drawn. If it is nil the background is transparent.
+	Background color.Color
+	// Padding is the space in pixels between the edges of the image and the
+	// bounding box of the text.
+	Padding Padding
+	// SmallCaps draws lowercase letters as small capitals, synthesized from
+	// the scaled down uppercase glyphs of the font.
+	SmallCaps bool
+	// SmallCapsScale is the size of the synthesized small capitals relative
+	// to the uppercase letters. If it is 0 a factor of 0.75 is used.
+	SmallCapsScale float64
+	// Case is the case transformation applied to the text before it is
+	// laid out.
+	Case TextCase
+	// Language is the language of the text. It selects the language
+	// specific rules of the case transformation, e.g. the dotted and dotless
+	// i of Turkish. If it is the zero Tag language independent rules are used.
+	Language language.Tag
+	// LineSpacing is the factor the line height of the font is multiplied
+	// with. If it is 0 the line height of the font is used.
+	LineSpacing float64
+	// LineHeight overrides the line height of the font in pixels, i.e.
+	// Common.LineHeight of the descriptor, if it is greater than 0. It is
+	// scaled like the glyphs and multiplied with LineSpacing.
+	LineHeight int
+	// Leading is the amount in pixels that is added to the distance between
+	// the base lines of consecutive lines, scaled like the glyphs. Negative
+	// values tighten the lines.
+	Leading float64
+	// LineRounding specifies how the scaled distance between the base lines
+	// of consecutive lines is rounded to pixels.
+	LineRounding LineRounding
+	// GlyphRounding specifies how the position of each glyph is rounded to
+	// pixels. Fractional positioning yields the most even spacing, but
+	// blurs the glyphs slightly, since they are resampled.
+	GlyphRounding GlyphRounding
+	// SnapBaselines rounds the base line of each line to a whole pixel, so
+	// that all lines are rasterized the same way.
+	SnapBaselines bool
+	// DeviceScale is the number of device pixels per logical pixel, e.g. 2
+	// for high resolution displays. The glyphs are drawn to the destination
+	// image, whose pixels are device pixels, scaled by both Scale and
+	// DeviceScale, using the closest prescaled variant of the font if it
+	// has any. MaxWidth, Padding and the measurements of MeasureLines are
+	// in logical pixels. If it is 0 logical and device pixels are the same.
+	DeviceScale float64
+	// DisableKerning ignores the kerning pairs of the font, so that the
+	// characters are positioned by their advances only.
+	DisableKerning bool
+	// Tracking is the amount in pixels that is added to the advance of each
+	// character, scaled like the glyphs, independently of the prescaled
+	// variant that is used. Positive values loosen and negative values
+	// tighten the spacing of the characters.
+	Tracking float64
+	// TabStops are the positions of tab stops in pixels from the start of
+	// a line, in ascending order, scaled like the glyphs. A tab character
+	// advances to the next tab stop. After the last one, the tab stops are
+	// spaced by TabWidth.
+	TabStops []int
+	// TabWidth is the distance between tab stops in pixels, scaled like the
+	// glyphs. If it is 0 the tab stops are spaced by eight times the advance
+	// of the space character of the font.
+	TabWidth int
+	// Transparency fades the text out: the alpha values of the glyphs,
+	// their outlines, shadows and highlights are multiplied with
+	// 1-Transparency. If it is 0 the text is opaque, and if it is 1 the text
+	// is invisible.
+	Transparency float64
+	// OutlineColor is the color the outline of fonts exported with an
+	// outline is drawn in, if it is not nil. The glyphs and their outlines
+	// are then separated by the channel information of the font, e.g. for
+	// fonts that hold the glyph in the color channels and the outline in the
+	// alpha channel, and the glyphs are drawn in Color, or in white if it is
+	// nil, over the outline.
+	OutlineColor color.Color
+	// Compositor composites the glyphs with the destination image when the
+	// text is drawn. If it is nil they are drawn over the destination with
+	// draw.Over. With draw.Src the glyph bitmaps replace the pixels of the
+	// destination, e.g. for rendering the text into an alpha mask; then the
+	// transparent margins of adjacent glyph bitmaps may overlap the glyphs.
+	// Other draw.Drawer implementations are called for each glyph with the
+	// glyph bitmap rendered to a temporary image.
+	Compositor draw.Drawer
+	// Clip is the rectangle of the destination image that drawing is
+	// restricted to, if it is not empty. Glyphs that cross its edges are cut
+	// off at them, e.g. for scrolling text boxes and marquees.
+	Clip image.Rectangle
+	// Highlight is a box that is filled behind the text and its shadow, or
+	// behind each line, if it is not nil. It is included in the bounding
+	// box of the text, like the shadow. DrawTextRotated ignores it.
+	Highlight *Highlight
+	// Shadow is a drop shadow that is drawn behind the text, if it is not
+	// nil. It is included in the bounding box of the text, e.g. for
+	// MeasureTextOpts and RenderToImage. DrawTextRotated ignores it.
+	Shadow *Shadow
+	// Slant is the angle in radians by which the glyphs are sheared to the
+	// right around their base line, producing a synthetic oblique for fonts
+	// without an italic variant, e.g. 0.2 for about 11 degrees. Negative
+	// values shear the glyphs to the left. The rectangles reported by Quads
+	// are not sheared.
+	Slant float64
+	// Visible is the range of the glyphs that are drawn, if it is not nil,
+	// e.g. for revealing dialog text glyph by glyph. The text is laid out
+	// completely, so that the line breaks and the positions of the glyphs
+	// don't change while more of it is revealed.
+	Visible *GlyphRange
+	// GlyphFunc is called for each glyph before the text is drawn, if it is
+	// not nil, and can adjust the position, the scale and the color of the
+	// glyph, or skip it. It doesn't affect the measurements of the text.
+	GlyphFunc GlyphFunc
+	// Images are the inline images that are drawn in place of placeholders
+	// of the form {icon:name} in the text, e.g. {icon:coin} for the image
+	// named "coin". They take part in the layout like glyphs, including
+	// line wrapping. Placeholders with unknown names are drawn as text.
+	Images map[string]*InlineImage
+	// Fonts are the fonts that markup can select by name, e.g. "bold" for
+	// the tag [font=bold], see ParseMarkup.
+	Fonts map[string]*BitmapFont
+	// JustifyCharacters distributes the remaining space of justified lines
+	// between all characters instead of between words, e.g. for CJK text,
+	// which has no spaces between words.
+	JustifyCharacters bool
+}
+
+// Alignment is the horizontal alignment of lines of text.
+type Alignment int
+
+const (
+	AlignLeft Alignment = iota
+	AlignCenter
+	AlignRight
+	// AlignJustify distributes the remaining space of each line between
+	// its words, so that the lines are flush with both edges. The last
+	// line of each paragraph is left aligned.
+	AlignJustify
+)
+
+// TextCase is a case transformation of a text.
+type TextCase int
+
+const (
+	KeepCase  TextCase = iota // the text is drawn as it is
+	UpperCase                 // all letters are drawn in upper case
+	LowerCase                 // all letters are drawn in lower case
+	TitleCase                 // the first letter of each word is drawn in upper case
+)
+
+// LineRounding specifies how a line advance with a fractional pixel amount,
+// e.g. due to scaling or line spacing, is rounded.
+type LineRounding int
+
+const (
+	LineFractional LineRounding = iota // kept fractional
+	LineRound                          // rounded to the nearest pixel
+	LineFloor                          // rounded down to a whole pixel
+)
+
+// GlyphRounding specifies how the fractional pixel positions of glyphs, e.g.
+// due to scaling, fractional metrics or a fractional start position, are
+// rounded when the glyphs are drawn.
+type GlyphRounding int
+
+const (
+	GlyphRound      GlyphRounding = iota // rounded to the nearest pixel
+	GlyphFloor                           // rounded down to a whole pixel
+	GlyphFractional                      // kept fractional, resampling the glyphs
+)
+
+// clip returns the destination image restricted to the clip rectangle of
+// the style, if it has one.
+func (s *Style) clip(dst draw.Image) draw.Image {
+	if s == nil || s.Clip.Empty() {
+		return dst
+	}
+	return clipImage(dst, s.Clip)
+}
+
+func (s *Style) compositor() draw.Drawer {
+	if s == nil {
+		return nil
+	}
+	return s.Compositor
+}
+
+// color returns the color the glyphs are tinted with when they are drawn.
+// It is nil if the glyphs and outlines are drawn with separate colors,
+// because these are applied when the channels are separated.
+func (s *Style) color() color.Color {
+	if s == nil || s.OutlineColor != nil {
+		return nil
+	}
+	return s.fade(s.Color)
+}
+
+// fade returns the color with the transparency of the style applied. A nil
+// color stands for the colors of the page sheets, which are faded by a
+// transparent white tint.
+func (s *Style) fade(c color.Color) color.Color {
+	if s == nil || s.Transparency <= 0 {
+		return c
+	}
+	k := uint32(math.Round((1 - min(s.Transparency, 1)) * 0xffff))
+	if c == nil {
+		return color.Alpha16{A: uint16(k)}
+	}
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{
+		R: uint16(r * k / 0xffff),
+		G: uint16(g * k / 0xffff),
+		B: uint16(b * k / 0xffff),
+		A: uint16(a * k / 0xffff),
+	}
+}
+
+// scale returns the effective scale factor from the font's pixels to device
+// pixels.
+func (s *Style) scale() float64 {
+	if s == nil || s.Scale == 0 {
+		return s.deviceScale()
+	}
+	return s.Scale * s.deviceScale()
+}
+
+func (s *Style) deviceScale() float64 {
+	if s == nil || s.DeviceScale == 0 {
+		return 1
+	}
+	return s.DeviceScale
+}
+
+// maxWidth returns the maximum line width in device pixels.
+func (s *Style) maxWidth() int {
+	if s == nil {
+		return 0
+	}
+	return s.device(s.MaxWidth)
+}
+
+// padding returns the padding of the style in device pixels.
+func (s *Style) padding() Padding {
+	if s == nil {
+		return Padding{}
+	}
+	return Padding{
+		Up:    s.device(s.Padding.Up),
+		Right: s.device(s.Padding.Right),
+		Down:  s.device(s.Padding.Down),
+		Left:  s.device(s.Padding.Left),
+	}
+}
+
+// device converts logical pixels to device pixels.
+func (s *Style) device(px int) int {
+	return int(math.Round(float64(px) * s.deviceScale()))
+}
+
+// logical converts device pixels to logical pixels.
+func (s *Style) logical(px int) int {
+	return int(math.Round(float64(px) / s.deviceScale()))
+}
+
+func (s *Style) kerning() bool {
+	return s == nil || !s.DisableKerning
+}
+
+func (s *Style) tracking() float64 {
+	if s == nil {
+		return 0
+	}
+	return s.Tracking
+}
+
+// nextTabStop returns the position of the first tab stop after x, for a font
+// with the given scaled advance of the space character. The tab stops are
+// scaled with the scale of the style, independently of the prescaled
+// variant that is used.
+func (s *Style) nextTabStop(x, space fixed.Int26_6) fixed.Int26_6 {
+	width := 8 * space
+	if s != nil {
+		scale := s.scale()
+		for _, stop := range s.TabStops {
+			if p := scaled(stop, scale); p > x {
+				return p
+			}
+		}
+		if s.TabWidth > 0 {
+			width = scaled(s.TabWidth, scale)
+		}
+	}
+	if width <= 0 {
+		return x
+	}
+	return (x/width + 1) * width
+}
+
+func (s *Style) glyphRounding() GlyphRounding {
+	if s == nil {
+		return GlyphRound
+	}
+	return s.GlyphRounding
+}
+
+// shear returns the horizontal shift per pixel above the base line for the
+// slant of the style.
+func (s *Style) shear() float64 {
+	if s == nil || s.Slant == 0 {
+		return 0
+	}
+	return math.Tan(s.Slant)
+}
+
+func (s *Style) justifyCharacters() bool {
+	return s != nil && s.JustifyCharacters
+}
+
+func (s *Style) align() Alignment {
+	if s == nil {
+		return AlignLeft
+	}
+	return s.Align
+}
+
+// smallCaps returns the scale factor of small capitals relative to the
+// uppercase letters, or 0 if small caps are disabled.
+func (s *Style) smallCaps() float64 {
+	switch {
+	case s == nil || !s.SmallCaps:
+		return 0
+	case s.SmallCapsScale == 0:
+		return 0.75
+	}
+	return s.SmallCapsScale
+}
+
+// transform applies the case transformation of the style to the text.
+func (s *Style) transform(text string) string {
+	if s == nil {
+		return text
+	}
+	var c cases.Caser
+	switch s.Case {
+	case UpperCase:
+		c = cases.Upper(s.Language)
+	case LowerCase:
+		c = cases.Lower(s.Language)
+	case TitleCase:
+		c = cases.Title(s.Language)
+	default:
+		return text
+	}
+	if len(s.Images) > 0 {
+		return transformOutsideImages(c, text)
+	}
+	return c.String(text)
+}
+
+// lineAdvance returns the distance between the base lines of consecutive
+// lines for a font with the given line height, scaled by the given factor.
+// The line height override and the leading of the style are scaled by the
+// effective scale of the style instead, because the font may be a
+// prescaled variant.
+func (s *Style) lineAdvance(lineHeight int, scale float64) fixed.Int26_6 {
+	if s == nil {
+		return scaled(lineHeight, scale)
+	}
+	spacing := s.LineSpacing
+	if spacing == 0 {
+		spacing = 1
+	}
+	height := float64(lineHeight) * scale
+	if s.LineHeight > 0 {
+		height = float64(s.LineHeight) * s.scale()
+	}
+	advance := height*spacing + s.Leading*s.scale()
+	switch s.LineRounding {
+	case LineRound:
+		advance = math.Round(advance)
+	case LineFloor:
+		advance = math.Floor(advance)
+	}
+	return fixed.Int26_6(math.Round(advance * 64))
+}
+
+// baseline returns the vertical position of a base line, snapped to the
+// pixel grid if the style requests it.
+func (s *Style) baseline(y fixed.Int26_6) fixed.Int26_6 {
+	if s == nil || !s.SnapBaselines {
+		return y
+	}
+	return fixed.I(y.Round())
+}
@@ -0,0 +1,177 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/language"
+)
+
+func TestSmallCaps(t *testing.T) {
+	f := testFont("AB1", 1)
+	tests := []struct {
+		style      *Style
+		wantRunes  string
+		wantScales []float64
+	}{
+		{&Style{SmallCaps: true}, "AB1", []float64{1, 0.75, 1}},
+		{&Style{SmallCaps: true, SmallCapsScale: 0.5}, "AB1", []float64{1, 0.5, 1}},
+		{&Style{}, "A1", []float64{1, 1}},
+	}
+	for _, tt := range tests {
+		l := f.layout("Ab1", tt.style, nil)
+		if got := layoutRunes(l)[0]; got != tt.wantRunes {
+			t.Errorf("%+v: got glyphs %q, want %q", tt.style, got, tt.wantRunes)
+			continue
+		}
+		for i, g := range l.lines[0].glyphs {
+			if g.scale != tt.wantScales[i] {
+				t.Errorf("%+v: glyph %d: got scale %g, want %g", tt.style, i, g.scale, tt.wantScales[i])
+			}
+			if want := fixed.Int26_6(5 * 64 * tt.wantScales[i]); g.advance != want {
+				t.Errorf("%+v: glyph %d: got advance %v, want %v", tt.style, i, g.advance, want)
+			}
+		}
+	}
+}
+
+func TestStyleCase(t *testing.T) {
+	tests := []struct {
+		style *Style
+		text  string
+		want  string
+	}{
+		{nil, "Hello", "Hello"},
+		{&Style{Case: UpperCase}, "straße", "STRASSE"},
+		{&Style{Case: LowerCase}, "HELLO", "hello"},
+		{&Style{Case: TitleCase}, "hello world", "Hello World"},
+		{&Style{Case: UpperCase, Language: language.Turkish}, "istanbul", "İSTANBUL"},
+		{&Style{Case: LowerCase, Language: language.Turkish}, "ISPARTA", "ısparta"},
+	}
+	for _, tt := range tests {
+		if got := tt.style.transform(tt.text); got != tt.want {
+			t.Errorf("%+v: transform(%q) = %q, want %q", tt.style, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStyleCaseAppliesToLayout(t *testing.T) {
+	f := testFont("AB ", 1)
+	l := f.layout("ab", &Style{Case: UpperCase}, nil)
+	if got := layoutRunes(l)[0]; got != "AB" {
+		t.Errorf("got glyphs %q, want %q", got, "AB")
+	}
+	// The text that fits into the columns is part of the transformed text.
+	columns := Columns{Count: 1, Width: 100, Height: 100}
+	if fit, overflow := f.FitColumns("ab ba", columns, &Style{Case: UpperCase}); fit != "AB BA" || overflow != "" {
+		t.Errorf("got fit %q and overflow %q, want %q and none", fit, overflow, "AB BA")
+	}
+}
+
+func TestStyleLineAdvance(t *testing.T) {
+	tests := []struct {
+		style *Style
+		scale float64
+		want  fixed.Int26_6
+	}{
+		{nil, 1.5, fixed.I(12)},
+		{&Style{LineSpacing: 1.3}, 1, 666},
+		{&Style{LineSpacing: 1.3, LineRounding: LineRound}, 1, fixed.I(10)},
+		{&Style{LineSpacing: 1.45, LineRounding: LineRound}, 1, fixed.I(12)},
+		{&Style{LineSpacing: 1.45, LineRounding: LineFloor}, 1, fixed.I(11)},
+		{&Style{LineHeight: 10, Leading: 2, Scale: 2}, 2, fixed.I(24)},
+	}
+	for _, tt := range tests {
+		if got := tt.style.lineAdvance(8, tt.scale); got != tt.want {
+			t.Errorf("%+v: got line advance %v, want %v", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestStyleSnapBaselines(t *testing.T) {
+	f := testFont("A", 1)
+	for _, tt := range []struct {
+		snap bool
+		want []fixed.Int26_6
+	}{
+		{false, []fixed.Int26_6{0, 666, 2 * 666}},
+		{true, []fixed.Int26_6{0, fixed.I(10), fixed.I(21)}},
+	} {
+		l := f.layout("A\nA\nA", &Style{LineSpacing: 1.3, SnapBaselines: tt.snap}, nil)
+		for i, ln := range l.lines {
+			if ln.y != tt.want[i] {
+				t.Errorf("snap %v: line %d: got base line %v, want %v", tt.snap, i, ln.y, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestStyleLineHeightAndLeading(t *testing.T) {
+	f := testFont("A", 1)
+	f.Variants = map[float64]*BitmapFont{2: testFont("A", 2)}
+	tests := []struct {
+		style *Style
+		want  fixed.Int26_6 // the base line of the second line
+	}{
+		{&Style{LineHeight: 12}, fixed.I(12)},
+		{&Style{Leading: -2}, fixed.I(6)},
+		{&Style{LineHeight: 12, Leading: 1.5}, fixed.I(27) / 2},
+		{&Style{LineHeight: 10, LineSpacing: 1.5}, fixed.I(15)},
+		// The prescaled variant doesn't change the scaled line height.
+		{&Style{LineHeight: 10, Leading: 1, Scale: 2}, fixed.I(22)},
+	}
+	for _, tt := range tests {
+		l := f.layout("A\nA", tt.style, nil)
+		if got := l.lines[1].y; got != tt.want {
+			t.Errorf("%+v: got base line %v, want %v", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestStyleFade(t *testing.T) {
+	tests := []struct {
+		style *Style
+		c     color.Color
+		want  color.Color
+	}{
+		{nil, nil, nil},
+		{&Style{}, color.White, color.White},
+		{&Style{Transparency: 0.5}, nil, color.Alpha16{A: 0x8000}},
+		{&Style{Transparency: 0.5}, color.RGBA{R: 0xff, A: 0xff}, color.RGBA64{R: 0x8000, A: 0x8000}},
+		{&Style{Transparency: 2}, color.White, color.RGBA64{}},
+	}
+	for _, tt := range tests {
+		if got := tt.style.fade(tt.c); got != tt.want {
+			t.Errorf("%+v: fade(%v) = %v, want %v", tt.style, tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestDrawOpacity(t *testing.T) {
+	f := opaqueFont("A")
+	for _, tt := range []struct {
+		opts []DrawOption
+		want uint8 // the alpha value of the glyph
+	}{
+		{[]DrawOption{WithOpacity(1)}, 0xff},
+		{[]DrawOption{WithOpacity(0.5)}, 0x80},
+		{[]DrawOption{WithOpacity(0.5), WithColor(color.RGBA{R: 0xff, A: 0xff})}, 0x80},
+		{[]DrawOption{WithOpacity(0)}, 0},
+	} {
+		dst := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		f.DrawTextOpts(dst, image.Pt(0, 6), "A", append(tt.opts, WithShadow(image.Pt(4, 0), color.Black, 0))...)
+		if got := dst.RGBAAt(1, 1).A; got != tt.want {
+			t.Errorf("got glyph alpha %d, want %d", got, tt.want)
+		}
+		// The shadow is faded, too.
+		if got := dst.RGBAAt(7, 1).A; got != tt.want {
+			t.Errorf("got shadow alpha %d, want %d", got, tt.want)
+		}
+	}
+}
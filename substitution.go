@@ -0,0 +1,80 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Substitution is a contextual substitution rule that replaces the glyph
+// of a character with an alternate glyph depending on the surrounding
+// characters, e.g. for stylistic alternates or terminal forms of
+// decorative fonts.
+type Substitution struct {
+	// Rune is the character whose glyph is replaced.
+	Rune rune
+	// Before is the set of characters one of which must immediately
+	// precede the character. If it is empty any preceding character
+	// matches.
+	Before string
+	// After is the set of characters one of which must immediately follow
+	// the character. If it is empty any following character matches.
+	After string
+	// Initial restricts the substitution to characters at the start of a
+	// word, which are not preceded by a letter or digit.
+	Initial bool
+	// Final restricts the substitution to characters at the end of a word,
+	// which are not followed by a letter or digit.
+	Final bool
+	// Glyph is the ID of the alternate glyph in the font.
+	Glyph rune
+}
+
+// substitute returns the glyph of the first substitution rule of the font
+// that matches the rune r of size bytes at byte offset i of the text, or r
+// if no rule matches. Rules whose glyph is missing in the font or variant v
+// the glyphs are taken from are ignored.
+func (f *BitmapFont) substitute(v *BitmapFont, text string, i, size int, r rune) rune {
+	prev, next := rune(-1), rune(-1)
+	if i > 0 {
+		prev, _ = utf8.DecodeLastRuneInString(text[:i])
+	}
+	if i+size < len(text) {
+		next, _ = utf8.DecodeRuneInString(text[i+size:])
+	}
+	for _, sub := range f.Substitutions {
+		if sub.Rune != r ||
+			sub.Before != "" && (prev < 0 || !strings.ContainsRune(sub.Before, prev)) ||
+			sub.After != "" && (next < 0 || !strings.ContainsRune(sub.After, next)) ||
+			sub.Initial && isWordChar(prev) ||
+			sub.Final && isWordChar(next) {
+			continue
+		}
+		if _, found := v.Descriptor.Chars[sub.Glyph]; found {
+			return sub.Glyph
+		}
+	}
+	return r
+}
+
+// smallCap returns the uppercase letter for a lowercase letter if the font
+// includes it.
+func (f *BitmapFont) smallCap(r rune) (rune, bool) {
+	if !unicode.IsLower(r) {
+		return r, false
+	}
+	upper := unicode.ToUpper(r)
+	if upper == r {
+		return r, false
+	}
+	_, found := f.Descriptor.Chars[upper]
+	return upper, found
+}
+
+func isWordChar(r rune) bool {
+	return r >= 0 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import "testing"
+
+func TestSubstitutions(t *testing.T) {
+	const (
+		finalS   = '\uE000'
+		initialT = '\uE001'
+		swashE   = '\uE002'
+		missing  = '\uE003'
+	)
+	f := testFont("aest. "+string([]rune{finalS, initialT, swashE}), 1)
+	f.Substitutions = []Substitution{
+		{Rune: 's', Final: true, Glyph: finalS},
+		{Rune: 't', Initial: true, Glyph: initialT},
+		{Rune: 'e', Before: "t", After: "s", Glyph: swashE},
+		{Rune: 'a', Glyph: missing},
+	}
+	tests := []struct {
+		text, want string
+	}{
+		{"ss s.", "s" + string(finalS) + " " + string(finalS) + "."},
+		{"tat", string(initialT) + "at"},
+		{"test", string([]rune{initialT, swashE, 's', 't'})},
+		{"tes", string([]rune{initialT, swashE, finalS})},
+		{"es", "e" + string(finalS)},
+		{"a", "a"},
+	}
+	for _, tt := range tests {
+		if got := layoutRunes(f.layout(tt.text, nil, nil))[0]; got != tt.want {
+			t.Errorf("%q: got glyphs %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
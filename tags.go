@@ -0,0 +1,37 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import "io"
+
+// A Tag is a line of a font descriptor in BMFont's text format, like
+// "char id=65 x=0 y=0 ...".
+type Tag struct {
+	// Name is the name of the tag, e.g. "info", "page" or "char".
+	Name string
+	// Attrs are the attributes of the tag by name. Quoted string values
+	// are unquoted. Integers and lists of integers are kept in their text
+	// form, e.g. "-2" or "1,1".
+	Attrs map[string]string
+	// Line is the line number of the tag in the input, starting at 1.
+	Line int
+}
+
+// ParseTags reads the tags of a font descriptor in BMFont's text format
+// from r and calls fn for each tag as soon as it is read, without building
+// a Descriptor. The input is decoded like by ReadDescriptor. This allows
+// filtering or subsetting descriptors with many characters, e.g. of CJK
+// fonts, in constant memory.
+//
+// If fn returns an error, ParseTags stops and returns that error. Syntax
+// errors don't stop the parsing: the affected tags are passed to fn with the
+// attributes that could be read, and the errors are returned after the end
+// of the input.
+func ParseTags(r io.Reader, fn func(tag Tag) error) error {
+	var p tagsParser
+	return p.parseFunc("bmfont", newTextReader(r), func(t tag) error {
+		return fn(Tag{Name: t.name, Attrs: t.attrs, Line: t.pos.Line})
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	var names []string
+	err := ParseTags(strings.NewReader(testDescriptor), func(tag Tag) error {
+		names = append(names, tag.Name)
+		if tag.Name == "page" {
+			if got := tag.Attrs["file"]; got != "sheet.png" {
+				t.Errorf("got page file %q, want %q", got, "sheet.png")
+			}
+			if tag.Line != 3 {
+				t.Errorf("got page tag in line %d, want 3", tag.Line)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "info common page chars char char kernings kerning"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("got tags %q, want %q", got, want)
+	}
+}
+
+func TestParseTagsStop(t *testing.T) {
+	errStop := errors.New("stop")
+	n := 0
+	err := ParseTags(strings.NewReader(testDescriptor), func(tag Tag) error {
+		n++
+		if tag.Name == "page" {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Errorf("got error %v, want %v", err, errStop)
+	}
+	if n != 3 {
+		t.Errorf("fn was called %d times, want 3", n)
+	}
+}
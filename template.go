@@ -0,0 +1,27 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+	"text/template"
+)
+
+// RenderTemplate executes the template with the given data and draws the
+// resulting text on the destination image at the given position with the
+// given style, e.g. for data-driven labels, scoreboards and reports. The
+// start position is on the base line of the first line, like for DrawText.
+// Lines are wrapped and aligned according to the style. Nothing is drawn if
+// the template fails to execute.
+func (f *BitmapFont) RenderTemplate(dst draw.Image, pos image.Point, tmpl *template.Template, data any, style *Style) error {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return err
+	}
+	f.drawText(dst, pos, sb.String(), style)
+	return nil
+}
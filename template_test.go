@@ -0,0 +1,32 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"testing"
+	"text/template"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	f := testFont("0123456789", 1)
+	dst := image.NewNRGBA(image.Rect(0, 0, 64, 16))
+	tmpl := template.Must(template.New("score").Parse("{{.Score}}"))
+	if err := f.RenderTemplate(dst, image.Pt(0, 8), tmpl, struct{ Score int }{1234}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Stats().GlyphsDrawn; got != 4 {
+		t.Errorf("drew %d glyphs, want 4", got)
+	}
+
+	f.ResetStats()
+	failing := template.Must(template.New("fail").Parse("1{{.Missing}}"))
+	if err := f.RenderTemplate(dst, image.Pt(0, 8), failing, struct{}{}, nil); err == nil {
+		t.Error("got no error for a failing template")
+	}
+	if got := f.Stats().GlyphsDrawn; got != 0 {
+		t.Errorf("drew %d glyphs for a failing template, want none", got)
+	}
+}
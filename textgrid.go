@@ -0,0 +1,173 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// A TextGrid is a grid of character cells of a fixed size, like a text
+// terminal or the screen of a roguelike game. Each cell holds a single rune
+// with its own foreground and background color.
+type TextGrid struct {
+	font       *BitmapFont
+	cols, rows int
+	cells      []Cell
+
+	// CellSize is the size of a cell in pixels. NewTextGrid initializes it
+	// with the largest advance width of the font's characters and the line
+	// height.
+	CellSize image.Point
+	// Align specifies how glyphs are positioned within their cells.
+	Align CellAlignment
+}
+
+// A Cell is a character cell of a text grid.
+type Cell struct {
+	// Rune is the character of the cell. The zero rune leaves the cell
+	// empty.
+	Rune rune
+	// Foreground is the color the glyph is tinted with. If it is nil the
+	// glyph is drawn with the colors of the page sheet.
+	Foreground color.Color
+	// Background is the color the cell is filled with. If it is nil the
+	// cell has no background.
+	Background color.Color
+}
+
+// CellAlignment specifies how glyphs are positioned within the cells of a
+// text grid.
+type CellAlignment int
+
+const (
+	// CellBaseline centers the advance width of the glyph horizontally in
+	// the cell and places it on the base line of the font, as if the cell
+	// was a line of text.
+	CellBaseline CellAlignment = iota
+	// CellCenter centers the bitmap of the glyph in the cell, both
+	// horizontally and vertically.
+	CellCenter
+)
+
+// NewTextGrid creates a new text grid with the given number of columns and
+// rows of empty cells, drawn with the given font.
+func NewTextGrid(font *BitmapFont, cols, rows int) *TextGrid {
+	cellWidth := 0
+	for _, ch := range font.Descriptor.Chars {
+		cellWidth = max(cellWidth, ch.XAdvance)
+	}
+	return &TextGrid{
+		font:     font,
+		cols:     cols,
+		rows:     rows,
+		cells:    make([]Cell, cols*rows),
+		CellSize: image.Pt(cellWidth, font.Descriptor.Common.LineHeight),
+	}
+}
+
+// Size returns the number of columns and rows of the grid.
+func (g *TextGrid) Size() (cols, rows int) {
+	return g.cols, g.rows
+}
+
+// Cell returns the cell at the given column and row. Positions outside the
+// grid return an empty cell.
+func (g *TextGrid) Cell(col, row int) Cell {
+	if !g.inside(col, row) {
+		return Cell{}
+	}
+	return g.cells[row*g.cols+col]
+}
+
+// SetCell sets the cell at the given column and row. Positions outside the
+// grid are ignored.
+func (g *TextGrid) SetCell(col, row int, c Cell) {
+	if !g.inside(col, row) {
+		return
+	}
+	g.cells[row*g.cols+col] = c
+}
+
+// WriteString sets the runes of the string in consecutive cells of a row,
+// starting at the given column, with the given colors. Runes beyond the
+// last column are cut off.
+func (g *TextGrid) WriteString(col, row int, s string, fg, bg color.Color) {
+	for _, r := range s {
+		g.SetCell(col, row, Cell{Rune: r, Foreground: fg, Background: bg})
+		col++
+	}
+}
+
+// Fill sets all cells of the grid to the given cell.
+func (g *TextGrid) Fill(c Cell) {
+	for i := range g.cells {
+		g.cells[i] = c
+	}
+}
+
+// Clear empties all cells of the grid.
+func (g *TextGrid) Clear() {
+	g.Fill(Cell{})
+}
+
+// Bounds returns the rectangle covered by the grid if it is drawn at the
+// given position.
+func (g *TextGrid) Bounds(pos image.Point) image.Rectangle {
+	return image.Rectangle{
+		Min: pos,
+		Max: pos.Add(image.Pt(g.cols*g.CellSize.X, g.rows*g.CellSize.Y)),
+	}
+}
+
+// Draw draws the grid on the destination image with its top left corner at
+// the given position.
+func (g *TextGrid) Draw(dst draw.Image, pos image.Point) {
+	common := g.font.Descriptor.Common
+	for row := 0; row < g.rows; row++ {
+		for col := 0; col < g.cols; col++ {
+			c := g.cells[row*g.cols+col]
+			cell := image.Rectangle{
+				Min: pos.Add(image.Pt(col*g.CellSize.X, row*g.CellSize.Y)),
+			}
+			cell.Max = cell.Min.Add(g.CellSize)
+			if c.Background != nil {
+				draw.Draw(dst, cell, image.NewUniform(c.Background), image.Point{}, draw.Over)
+			}
+			if c.Rune == 0 {
+				continue
+			}
+			ch, found, ok := g.font.char(c.Rune)
+			if !found {
+				g.font.stats.countMissing(c.Rune, ok)
+			}
+			if !ok {
+				continue
+			}
+			var min image.Point
+			switch g.Align {
+			case CellCenter:
+				min = cell.Min.Add(g.CellSize.Sub(ch.Size()).Div(2))
+			default:
+				min = image.Pt(
+					cell.Min.X+(g.CellSize.X-ch.XAdvance)/2+ch.XOffset,
+					cell.Min.Y+(g.CellSize.Y-common.LineHeight)/2+ch.YOffset,
+				)
+			}
+			sheet, _ := g.font.Page(ch.Page)
+			if sheet == nil {
+				continue
+			}
+			d := imageDrawer{dst: dst, color: c.Foreground}
+			d.Draw(image.Rectangle{Min: min, Max: min.Add(ch.Size())}, func() image.Image { return sheet }, ch.Bounds())
+			g.font.stats.glyphsDrawn.Add(1)
+		}
+	}
+}
+
+func (g *TextGrid) inside(col, row int) bool {
+	return col >= 0 && col < g.cols && row >= 0 && row < g.rows
+}
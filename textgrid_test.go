@@ -0,0 +1,53 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTextGridCells(t *testing.T) {
+	g := NewTextGrid(testFont("AB", 1), 3, 2)
+	if g.CellSize != image.Pt(5, 8) {
+		t.Errorf("got cell size %v, want the largest advance and the line height (5,8)", g.CellSize)
+	}
+	g.WriteString(1, 0, "ABAB", nil, nil)
+	if got := g.Cell(1, 0).Rune; got != 'A' {
+		t.Errorf("got rune %q in cell (1,0), want 'A'", got)
+	}
+	if got := g.Cell(2, 0).Rune; got != 'B' {
+		t.Errorf("got rune %q in cell (2,0), want 'B'", got)
+	}
+	if got := g.Cell(0, 1).Rune; got != 0 {
+		t.Errorf("runes beyond the last column were not cut off, got %q in cell (0,1)", got)
+	}
+	if got := g.Cell(5, 5); got != (Cell{}) {
+		t.Errorf("got %+v outside of the grid, want an empty cell", got)
+	}
+	if got, want := g.Bounds(image.Pt(1, 2)), image.Rect(1, 2, 16, 18); got != want {
+		t.Errorf("got bounds %v, want %v", got, want)
+	}
+}
+
+func TestTextGridDraw(t *testing.T) {
+	g := NewTextGrid(opaqueFont("A"), 2, 1)
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	blue := color.RGBA{B: 0xFF, A: 0xFF}
+	g.SetCell(1, 0, Cell{Rune: 'A', Foreground: red, Background: blue})
+	dst := image.NewRGBA(g.Bounds(image.Point{}))
+	g.Draw(dst, image.Point{})
+	if got := dst.RGBAAt(1, 1); got != (color.RGBA{}) {
+		t.Errorf("got %v in the empty cell, want transparent", got)
+	}
+	// The 4 pixel wide glyph is centered in the 5 pixel wide cell.
+	if got := dst.RGBAAt(5, 7); got != blue {
+		t.Errorf("got %v below the glyph, want the background %v", got, blue)
+	}
+	if got := dst.RGBAAt(6, 1); got != red {
+		t.Errorf("got %v in the glyph, want the foreground %v", got, red)
+	}
+}
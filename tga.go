@@ -0,0 +1,162 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// tgaHeader is the header of a TGA (Truevision TARGA) image file, the
+// default page sheet format of BMFont.
+type tgaHeader struct {
+	IDLength      uint8
+	ColorMapType  uint8
+	ImageType     uint8
+	ColorMapFirst uint16
+	ColorMapLen   uint16
+	ColorMapDepth uint8
+	XOrigin       uint16
+	YOrigin       uint16
+	Width         uint16
+	Height        uint16
+	PixelDepth    uint8
+	Descriptor    uint8
+}
+
+// TGA image types.
+const (
+	tgaTrueColor    = 2
+	tgaGrayscale    = 3
+	tgaRLETrueColor = 10
+	tgaRLEGrayscale = 11
+)
+
+func readTGAHeader(r io.Reader) (*tgaHeader, error) {
+	var h tgaHeader
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return nil, fmt.Errorf("bmfont: invalid TGA header: %w", err)
+	}
+	if h.ColorMapType != 0 {
+		return nil, errors.New("bmfont: unsupported TGA image with color map")
+	}
+	switch {
+	case (h.ImageType == tgaTrueColor || h.ImageType == tgaRLETrueColor) && (h.PixelDepth == 24 || h.PixelDepth == 32):
+	case (h.ImageType == tgaGrayscale || h.ImageType == tgaRLEGrayscale) && h.PixelDepth == 8:
+	default:
+		return nil, fmt.Errorf("bmfont: unsupported TGA image type %d with %d bits per pixel", h.ImageType, h.PixelDepth)
+	}
+	return &h, nil
+}
+
+// decodeTGAConfig returns the color model and dimensions of a TGA image
+// without decoding the pixels.
+func decodeTGAConfig(r io.Reader) (image.Config, error) {
+	h, err := readTGAHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	model := color.NRGBAModel
+	if h.PixelDepth == 8 {
+		model = color.GrayModel
+	}
+	return image.Config{ColorModel: model, Width: int(h.Width), Height: int(h.Height)}, nil
+}
+
+// decodeTGA decodes an uncompressed or run-length encoded true color or
+// grayscale TGA image, as written by BMFont. True color images are
+// returned as *image.NRGBA and grayscale images as *image.Gray.
+func decodeTGA(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	h, err := readTGAHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := br.Discard(int(h.IDLength)); err != nil {
+		return nil, fmt.Errorf("bmfont: invalid TGA image: %w", err)
+	}
+	w, ht := int(h.Width), int(h.Height)
+	bpp := int(h.PixelDepth) / 8
+	data := make([]byte, w*ht*bpp)
+	if h.ImageType == tgaRLETrueColor || h.ImageType == tgaRLEGrayscale {
+		err = readTGARLE(br, data, bpp)
+	} else {
+		_, err = io.ReadFull(br, data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bmfont: invalid TGA image data: %w", err)
+	}
+
+	rect := image.Rect(0, 0, w, ht)
+	var img interface {
+		image.Image
+		PixOffset(x, y int) int
+	}
+	var pix []byte
+	if bpp == 1 {
+		gray := image.NewGray(rect)
+		img, pix = gray, gray.Pix
+	} else {
+		nrgba := image.NewNRGBA(rect)
+		img, pix = nrgba, nrgba.Pix
+	}
+	topToBottom := h.Descriptor&0x20 != 0
+	rightToLeft := h.Descriptor&0x10 != 0
+	for i := 0; i < w*ht; i++ {
+		x, y := i%w, i/w
+		if !topToBottom {
+			y = ht - 1 - y
+		}
+		if rightToLeft {
+			x = w - 1 - x
+		}
+		src := data[i*bpp : (i+1)*bpp]
+		o := img.PixOffset(x, y)
+		if bpp == 1 {
+			pix[o] = src[0]
+			continue
+		}
+		// The pixels are stored in BGR(A) order.
+		pix[o], pix[o+1], pix[o+2], pix[o+3] = src[2], src[1], src[0], 0xff
+		if bpp == 4 {
+			pix[o+3] = src[3]
+		}
+	}
+	return img, nil
+}
+
+// readTGARLE decompresses run-length encoded TGA pixel data into data.
+func readTGARLE(r *bufio.Reader, data []byte, bpp int) error {
+	pixel := make([]byte, bpp)
+	for i := 0; i < len(data); {
+		packet, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		n := (int(packet&0x7f) + 1) * bpp
+		if i+n > len(data) {
+			return errors.New("run exceeds the image")
+		}
+		if packet&0x80 == 0 {
+			if _, err := io.ReadFull(r, data[i:i+n]); err != nil {
+				return err
+			}
+		} else {
+			if _, err := io.ReadFull(r, pixel); err != nil {
+				return err
+			}
+			for j := i; j < i+n; j += bpp {
+				copy(data[j:], pixel)
+			}
+		}
+		i += n
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+)
+
+// tgaFile returns a 2×2 TGA image file with the given header fields and
+// pixel data.
+func tgaFile(t *testing.T, imageType, depth, descriptor uint8, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	h := tgaHeader{IDLength: 3, ImageType: imageType, Width: 2, Height: 2, PixelDepth: depth, Descriptor: descriptor}
+	if err := binary.Write(&buf, binary.LittleEndian, h); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("id!")
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestDecodeTGA(t *testing.T) {
+	red, green := color.NRGBA{R: 0xff, A: 0xff}, color.NRGBA{G: 0xff, A: 0x80}
+	tests := []struct {
+		name string
+		file []byte
+		want [4]color.Color // the pixels in reading order
+	}{
+		{
+			"32 bit bottom to top",
+			tgaFile(t, tgaTrueColor, 32, 8, []byte{
+				0, 0xff, 0, 0x80, 0, 0, 0xff, 0xff, // bottom row: green, red
+				0, 0, 0xff, 0xff, 0, 0, 0xff, 0xff, // top row: red, red
+			}),
+			[4]color.Color{red, red, green, red},
+		},
+		{
+			"24 bit top to bottom",
+			tgaFile(t, tgaTrueColor, 24, 0x20, []byte{
+				0, 0, 0xff, 0xff, 0xff, 0xff,
+				0, 0, 0, 0, 0, 0xff,
+			}),
+			[4]color.Color{red, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, color.NRGBA{A: 0xff}, red},
+		},
+		{
+			"RLE 32 bit right to left",
+			tgaFile(t, tgaRLETrueColor, 32, 0x30, []byte{
+				0x81, 0, 0, 0xff, 0xff, // run of 2 red pixels
+				0x01, 0, 0xff, 0, 0x80, 0, 0, 0xff, 0xff, // raw green, red
+			}),
+			[4]color.Color{red, red, red, green},
+		},
+		{
+			"RLE grayscale",
+			tgaFile(t, tgaRLEGrayscale, 8, 0x20, []byte{0x82, 0x40, 0x00, 0x90}),
+			[4]color.Color{color.Gray{Y: 0x40}, color.Gray{Y: 0x40}, color.Gray{Y: 0x40}, color.Gray{Y: 0x90}},
+		},
+	}
+	for _, tt := range tests {
+		img, err := decodeTGA(bytes.NewReader(tt.file))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for i, want := range tt.want {
+			if got := img.At(i%2, i/2); got != want {
+				t.Errorf("%s: got pixel %d,%d %v, want %v", tt.name, i%2, i/2, got, want)
+			}
+		}
+		config, err := decodeTGAConfig(bytes.NewReader(tt.file))
+		if err != nil || config.Width != 2 || config.Height != 2 || config.ColorModel != img.ColorModel() {
+			t.Errorf("%s: got config %+v, error %v", tt.name, config, err)
+		}
+	}
+}
+
+func TestDecodeTGAErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{"truncated header", []byte{0, 0, 2}},
+		{"color mapped", tgaFile(t, 1, 8, 0, make([]byte, 4))},
+		{"16 bit", tgaFile(t, tgaTrueColor, 16, 0, make([]byte, 8))},
+		{"truncated data", tgaFile(t, tgaTrueColor, 24, 0, make([]byte, 11))},
+		{"run exceeds image", tgaFile(t, tgaRLEGrayscale, 8, 0, []byte{0x84, 0})},
+	}
+	for _, tt := range tests {
+		if _, err := decodeTGA(bytes.NewReader(tt.file)); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
+
+func TestReadTGASheet(t *testing.T) {
+	descriptor := strings.Replace(testDescriptor, `file="sheet.png"`, `file="sheet.TGA"`, 1)
+	file := tgaFile(t, tgaGrayscale, 8, 0, make([]byte, 4))
+	f, err := Read(strings.NewReader(descriptor), func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(file)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.PageSheets[0].(*image.Gray); !ok {
+		t.Errorf("got page sheet %T, want *image.Gray", f.PageSheets[0])
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tolerantReader normalizes the lines of a descriptor in text format with
+// the quirks accepted by ParseOptions.Tolerant before they are parsed.
+type tolerantReader struct {
+	r   *bufio.Reader
+	buf []byte
+	err error
+}
+
+func newTolerantReader(r io.Reader) *tolerantReader {
+	return &tolerantReader{r: bufio.NewReader(r)}
+}
+
+func (t *tolerantReader) Read(p []byte) (int, error) {
+	for len(t.buf) == 0 {
+		if t.err != nil {
+			return 0, t.err
+		}
+		var line string
+		line, t.err = t.r.ReadString('\n')
+		if line != "" {
+			t.buf = append([]byte(normalizeLine(line)), '\n')
+		}
+	}
+	n := copy(p, t.buf)
+	t.buf = t.buf[n:]
+	return n, nil
+}
+
+var (
+	// attrStart matches the start of the next attribute after a value.
+	attrStart = regexp.MustCompile(`\s+[A-Za-z_][A-Za-z0-9_]*\s*=`)
+	// emptyValue matches the start of a line remainder if the value of the
+	// previous attribute is missing.
+	emptyValue = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*=`)
+	// intList matches an integer or a list of integers, with optional
+	// spaces after the commas.
+	intList = regexp.MustCompile(`^-?[0-9]+(\s*,\s*-?[0-9]+)*$`)
+)
+
+// normalizeLine rewrites a line of a descriptor in text format, so that
+// unquoted string values are quoted, empty values are given as "", and the
+// white space around the equal signs and within integer lists is removed.
+// Quoted values are kept as they are.
+func normalizeLine(line string) string {
+	line = strings.TrimSpace(line)
+	i := strings.IndexFunc(line, unicode.IsSpace)
+	if i < 0 {
+		return line
+	}
+	var sb strings.Builder
+	sb.WriteString(line[:i])
+	for rest := strings.TrimSpace(line[i:]); rest != ""; rest = strings.TrimSpace(rest) {
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			// Not an attribute, left for the parser to report.
+			sb.WriteString(" " + rest)
+			break
+		}
+		name := strings.TrimSpace(rest[:eq])
+		rest = strings.TrimLeftFunc(rest[eq+1:], unicode.IsSpace)
+		var value string
+		switch {
+		case strings.HasPrefix(rest, `"`):
+			end := strings.IndexByte(rest[1:], '"') + 2
+			if end == 1 {
+				end = len(rest)
+			} else if strings.HasPrefix(rest[end:], `"`) {
+				// letter="""
+				end++
+			}
+			value, rest = rest[:end], rest[end:]
+		case emptyValue.MatchString(rest):
+			value = `""`
+		default:
+			end := len(rest)
+			if loc := attrStart.FindStringIndex(rest); loc != nil {
+				end = loc[0]
+			}
+			value, rest = strings.TrimSpace(rest[:end]), rest[end:]
+			if intList.MatchString(value) {
+				value = strings.Join(strings.Fields(value), "")
+			} else {
+				value = quote(value)
+			}
+		}
+		sb.WriteString(" " + name + "=" + value)
+	}
+	return sb.String()
+}
+
+// applyTolerantDefaults sets the missing required attributes of the tags,
+// except for the identifying ones, to the defaults documented for
+// ParseOptions.Tolerant.
+func applyTolerantDefaults(tags []tag) {
+	size := 0
+	for i := range tags {
+		t := &tags[i]
+		set := func(name, value string) {
+			if _, ok := t.attrs[name]; !ok {
+				t.attrs[name] = value
+			}
+		}
+		switch t.name {
+		case "info":
+			size = max(t.intAttr("size"), -t.intAttr("size"))
+		case "common":
+			set("lineHeight", strconv.Itoa(size))
+			set("base", t.stringAttr("lineHeight"))
+		case "char":
+			for _, name := range []string{"x", "y", "width", "height"} {
+				set(name, "0")
+			}
+			set("xadvance", t.stringAttr("width"))
+		case "kerning", "classkerning":
+			set("amount", "0")
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLine(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{`info face=Open Sans size=32 bold=0`, `info face="Open Sans" size=32 bold=0`},
+		{`info face="Open Sans"  size = 32`, `info face="Open Sans" size=32`},
+		{`info unicode= stretchH=100`, `info unicode="" stretchH=100`},
+		{`info padding = 1, 2, 3, 4 spacing=1,1`, `info padding=1,2,3,4 spacing=1,1`},
+		{`char id=34 letter="""`, `char id=34 letter="""`},
+		{`  chars count=2  `, `chars count=2`},
+		{`common`, `common`},
+	}
+	for _, tt := range tests {
+		if got := normalizeLine(tt.line); got != tt.want {
+			t.Errorf("normalizeLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTolerant(t *testing.T) {
+	// A descriptor in the style of Hiero and fontbm.
+	input := `info face=Open Sans size=-8 bold=0 italic=0 charset= unicode=1 padding = 0, 0, 0, 0 spacing=1,1
+common scaleW=16 scaleH=8 pages=1 packed=0
+page id=0 file="sheet.png"
+chars count=2
+char id=65 x=0 y=0 width=4 height=6 xoffset=0 yoffset=1 page=0 chnl=15
+char id=66 x=4 y=0 width=4 height=6 xoffset=0 yoffset=1 xadvance=5 page=0 chnl=15
+kernings count=1
+kerning first=65 second=66
+`
+	if _, err := ReadDescriptor(strings.NewReader(input)); err == nil {
+		t.Error("quirky descriptor was accepted without the tolerant mode")
+	}
+	opts := &ParseOptions{Tolerant: true}
+	d, err := opts.ReadDescriptor(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Info.Face != "Open Sans" || d.Info.Charset != "" {
+		t.Errorf("got info %+v", d.Info)
+	}
+	if d.Common.LineHeight != 8 || d.Common.Base != 8 {
+		t.Errorf("got line height %d and base %d, want 8 and 8", d.Common.LineHeight, d.Common.Base)
+	}
+	if got := d.Chars['A'].XAdvance; got != 4 {
+		t.Errorf("got advance %d for A, want its width 4", got)
+	}
+	if k, ok := d.Kerning[CharPair{First: 'A', Second: 'B'}]; !ok || k.Amount != 0 {
+		t.Errorf("got kerning %+v, ok %v, want amount 0", k, ok)
+	}
+}
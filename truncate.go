@@ -0,0 +1,150 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"golang.org/x/image/math/fixed"
+)
+
+func (s *Style) truncate() bool {
+	return s != nil && s.Truncate
+}
+
+// ellipsis returns the glyphs of the ellipsis of the style, shaped with the
+// font and scaled with the given factor. The default ellipsis is "…", or
+// "..." if the font doesn't include U+2026.
+func (f *BitmapFont) ellipsis(style *Style, s float64) []glyph {
+	text := "…"
+	if style != nil && style.Ellipsis != "" {
+		text = style.Ellipsis
+	} else if _, ok := f.Descriptor.Chars['…']; !ok {
+		text = "..."
+	}
+	return f.shape(f, text, 0, len(text), style, s, 0, nil)
+}
+
+// breakParagraph breaks the glyphs of a paragraph between the byte offsets
+// start and end of the text into lines that fit into the maximum width:
+// they are wrapped, or, if the style truncates lines, the paragraph is
+// truncated with an ellipsis shaped with the font, scaled with the factor s.
+func (f *BitmapFont) breakParagraph(glyphs []glyph, start, end int, maxWidth fixed.Int26_6, style *Style, s float64) []line {
+	var lines []line
+	if style.truncate() && maxWidth > 0 {
+		ln := newLine(glyphs, start, end)
+		if ln.width > maxWidth {
+			ln.ellipsize(maxWidth, f.ellipsis(style, s))
+		}
+		lines = []line{ln}
+	} else {
+		lines = wrapLine(glyphs, start, end, maxWidth)
+	}
+	lines[len(lines)-1].last = true
+	return lines
+}
+
+// ellipsize removes glyphs from the end of the line, and spaces before them,
+// until the ellipsis fits behind them into the maximum width, and appends
+// the ellipsis, in the color of the last remaining glyph. The text of the
+// line ends where it is truncated. If the ellipsis alone is wider than the
+// maximum width, only as many of its glyphs as fit are appended.
+func (ln *line) ellipsize(maxWidth fixed.Int26_6, ellipsis []glyph) {
+	var width fixed.Int26_6
+	if n := len(ellipsis); n > 0 {
+		width = ellipsis[n-1].dot.X + ellipsis[n-1].advance
+	}
+	n := len(ln.glyphs)
+	for n > 0 && (ln.glyphs[n-1].dot.X+ln.glyphs[n-1].advance+width > maxWidth || isSpace(ln.glyphs[n-1].r)) {
+		n--
+	}
+	if n < len(ln.glyphs) {
+		ln.end = ln.glyphs[n].offset
+	}
+	ln.glyphs = ln.glyphs[:n]
+	var x fixed.Int26_6
+	if n > 0 {
+		x = ln.glyphs[n-1].dot.X + ln.glyphs[n-1].advance
+	}
+	for _, g := range ellipsis {
+		if x+g.dot.X+g.advance > maxWidth {
+			break
+		}
+		if n > 0 {
+			g.color = ln.glyphs[n-1].color
+		}
+		g.dot = fixed.Point26_6{X: x + g.dot.X, Y: ln.y}
+		g.offset = ln.end
+		ln.glyphs = append(ln.glyphs, g)
+	}
+	ln.width = 0
+	if k := len(ln.glyphs); k > 0 {
+		ln.width = ln.glyphs[k-1].dot.X + ln.glyphs[k-1].advance
+	}
+}
+
+// Overflow is the policy for text that has more lines than the MaxLines of
+// a style.
+type Overflow int
+
+const (
+	// OverflowClip omits the lines after the maximum number of lines.
+	OverflowClip Overflow = iota
+	// OverflowEllipsis omits the lines after the maximum number of lines
+	// and ends the last line with the Ellipsis of the style, truncating the
+	// line as needed to fit it into MaxWidth.
+	OverflowEllipsis
+)
+
+func (s *Style) maxLines() int {
+	if s == nil {
+		return 0
+	}
+	return s.MaxLines
+}
+
+// limitLines removes the lines after the maximum number of lines of the style
+// from the layout, according to its overflow policy.
+func (l *layout) limitLines(style *Style, maxWidth fixed.Int26_6) {
+	n := style.maxLines()
+	if n <= 0 || len(l.lines) <= n {
+		return
+	}
+	l.lines = l.lines[:n]
+	if style.Overflow != OverflowEllipsis {
+		return
+	}
+	ln := &l.lines[n-1]
+	ellipsis := l.font.ellipsis(style, l.scale)
+	if maxWidth <= 0 {
+		maxWidth = ln.width
+		if k := len(ellipsis); k > 0 {
+			maxWidth += ellipsis[k-1].dot.X + ellipsis[k-1].advance
+		}
+	}
+	ln.ellipsize(maxWidth, ellipsis)
+	ln.last = true
+}
+
+// FitLines determines how much of the text with the given style fits into
+// the MaxLines of the style, without drawing it. It returns the text that
+// fits and the remaining text, which is empty if the text fits completely,
+// e.g. for continuing it on another page of a fixed height label. If the
+// style transforms the case of the text, both are parts of the transformed
+// text.
+func (f *BitmapFont) FitLines(text string, style *Style) (fit, overflow string) {
+	text = style.transform(text)
+	n := style.maxLines()
+	if n <= 0 {
+		return text, ""
+	}
+	s := *style
+	s.Case = KeepCase
+	s.MaxLines = 0
+	l := f.layout(text, &s, nil)
+	if len(l.lines) <= n {
+		return text, ""
+	}
+	start := l.lines[n].start
+	return text[:start], text[start:]
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		chars, text string
+		style       *Style
+		want        string
+	}{
+		{"AB.", "ABAB", &Style{Truncate: true, MaxWidth: 20}, "ABAB"},
+		{"AB.", "ABABAB", &Style{Truncate: true, MaxWidth: 20}, "A..."},
+		{"AB.…", "ABABAB", &Style{Truncate: true, MaxWidth: 20}, "ABA…"},
+		{"AB.", "AAAAAA", &Style{Truncate: true, MaxWidth: 17, Ellipsis: "B"}, "AAB"},
+		// Spaces before the ellipsis are removed.
+		{"AB ", "AB ABAB", &Style{Truncate: true, MaxWidth: 17, Ellipsis: "B"}, "ABB"},
+		// Only the part of the ellipsis that fits is appended.
+		{"AB.", "ABABAB", &Style{Truncate: true, MaxWidth: 12}, ".."},
+		// Each paragraph is truncated separately.
+		{"AB.", "ABABAB\nAB", &Style{Truncate: true, MaxWidth: 20}, "A...\nAB"},
+	}
+	for _, tt := range tests {
+		l := testFont(tt.chars, 1).layout(tt.text, tt.style, nil)
+		var got string
+		for i, ln := range layoutRunes(l) {
+			if i > 0 {
+				got += "\n"
+			}
+			got += ln
+		}
+		if got != tt.want {
+			t.Errorf("%q truncated at %d: got %q, want %q", tt.text, tt.style.MaxWidth, got, tt.want)
+		}
+		for _, ln := range l.lines {
+			if ln.width > fixed.I(tt.style.MaxWidth) {
+				t.Errorf("%q truncated at %d: got line width %v", tt.text, tt.style.MaxWidth, ln.width)
+			}
+		}
+	}
+}
+
+func TestTruncateLineText(t *testing.T) {
+	f := testFont("AB .", 1)
+	lines := f.MeasureLines("AB ABAB", &Style{Truncate: true, MaxWidth: 25})
+	if len(lines) != 1 || lines[0].Text != "AB" || lines[0].Width != 25 {
+		t.Errorf("got lines %+v, want the text before the ellipsis", lines)
+	}
+	if got, want := layoutRunes(f.layout("AB ABAB", &Style{Truncate: true, MaxWidth: 25}, nil)), []string{"AB..."}; !slices.Equal(got, want) {
+		t.Errorf("got glyphs %q, want %q", got, want)
+	}
+}
+
+func TestMaxLines(t *testing.T) {
+	f := testFont("AB .", 1)
+	tests := []struct {
+		style *Style
+		want  []string
+	}{
+		{&Style{MaxWidth: 12}, []string{"AB", "AB", "AB"}},
+		{&Style{MaxWidth: 12, MaxLines: 2}, []string{"AB", "AB"}},
+		{&Style{MaxWidth: 12, MaxLines: 2, Overflow: OverflowEllipsis, Ellipsis: "."}, []string{"AB", "A."}},
+		// Without a maximum width the ellipsis is appended.
+		{&Style{MaxLines: 1, Overflow: OverflowEllipsis}, []string{"AB..."}},
+		{&Style{MaxLines: 3, Overflow: OverflowEllipsis}, []string{"AB", "AB", "AB"}},
+	}
+	for _, tt := range tests {
+		text := "AB AB AB"
+		if tt.style.MaxWidth == 0 {
+			text = "AB\nAB\nAB"
+		}
+		if got := layoutRunes(f.layout(text, tt.style, nil)); !slices.Equal(got, tt.want) {
+			t.Errorf("%+v: got lines %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestFitLines(t *testing.T) {
+	f := testFont("ABab ", 1)
+	tests := []struct {
+		style         *Style
+		fit, overflow string
+	}{
+		{&Style{MaxWidth: 12}, "AB AB AB", ""},
+		{&Style{MaxWidth: 12, MaxLines: 3}, "AB AB AB", ""},
+		{&Style{MaxWidth: 12, MaxLines: 2}, "AB AB ", "AB"},
+		{&Style{MaxWidth: 12, MaxLines: 1, Case: LowerCase}, "ab ", "ab ab"},
+	}
+	for _, tt := range tests {
+		fit, overflow := f.FitLines("AB AB AB", tt.style)
+		if fit != tt.fit || overflow != tt.overflow {
+			t.Errorf("%+v: got %q and %q, want %q and %q", tt.style, fit, overflow, tt.fit, tt.overflow)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"fmt"
+	"image"
+)
+
+// A Finding is a problem of a font descriptor reported by Validate.
+type Finding struct {
+	Kind FindingKind
+	// Char is the concerned character for findings about chars.
+	Char rune
+	// Pair is the concerned character pair for findings about kerning
+	// pairs.
+	Pair CharPair
+	// Page is the concerned page ID for findings about pages and about
+	// chars referencing pages.
+	Page int
+	// Message describes the problem, e.g.
+	// "char U+0041 'A': references missing page 2".
+	Message string
+}
+
+func (f Finding) String() string {
+	return f.Message
+}
+
+// FindingKind is the kind of problem of a Finding.
+type FindingKind int
+
+const (
+	// FindingLineHeight: the line height is not positive.
+	FindingLineHeight FindingKind = iota + 1
+	// FindingBase: the base is outside of the line height.
+	FindingBase
+	// FindingNoPages: the font has no pages.
+	FindingNoPages
+	// FindingMissingPage: a char references a page that does not exist.
+	FindingMissingPage
+	// FindingNegativeSize: a char has a negative width or height.
+	FindingNegativeSize
+	// FindingOutsidePage: the glyph rectangle of a char exceeds the page
+	// size declared by the common tag.
+	FindingOutsidePage
+	// FindingDuplicateID: a page, char or kerning pair was defined more
+	// than once in the parsed descriptor. Only the last definition is kept.
+	FindingDuplicateID
+	// FindingMissingChar: a kerning pair references a char that does not
+	// exist.
+	FindingMissingChar
+	// FindingLetterMismatch: the letter of a char does not match its ID.
+	FindingLetterMismatch
+)
+
+var findingKindNames = []string{"", "lineheight", "base", "nopages", "missingpage", "negativesize", "outsidepage", "duplicateid", "missingchar", "lettermismatch"}
+
+func (k FindingKind) String() string {
+	if k <= 0 || int(k) >= len(findingKindNames) {
+		return fmt.Sprintf("FindingKind(%d)", int(k))
+	}
+	return findingKindNames[k]
+}
+
+// Validate checks the descriptor for inconsistencies and returns the
+// problems found, or nil if there are none. The findings about chars and
+// kerning pairs are sorted by their IDs. The page sheet images are not
+// checked, so the glyph rectangles are checked against the declared page
+// size.
+func (d *Descriptor) Validate() []Finding {
+	var findings []Finding
+	report := func(f Finding, format string, args ...any) {
+		f.Message = fmt.Sprintf(format, args...)
+		findings = append(findings, f)
+	}
+	common := d.Common
+	if common.LineHeight <= 0 {
+		report(Finding{Kind: FindingLineHeight}, "line height %d is not positive", common.LineHeight)
+	}
+	if common.Base < 0 || common.Base > common.LineHeight {
+		report(Finding{Kind: FindingBase}, "base %d is outside of the line height %d", common.Base, common.LineHeight)
+	}
+	if len(d.Pages) == 0 {
+		report(Finding{Kind: FindingNoPages}, "font has no pages")
+	}
+	findings = append(findings, d.duplicates...)
+
+	size := common.Scale()
+	for _, r := range sortedKeys(d.Chars) {
+		ch := d.Chars[r]
+		f := Finding{Char: r, Page: ch.Page}
+		if l, ok := letterRune(ch.Letter); ok && l != r {
+			report(Finding{Kind: FindingLetterMismatch, Char: r},
+				"char %s: letter %q does not match the ID", runeName(r), ch.Letter)
+		}
+		if _, ok := d.Pages[ch.Page]; !ok {
+			f.Kind = FindingMissingPage
+			report(f, "char %s: references missing page %d", runeName(r), ch.Page)
+			continue
+		}
+		if ch.Width < 0 || ch.Height < 0 {
+			f.Kind = FindingNegativeSize
+			report(f, "char %s: negative size %dx%d", runeName(r), ch.Width, ch.Height)
+			continue
+		}
+		if !ch.Bounds().In(image.Rectangle{Max: size}) && ch.Width > 0 && ch.Height > 0 {
+			b := ch.Bounds()
+			f.Kind = FindingOutsidePage
+			report(f, "char %s: glyph rectangle (%d,%d)-(%d,%d) exceeds page %d of size %dx%d",
+				runeName(r), b.Min.X, b.Min.Y, b.Max.X, b.Max.Y, ch.Page, size.X, size.Y)
+		}
+	}
+
+	for _, p := range d.kerningPairs() {
+		for _, r := range []rune{p.First, p.Second} {
+			if _, ok := d.Chars[r]; !ok {
+				report(Finding{Kind: FindingMissingChar, Char: r, Pair: p},
+					"kerning %s %s: references missing char %s", runeName(p.First), runeName(p.Second), runeName(r))
+			}
+		}
+	}
+	return findings
+}
+
+// addDuplicate records a page, char or kerning pair that was defined more
+// than once while parsing, for Validate.
+func (d *Descriptor) addDuplicate(f Finding, format string, args ...any) {
+	f.Kind = FindingDuplicateID
+	f.Message = fmt.Sprintf(format, args...)
+	d.duplicates = append(d.duplicates, f)
+}
+
+// runeName returns the code point of r followed by the quoted character if
+// it is printable, e.g. "U+0041 'A'".
+func runeName(r rune) string {
+	if r > ' ' && r != 0x7f {
+		return fmt.Sprintf("U+%04X %q", r, r)
+	}
+	return fmt.Sprintf("U+%04X", r)
+}
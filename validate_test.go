@@ -0,0 +1,93 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	d, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings := d.Validate(); findings != nil {
+		t.Errorf("got findings %v for a valid descriptor, want none", findings)
+	}
+
+	input := `info face="Broken" size=8
+common lineHeight=8 base=9 scaleW=16 scaleH=8 pages=1
+page id=0 file="sheet.png"
+char id=65 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0
+char id=65 x=4 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0
+char id=66 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=3
+char id=67 x=0 y=0 width=-1 height=6 xoffset=0 yoffset=0 xadvance=5 page=0
+char id=68 x=14 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0
+char id=69 x=0 y=0 width=4 height=6 xoffset=0 yoffset=0 xadvance=5 page=0 letter="F"
+kerning first=65 second=90 amount=-1
+`
+	if d, err = ReadDescriptor(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	findings := d.Validate()
+	var kinds []FindingKind
+	for _, f := range findings {
+		kinds = append(kinds, f.Kind)
+	}
+	wantKinds := []FindingKind{
+		FindingBase,
+		FindingDuplicateID,
+		FindingMissingPage,
+		FindingNegativeSize,
+		FindingOutsidePage,
+		FindingLetterMismatch,
+		FindingMissingChar,
+	}
+	if !slices.Equal(kinds, wantKinds) {
+		t.Fatalf("got finding kinds %v, want %v\n%v", kinds, wantKinds, findings)
+	}
+	wantMessages := []string{
+		"base 9 is outside of the line height 8",
+		"",
+		"char U+0042 'B': references missing page 3",
+		"char U+0043 'C': negative size -1x6",
+		"char U+0044 'D': glyph rectangle (14,0)-(18,6) exceeds page 0 of size 16x8",
+		`char U+0045 'E': letter "F" does not match the ID`,
+		"kerning U+0041 'A' U+005A 'Z': references missing char U+005A 'Z'",
+	}
+	for i, f := range findings {
+		if wantMessages[i] != "" && f.Message != wantMessages[i] {
+			t.Errorf("finding %d: got message %q, want %q", i, f.Message, wantMessages[i])
+		}
+	}
+	if f := findings[1]; f.Char != 'A' {
+		t.Errorf("got duplicate char %q, want 'A'", f.Char)
+	}
+	if f := findings[6]; f.Char != 'Z' || f.Pair != (CharPair{First: 'A', Second: 'Z'}) {
+		t.Errorf("got missing char %q of pair %v, want 'Z' of A Z", f.Char, f.Pair)
+	}
+}
+
+func TestValidateEmpty(t *testing.T) {
+	var d Descriptor
+	var kinds []FindingKind
+	for _, f := range d.Validate() {
+		kinds = append(kinds, f.Kind)
+	}
+	if want := []FindingKind{FindingLineHeight, FindingNoPages}; !slices.Equal(kinds, want) {
+		t.Errorf("got finding kinds %v, want %v", kinds, want)
+	}
+}
+
+func TestFindingKindString(t *testing.T) {
+	if got := FindingOutsidePage.String(); got != "outsidepage" {
+		t.Errorf("got %q, want %q", got, "outsidepage")
+	}
+	if got := FindingKind(99).String(); got != "FindingKind(99)" {
+		t.Errorf("got %q, want %q", got, "FindingKind(99)")
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// WatermarkOptions configure how DrawWatermark tiles a text across an image.
+// A nil *WatermarkOptions is valid and selects the defaults.
+type WatermarkOptions struct {
+	// Style is the style of the repeated text. Its background and padding
+	// are part of each tile.
+	Style *Style
+	// Angle is the angle in degrees by which the tiled text is rotated
+	// counterclockwise.
+	Angle float64
+	// Spacing is the horizontal and vertical space in pixels between
+	// adjacent repetitions of the text, before rotation.
+	Spacing image.Point
+	// Opacity is the opacity of the text in the range (0, 1].
+	// If it is 0 an opacity of 0.25 is used.
+	Opacity float64
+}
+
+// DrawWatermark tiles the given text rotated and semi-transparent across
+// the whole destination image, e.g. for watermarking screenshots. Every
+// other row of tiles is shifted by half a tile.
+func (f *BitmapFont) DrawWatermark(dst draw.Image, text string, opts *WatermarkOptions) {
+	if opts == nil {
+		opts = &WatermarkOptions{}
+	}
+	opacity := opts.Opacity
+	if opacity <= 0 {
+		opacity = 0.25
+	}
+	label := f.RenderToImage(text, opts.Style)
+	tile := image.NewRGBA(image.Rectangle{Max: label.Bounds().Size().Add(opts.Spacing)})
+	if tile.Bounds().Empty() {
+		return
+	}
+	draw.Draw(tile, label.Bounds(), label, image.Point{}, draw.Src)
+	for i := range tile.Pix {
+		tile.Pix[i] = uint8(math.Round(float64(tile.Pix[i]) * min(opacity, 1)))
+	}
+
+	b := dst.Bounds()
+	center := image.Pt(b.Min.X+b.Dx()/2, b.Min.Y+b.Dy()/2)
+	sin, cos := math.Sincos(opts.Angle * math.Pi / 180)
+	s2d := f64.Aff3{
+		cos, sin, float64(center.X),
+		-sin, cos, float64(center.Y),
+	}
+	d := int(math.Ceil(math.Hypot(float64(b.Dx()), float64(b.Dy()))/2)) + 1
+	sr := image.Rect(-d, -d, d, d)
+	xdraw.ApproxBiLinear.Transform(dst, s2d, tiledImage{tile}, sr, draw.Over, nil)
+}
+
+// A tiledImage is an image of unlimited size, which repeats a tile image.
+// Every other row of tiles is shifted by half a tile.
+type tiledImage struct {
+	tile *image.RGBA
+}
+
+func (t tiledImage) ColorModel() color.Model {
+	return t.tile.ColorModel()
+}
+
+func (t tiledImage) Bounds() image.Rectangle {
+	return image.Rect(math.MinInt32/2, math.MinInt32/2, math.MaxInt32/2, math.MaxInt32/2)
+}
+
+func (t tiledImage) At(x, y int) color.Color {
+	size := t.tile.Bounds().Size()
+	row := floorDiv(y, size.Y)
+	if row%2 != 0 {
+		x += size.X / 2
+	}
+	return t.tile.At(x-floorDiv(x, size.X)*size.X, y-row*size.Y)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
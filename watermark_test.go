@@ -0,0 +1,47 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTiledImage(t *testing.T) {
+	tile := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	tile.SetRGBA(0, 0, red)
+	ti := tiledImage{tile}
+	for _, p := range []image.Point{{0, 0}, {4, 0}, {-4, 0}, {-8, -4}, {2, 2}, {-2, -2}} {
+		if got := ti.At(p.X, p.Y); got != red {
+			t.Errorf("got %v at %v, want the repeated tile pixel %v", got, p, red)
+		}
+	}
+	if got := ti.At(0, 2); got == red {
+		t.Errorf("got %v at (0,2), want the odd row shifted by half a tile", got)
+	}
+}
+
+func TestDrawWatermark(t *testing.T) {
+	f := opaqueFont("A")
+	dst := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	f.DrawWatermark(dst, "A", &WatermarkOptions{Spacing: image.Pt(4, 4), Opacity: 0.5})
+	var quadrants [4]bool
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			a := dst.RGBAAt(x, y).A
+			if a > 0x81 {
+				t.Fatalf("got alpha %d at (%d,%d), want at most half opacity", a, x, y)
+			}
+			if a > 0 {
+				quadrants[y/20*2+x/20] = true
+			}
+		}
+	}
+	if quadrants != [4]bool{true, true, true, true} {
+		t.Errorf("got text in quadrants %v, want the whole image covered", quadrants)
+	}
+}
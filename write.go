@@ -0,0 +1,234 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteText writes the descriptor in BMFont's text format to w. The
+// characters and kerning pairs are written sorted by their IDs. The unknown
+// attributes kept in the Extra fields are written after the known ones.
+func (d *Descriptor) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	i, c := d.Info, d.Common
+	fmt.Fprintf(bw, "info face=%s size=%d bold=%d italic=%d charset=%s unicode=%d stretchH=%d smooth=%d aa=%d padding=%d,%d,%d,%d spacing=%d,%d outline=%d",
+		quote(i.Face), i.Size, boolInt(i.Bold), boolInt(i.Italic), quote(i.Charset), boolInt(i.Unicode),
+		i.StretchH, boolInt(i.Smooth), i.AA,
+		i.Padding.Up, i.Padding.Right, i.Padding.Down, i.Padding.Left,
+		i.Spacing.Horizontal, i.Spacing.Vertical, i.Outline)
+	writeLocalization(bw, &i)
+	writeExtra(bw, i.Extra, textValue)
+	fmt.Fprintln(bw)
+	fmt.Fprintf(bw, "common lineHeight=%d base=%d scaleW=%d scaleH=%d pages=%d packed=%d alphaChnl=%d redChnl=%d greenChnl=%d blueChnl=%d",
+		c.LineHeight, c.Base, c.ScaleW, c.ScaleH, len(d.Pages), boolInt(c.Packed),
+		c.AlphaChannel, c.RedChannel, c.GreenChannel, c.BlueChannel)
+	writeExtra(bw, c.Extra, textValue)
+	fmt.Fprintln(bw)
+	for _, id := range sortedKeys(d.Pages) {
+		fmt.Fprintf(bw, "page id=%d file=%s", id, quote(d.Pages[id].File))
+		writeExtra(bw, d.Pages[id].Extra, textValue)
+		fmt.Fprintln(bw)
+	}
+	fmt.Fprintf(bw, "chars count=%d\n", len(d.Chars))
+	for _, id := range sortedKeys(d.Chars) {
+		ch := d.Chars[id]
+		fmt.Fprintf(bw, "char id=%-5d x=%-5d y=%-5d width=%-5d height=%-5d xoffset=%-5d yoffset=%-5d xadvance=%-5d page=%-2d chnl=%d",
+			ch.ID, ch.X, ch.Y, ch.Width, ch.Height, ch.XOffset, ch.YOffset, ch.XAdvance, ch.Page, ch.Channel)
+		if ch.Letter != "" {
+			fmt.Fprintf(bw, " letter=%s", quoteLetter(ch.Letter))
+		}
+		writeExtra(bw, ch.Extra, textValue)
+		fmt.Fprintln(bw)
+	}
+	if len(d.Kerning) > 0 {
+		pairs := d.kerningPairs()
+		fmt.Fprintf(bw, "kernings count=%d\n", len(pairs))
+		for _, pair := range pairs {
+			fmt.Fprintf(bw, "kerning first=%-3d second=%-3d amount=%d",
+				pair.First, pair.Second, d.Kerning[pair].Amount)
+			writeExtra(bw, d.Kerning[pair].Extra, textValue)
+			fmt.Fprintln(bw)
+		}
+	}
+	d.writeKerningClasses(bw)
+	return bw.Flush()
+}
+
+// kerningPairs returns the character pairs of the kerning map sorted by
+// their IDs.
+func (d *Descriptor) kerningPairs() []CharPair {
+	pairs := make([]CharPair, 0, len(d.Kerning))
+	for pair := range d.Kerning {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].First != pairs[j].First {
+			return pairs[i].First < pairs[j].First
+		}
+		return pairs[i].Second < pairs[j].Second
+	})
+	return pairs
+}
+
+// DescriptorFormat is a file format for font descriptors.
+type DescriptorFormat int
+
+const (
+	TextFormat   DescriptorFormat = iota // written by WriteText
+	BinaryFormat                         // written by WriteBinary
+	XMLFormat                            // written by WriteXML
+	JSONFormat                           // written by WriteJSON
+)
+
+// Write writes the descriptor to w in the given format.
+func (d *Descriptor) Write(w io.Writer, format DescriptorFormat) error {
+	switch format {
+	case TextFormat:
+		return d.WriteText(w)
+	case BinaryFormat:
+		return d.WriteBinary(w)
+	case XMLFormat:
+		return d.WriteXML(w)
+	case JSONFormat:
+		return d.WriteJSON(w)
+	}
+	return fmt.Errorf("bmfont: unknown descriptor format %d", format)
+}
+
+// Save saves the bitmap font to a BMFont descriptor file (.fnt) in text
+// format at the given path, and the page sheet images as PNG files in the
+// same directory, named by the File fields of the descriptor's pages.
+func (f *BitmapFont) Save(path string) error {
+	return f.SaveFormat(path, TextFormat)
+}
+
+// SaveFormat saves the bitmap font like Save, with the descriptor file in
+// the given format.
+func (f *BitmapFont) SaveFormat(path string, format DescriptorFormat) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer closeChecked(file, &err)
+	if err := f.Descriptor.Write(file, format); err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	for id, page := range f.Descriptor.Pages {
+		if f.pages == nil {
+			if _, ok := f.PageSheets[id]; !ok {
+				continue
+			}
+		}
+		sheet, err := f.Page(id)
+		if err != nil {
+			return err
+		}
+		if ext := strings.ToLower(filepath.Ext(page.File)); ext != ".png" {
+			return fmt.Errorf("bmfont: can't save page %d as %q, only PNG is supported", id, page.File)
+		}
+		if err := savePNG(filepath.Join(dir, page.File), sheet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func savePNG(path string, img image.Image) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer closeChecked(file, &err)
+	return png.Encode(file, img)
+}
+
+// writeLocalization writes the localization metadata of the info tag as
+// additional attributes, omitting the empty ones.
+func writeLocalization(w io.Writer, i *Info) {
+	for _, a := range localizationAttrs(i) {
+		if a.value != "" {
+			fmt.Fprintf(w, " %s=%s", a.name, quote(a.value))
+		}
+	}
+}
+
+// localizationAttrs returns the names and values of the attributes that
+// hold the localization metadata of the info tag.
+func localizationAttrs(i *Info) []struct{ name, value string } {
+	return []struct{ name, value string }{
+		{"languages", strings.Join(i.Languages, ",")},
+		{"scripts", strings.Join(i.Scripts, ",")},
+		{"usage", i.Usage},
+		{"notes", i.Notes},
+	}
+}
+
+// writeExtra writes the unknown attributes of a tag sorted by their names,
+// with the values formatted by the given function.
+func writeExtra(w io.Writer, extra map[string]string, value func(string) string) {
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, " %s=%s", name, value(extra[name]))
+	}
+}
+
+// textValue formats an attribute value for the text format. Integers and
+// lists of integers are written without quotes, like the parser reads them.
+func textValue(s string) string {
+	if s != "" && strings.Trim(s, "-,0123456789") == "" {
+		return s
+	}
+	return quote(s)
+}
+
+// quoteLetter quotes the letter of a char for the text format. The
+// quotation mark is written as """ like by the tools that emit letters.
+func quoteLetter(s string) string {
+	if s == `"` {
+		return `"""`
+	}
+	return quote(s)
+}
+
+// quote quotes a string value for the text format. BMFont does not escape
+// characters within quoted values, so the Go syntax is only used if the
+// value could not be read back otherwise.
+func quote(s string) string {
+	if strings.ContainsAny(s, "\"\\\n") {
+		return strconv.Quote(s)
+	}
+	return `"` + s + `"`
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys[K rune | int, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLocalizationRoundTrip(t *testing.T) {
+	d, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Info.Languages = []string{"de", "tr-TR"}
+	d.Info.Scripts = []string{"Latn"}
+	d.Info.Usage = "subtitles"
+	d.Info.Notes = `Use at 2x for "large" screens`
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	info, _, _ := strings.Cut(buf.String(), "\n")
+	if want := ` languages="de,tr-TR" scripts="Latn" usage="subtitles" notes="Use at 2x for \"large\" screens"`; !strings.HasSuffix(info, want) {
+		t.Errorf("got info tag %q, want it to end with %q", info, want)
+	}
+	got, err := ReadDescriptor(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Info, d.Info) {
+		t.Errorf("got info %+v, want %+v", got.Info, d.Info)
+	}
+}
+
+func TestLocalizationOmittedIfEmpty(t *testing.T) {
+	d, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	info, _, _ := strings.Cut(buf.String(), "\n")
+	for _, name := range []string{"languages=", "scripts=", "usage=", "notes="} {
+		if strings.Contains(info, name) {
+			t.Errorf("got info tag %q, want no %s attribute", info, name)
+		}
+	}
+	if d.Info.Languages != nil || d.Info.Usage != "" {
+		t.Errorf("got localization %v %q for a descriptor without it", d.Info.Languages, d.Info.Usage)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteXML writes the descriptor in BMFont's XML format to w, for tools that
+// only read the XML format. The document has the same structure as the XML
+// export of BMFont: a font element with info, common, pages, chars and
+// kernings elements, whose attributes are those of the text format. The
+// characters and kerning pairs are written sorted by their IDs.
+// Extensions like class-based kerning and localization metadata are written
+// like in the text format, as additional elements and attributes, and so are
+// the unknown attributes kept in the Extra fields.
+func (d *Descriptor) WriteXML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	i, c := d.Info, d.Common
+	fmt.Fprintln(bw, `<?xml version="1.0"?>`)
+	fmt.Fprintln(bw, "<font>")
+	fmt.Fprintf(bw, `  <info face=%s size="%d" bold="%d" italic="%d" charset=%s unicode="%d" stretchH="%d" smooth="%d" aa="%d" padding="%d,%d,%d,%d" spacing="%d,%d" outline="%d"`,
+		xmlAttr(i.Face), i.Size, boolInt(i.Bold), boolInt(i.Italic), xmlAttr(i.Charset), boolInt(i.Unicode),
+		i.StretchH, boolInt(i.Smooth), i.AA,
+		i.Padding.Up, i.Padding.Right, i.Padding.Down, i.Padding.Left,
+		i.Spacing.Horizontal, i.Spacing.Vertical, i.Outline)
+	writeXMLLocalization(bw, &i)
+	writeExtra(bw, i.Extra, xmlAttr)
+	fmt.Fprintln(bw, "/>")
+	fmt.Fprintf(bw, `  <common lineHeight="%d" base="%d" scaleW="%d" scaleH="%d" pages="%d" packed="%d" alphaChnl="%d" redChnl="%d" greenChnl="%d" blueChnl="%d"`,
+		c.LineHeight, c.Base, c.ScaleW, c.ScaleH, len(d.Pages), boolInt(c.Packed),
+		c.AlphaChannel, c.RedChannel, c.GreenChannel, c.BlueChannel)
+	writeExtra(bw, c.Extra, xmlAttr)
+	fmt.Fprintln(bw, "/>")
+	fmt.Fprintln(bw, "  <pages>")
+	for _, id := range sortedKeys(d.Pages) {
+		fmt.Fprintf(bw, "    <page id=\"%d\" file=%s", id, xmlAttr(d.Pages[id].File))
+		writeExtra(bw, d.Pages[id].Extra, xmlAttr)
+		fmt.Fprintln(bw, "/>")
+	}
+	fmt.Fprintln(bw, "  </pages>")
+	fmt.Fprintf(bw, "  <chars count=\"%d\">\n", len(d.Chars))
+	for _, id := range sortedKeys(d.Chars) {
+		ch := d.Chars[id]
+		fmt.Fprintf(bw, `    <char id="%d" x="%d" y="%d" width="%d" height="%d" xoffset="%d" yoffset="%d" xadvance="%d" page="%d" chnl="%d"`,
+			ch.ID, ch.X, ch.Y, ch.Width, ch.Height, ch.XOffset, ch.YOffset, ch.XAdvance, ch.Page, ch.Channel)
+		if ch.Letter != "" {
+			fmt.Fprintf(bw, " letter=%s", xmlAttr(ch.Letter))
+		}
+		writeExtra(bw, ch.Extra, xmlAttr)
+		fmt.Fprintln(bw, "/>")
+	}
+	fmt.Fprintln(bw, "  </chars>")
+	if len(d.Kerning) > 0 {
+		pairs := d.kerningPairs()
+		fmt.Fprintf(bw, "  <kernings count=\"%d\">\n", len(pairs))
+		for _, pair := range pairs {
+			fmt.Fprintf(bw, "    <kerning first=\"%d\" second=\"%d\" amount=\"%d\"",
+				pair.First, pair.Second, d.Kerning[pair].Amount)
+			writeExtra(bw, d.Kerning[pair].Extra, xmlAttr)
+			fmt.Fprintln(bw, "/>")
+		}
+		fmt.Fprintln(bw, "  </kernings>")
+	}
+	for _, c := range d.kerningClassList() {
+		fmt.Fprintf(bw, "  <kernclass id=\"%d\" side=%s chars=%s/>\n", c.id, xmlAttr(c.side), xmlAttr(c.chars))
+	}
+	for _, pair := range d.classKerningPairs() {
+		fmt.Fprintf(bw, "  <classkerning first=\"%d\" second=\"%d\" amount=\"%d\"/>\n",
+			pair.First, pair.Second, d.ClassKerning[pair].Amount)
+	}
+	fmt.Fprintln(bw, "</font>")
+	return bw.Flush()
+}
+
+// writeXMLLocalization writes the localization metadata of the info element
+// as additional attributes, omitting the empty ones.
+func writeXMLLocalization(w io.Writer, i *Info) {
+	for _, a := range localizationAttrs(i) {
+		if a.value != "" {
+			fmt.Fprintf(w, " %s=%s", a.name, xmlAttr(a.value))
+		}
+	}
+}
+
+// xmlAttr quotes and escapes an attribute value for the XML format.
+func xmlAttr(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	_ = xml.EscapeText(&sb, []byte(s))
+	sb.WriteByte('"')
+	return sb.String()
+}
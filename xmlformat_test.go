@@ -0,0 +1,83 @@
+// Copyright 2020 Frederik Zipp. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bmfont
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteXML(t *testing.T) {
+	d, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := d.WriteXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0"?>
+<font>
+  <info face="Test" size="8" bold="0" italic="0" charset="" unicode="1" stretchH="100" smooth="1" aa="1" padding="0,0,0,0" spacing="1,1" outline="0"/>
+  <common lineHeight="8" base="6" scaleW="16" scaleH="8" pages="1" packed="0" alphaChnl="0" redChnl="4" greenChnl="4" blueChnl="4"/>
+  <pages>
+    <page id="0" file="sheet.png"/>
+  </pages>
+  <chars count="2">
+    <char id="65" x="0" y="0" width="4" height="6" xoffset="0" yoffset="0" xadvance="5" page="0" chnl="15"/>
+    <char id="66" x="4" y="0" width="4" height="6" xoffset="0" yoffset="0" xadvance="5" page="0" chnl="15"/>
+  </chars>
+  <kernings count="1">
+    <kerning first="65" second="66" amount="-1"/>
+  </kernings>
+</font>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteXMLEscaping(t *testing.T) {
+	d, err := ReadDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Info.Face = `Fish & "Chips" <Bold>`
+	d.Info.Notes = "line\nbreak"
+	d.Pages[0] = Page{ID: 0, File: "sheet.png", Extra: map[string]string{"hash": "a<b"}}
+	ch := d.Chars['A']
+	ch.Letter = "&"
+	d.Chars['A'] = ch
+	var buf bytes.Buffer
+	if err := d.WriteXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Info struct {
+			Face  string `xml:"face,attr"`
+			Notes string `xml:"notes,attr"`
+		} `xml:"info"`
+		Pages []struct {
+			Hash string `xml:"hash,attr"`
+		} `xml:"pages>page"`
+		Chars []struct {
+			Letter string `xml:"letter,attr"`
+		} `xml:"chars>char"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Info.Face != d.Info.Face || doc.Info.Notes != d.Info.Notes {
+		t.Errorf("got face %q and notes %q, want %q and %q", doc.Info.Face, doc.Info.Notes, d.Info.Face, d.Info.Notes)
+	}
+	if len(doc.Pages) != 1 || doc.Pages[0].Hash != "a<b" {
+		t.Errorf("got pages %+v, want the extra attribute hash", doc.Pages)
+	}
+	if len(doc.Chars) != 2 || doc.Chars[0].Letter != "&" {
+		t.Errorf("got chars %+v, want the letter of A", doc.Chars)
+	}
+}